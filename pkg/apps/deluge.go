@@ -0,0 +1,258 @@
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"golift.io/cnfg"
+)
+
+/* The Deluge library only reads transfers. These methods implement the rest of the Downloader interface. */
+
+// GetTransfers returns all the torrents in Deluge.
+func (c *DelugeConfig) GetTransfers(ctx context.Context) ([]*Transfer, error) {
+	xfers, err := c.GetXfersCompatContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting transfers: %w", err)
+	}
+
+	transfers := make([]*Transfer, 0, len(xfers))
+
+	for hash, xfer := range xfers {
+		if xfer.Hash != "" {
+			hash = xfer.Hash
+		}
+
+		transfer := &Transfer{
+			ID:         hash,
+			Name:       xfer.Name,
+			Category:   xfer.Label,
+			State:      delugeState(xfer.State),
+			Path:       xfer.DownloadLocation,
+			Size:       int64(xfer.TotalSize),
+			Left:       int64(xfer.TotalRemaining),
+			Downloaded: int64(xfer.AllTimeDownload),
+			Uploaded:   int64(xfer.TotalUploaded),
+			Ratio:      xfer.Ratio,
+			DownRate:   int64(xfer.DownloadPayloadRate),
+			UpRate:     int64(xfer.UploadPayloadRate),
+			Seeding:    cnfg.Duration{Duration: time.Duration(xfer.SeedingTime) * time.Second},
+			Trackers:   TrackerHosts(xfer.TrackerHost),
+			Done:       xfer.IsFinished,
+			Added:      time.Unix(int64(xfer.TimeAdded), 0),
+		}
+
+		if transfer.Path == "" {
+			transfer.Path = xfer.SavePath
+		}
+
+		if xfer.Message != "OK" {
+			transfer.Message = xfer.Message
+		}
+
+		if eta, _ := xfer.Eta.Int64(); eta > 0 && !xfer.IsFinished {
+			transfer.Eta = time.Now().Add(time.Duration(eta) * time.Second).Round(time.Second)
+		}
+
+		if xfer.CompletedTime > 0 { // Deluge 2.
+			transfer.Completed = time.Unix(int64(xfer.CompletedTime), 0)
+		} else if xfer.FinishedTime > 0 && xfer.IsFinished { // seconds since it finished.
+			transfer.Completed = time.Now().Add(-time.Duration(xfer.FinishedTime) * time.Second).Round(time.Second)
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+// delugeState turns a Deluge torrent state into a transfer state.
+func delugeState(state string) TransferState {
+	switch strings.ToLower(state) {
+	case "seeding":
+		return TransferSeeding
+	case "downloading":
+		return TransferDownloading
+	case "paused":
+		return TransferPaused
+	case "queued", "allocating", "moving":
+		return TransferQueued
+	case "checking":
+		return TransferChecking
+	default: // error
+		return TransferFailed
+	}
+}
+
+// GetStatus returns the speeds, speed limits and free space in Deluge.
+func (c *DelugeConfig) GetStatus(ctx context.Context) (*DownloaderStatus, error) {
+	resp, err := c.Get(ctx, "web.update_ui", []interface{}{[]string{"name"}, map[string]string{}})
+	if err != nil {
+		return nil, fmt.Errorf("web.update_ui: %w", err)
+	}
+
+	var data struct {
+		Stats struct {
+			DownloadRate float64 `json:"download_rate"`
+			UploadRate   float64 `json:"upload_rate"`
+			MaxDownload  float64 `json:"max_download"` // KiB/s, -1 is unlimited.
+			MaxUpload    float64 `json:"max_upload"`
+			FreeSpace    int64   `json:"free_space"`
+		} `json:"stats"`
+	}
+
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return nil, fmt.Errorf("decoding web.update_ui: %w", err)
+	}
+
+	status := &DownloaderStatus{
+		DownRate:  int64(data.Stats.DownloadRate),
+		UpRate:    int64(data.Stats.UploadRate),
+		FreeSpace: data.Stats.FreeSpace,
+	}
+
+	if data.Stats.MaxDownload > 0 {
+		status.DownLimit = int64(data.Stats.MaxDownload * mnd.Kilobyte)
+	}
+
+	if data.Stats.MaxUpload > 0 {
+		status.UpLimit = int64(data.Stats.MaxUpload * mnd.Kilobyte)
+	}
+
+	return status, nil
+}
+
+// PauseTransfers pauses torrents in Deluge.
+func (c *DelugeConfig) PauseTransfers(ctx context.Context, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	return c.listMethod(ctx, "core.pause_torrents", "core.pause_torrent", hashes)
+}
+
+// ResumeTransfers resumes torrents in Deluge.
+func (c *DelugeConfig) ResumeTransfers(ctx context.Context, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	return c.listMethod(ctx, "core.resume_torrents", "core.resume_torrent", hashes)
+}
+
+// listMethod calls a method that takes a list of torrent IDs. Deluge 1.x takes a list in
+// the singular method, Deluge 2.x takes a single ID there, and a list in the plural method.
+func (c *DelugeConfig) listMethod(ctx context.Context, method, oldMethod string, hashes []string) error {
+	if strings.HasPrefix(c.Deluge.Version, "1.") {
+		method = oldMethod
+	}
+
+	if _, err := c.Get(ctx, method, []interface{}{hashes}); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	return nil
+}
+
+// RemoveTransfers removes torrents from Deluge, and optionally deletes their data.
+func (c *DelugeConfig) RemoveTransfers(ctx context.Context, deleteData bool, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if _, err := c.Get(ctx, "core.remove_torrent", []interface{}{hash, deleteData}); err != nil {
+			return fmt.Errorf("core.remove_torrent: %s: %w", hash, err)
+		}
+	}
+
+	return nil
+}
+
+// SetCategory sets the label on torrents in Deluge. The Label plugin must be enabled.
+// A missing label is created, and an empty label removes it.
+func (c *DelugeConfig) SetCategory(ctx context.Context, category string, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	// The Label plugin only allows lowercase labels.
+	if category = strings.ToLower(category); category != "" {
+		if err := c.addLabel(ctx, category); err != nil {
+			return err
+		}
+	}
+
+	for _, hash := range hashes {
+		if _, err := c.Get(ctx, "label.set_torrent", []interface{}{hash, category}); err != nil {
+			return fmt.Errorf("label.set_torrent: %s: %w", hash, err)
+		}
+	}
+
+	return nil
+}
+
+// addLabel creates a label if it does not exist.
+func (c *DelugeConfig) addLabel(ctx context.Context, label string) error {
+	resp, err := c.Get(ctx, "label.get_labels", []interface{}{})
+	if err != nil {
+		return fmt.Errorf("label.get_labels: %w", err)
+	}
+
+	var labels []string
+	if err := json.Unmarshal(resp.Result, &labels); err != nil {
+		return fmt.Errorf("decoding label.get_labels: %w", err)
+	}
+
+	for _, existing := range labels {
+		if existing == label {
+			return nil
+		}
+	}
+
+	if _, err := c.Get(ctx, "label.add", []interface{}{label}); err != nil {
+		return fmt.Errorf("label.add: %w", err)
+	}
+
+	return nil
+}
+
+// PauseAll pauses every torrent in Deluge.
+func (c *DelugeConfig) PauseAll(ctx context.Context) error {
+	if _, err := c.Get(ctx, "core.pause_all_torrents", []interface{}{}); err != nil {
+		return fmt.Errorf("core.pause_all_torrents: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeAll resumes every torrent in Deluge.
+func (c *DelugeConfig) ResumeAll(ctx context.Context) error {
+	if _, err := c.Get(ctx, "core.resume_all_torrents", []interface{}{}); err != nil {
+		return fmt.Errorf("core.resume_all_torrents: %w", err)
+	}
+
+	return nil
+}
+
+// SetSpeedLimits sets the global speed limits in Deluge. Deluge limits are KiB/s, and -1 is unlimited.
+func (c *DelugeConfig) SetSpeedLimits(ctx context.Context, download, upload int64) error {
+	limit := func(bytes int64) float64 {
+		if bytes <= 0 {
+			return -1
+		}
+
+		return float64(bytes) / mnd.Kilobyte
+	}
+
+	config := map[string]float64{"max_download_speed": limit(download), "max_upload_speed": limit(upload)}
+	if _, err := c.Get(ctx, "core.set_config", []interface{}{config}); err != nil {
+		return fmt.Errorf("core.set_config: %w", err)
+	}
+
+	return nil
+}
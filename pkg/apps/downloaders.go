@@ -0,0 +1,508 @@
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golift.io/cnfg"
+	"golift.io/starr"
+)
+
+/* This file has the interface all download clients implement, and the API handlers built on it. */
+
+// Download client names. The API path for each is its lowercase name.
+const (
+	AppDeluge   starr.App = "Deluge"
+	AppNZBGet   starr.App = "NZBGet"
+	AppQbit     starr.App = "Qbit"
+	AppRtorrent starr.App = "rTorrent"
+	AppSabNZB   starr.App = "SABnzbd"
+	AppXmission starr.App = "Transmission"
+)
+
+// Download protocols.
+const (
+	ProtocolTorrent = "torrent"
+	ProtocolUsenet  = "usenet"
+)
+
+// TransferState is the state of a transfer, the same for every download client.
+type TransferState string
+
+// These are the transfer states a download client state is turned into.
+const (
+	TransferDownloading TransferState = "downloading"
+	TransferSeeding     TransferState = "seeding"
+	TransferPaused      TransferState = "paused"
+	TransferQueued      TransferState = "queued"
+	TransferChecking    TransferState = "checking"
+	TransferProcessing  TransferState = "processing" // usenet post-processing.
+	TransferCompleted   TransferState = "completed"  // usenet history.
+	TransferFailed      TransferState = "failed"
+)
+
+// Errors returned by the download client methods and handlers.
+var (
+	ErrNoDownloader      = fmt.Errorf("configured download client ID not found")
+	ErrNoTransferIDs     = fmt.Errorf("at least one transfer ID must be provided")
+	ErrDeleteUnsupported = fmt.Errorf("this download client cannot delete data")
+)
+
+// Downloader is implemented by every download client. The dashboard, service checks
+// and the download client API use it, so a new client only has to implement this.
+type Downloader interface {
+	Enabled() bool
+	// GetTransfers returns every transfer. Usenet clients include their history.
+	GetTransfers(ctx context.Context) ([]*Transfer, error)
+	// GetStatus returns the client's speeds, speed limits and free space.
+	GetStatus(ctx context.Context) (*DownloaderStatus, error)
+	// PauseTransfers pauses the transfers with the provided IDs.
+	PauseTransfers(ctx context.Context, ids ...string) error
+	// ResumeTransfers resumes the transfers with the provided IDs.
+	ResumeTransfers(ctx context.Context, ids ...string) error
+	// RemoveTransfers removes the transfers with the provided IDs, and optionally their data.
+	RemoveTransfers(ctx context.Context, deleteData bool, ids ...string) error
+	// SetCategory moves the transfers with the provided IDs into a category (or label).
+	SetCategory(ctx context.Context, category string, ids ...string) error
+	// PauseAll pauses every transfer in the client.
+	PauseAll(ctx context.Context) error
+	// ResumeAll resumes every transfer in the client.
+	ResumeAll(ctx context.Context) error
+	// SetSpeedLimits sets the global speed limits in bytes per second. Zero is unlimited.
+	// Usenet clients do not upload, and ignore the upload limit.
+	SetSpeedLimits(ctx context.Context, download, upload int64) error
+}
+
+// Transfer is a torrent or NZB in any download client.
+type Transfer struct {
+	ID         string        `json:"id"`                   // torrent hash, nzo_id or NZBID.
+	DownloadID string        `json:"downloadId,omitempty"` // the ID Starr apps store, when it is not the ID.
+	Name       string        `json:"name"`
+	Category   string        `json:"category"` // category or label.
+	State      TransferState `json:"state"`
+	Message    string        `json:"message,omitempty"` // error message.
+	Path       string        `json:"path,omitempty"`
+	Size       int64         `json:"size"`
+	Left       int64         `json:"left"` // bytes remaining.
+	Downloaded int64         `json:"downloaded"`
+	Uploaded   int64         `json:"uploaded,omitempty"`
+	Ratio      float64       `json:"ratio,omitempty"`
+	DownRate   int64         `json:"downRate"` // bytes per second.
+	UpRate     int64         `json:"upRate,omitempty"`
+	Seeding    cnfg.Duration `json:"seeding,omitempty"`
+	Trackers   []string      `json:"trackers,omitempty"` // tracker host names.
+	Done       bool          `json:"done"`               // finished downloading.
+	Added      time.Time     `json:"added,omitempty"`
+	Eta        time.Time     `json:"eta,omitempty"` // zero if unknown.
+	Completed  time.Time     `json:"completed,omitempty"`
+}
+
+// DownloaderStatus is the state of a whole download client. Rates and limits are bytes per second.
+// A zero limit is unlimited, and a zero size is not reported by the client.
+type DownloaderStatus struct {
+	DownRate   int64 `json:"downRate"`
+	UpRate     int64 `json:"upRate"`
+	DownLimit  int64 `json:"downLimit"`
+	UpLimit    int64 `json:"upLimit"`
+	FreeSpace  int64 `json:"freeSpace"`
+	Downloaded int64 `json:"downloaded"` // total reported by the client.
+	Uploaded   int64 `json:"uploaded"`
+	Day        int64 `json:"day,omitempty"`
+	Week       int64 `json:"week,omitempty"`
+	Month      int64 `json:"month,omitempty"`
+	Paused     bool  `json:"paused"` // the whole client is paused.
+}
+
+// DownloadClient is one enabled download client instance.
+type DownloadClient struct {
+	Downloader
+	*ExtraConfig
+	App      starr.App
+	Instance int    // 1-indexed, like the API {id}.
+	Protocol string // torrent or usenet.
+	URL      string
+}
+
+// DownloadClients returns every enabled download client.
+func (a *Apps) DownloadClients() []*DownloadClient { //nolint:cyclop
+	clients := []*DownloadClient{}
+	add := func(app starr.App, instance int, protocol, uri string, dl Downloader, extra *ExtraConfig) {
+		clients = append(clients, &DownloadClient{
+			Downloader:  dl,
+			ExtraConfig: extra,
+			App:         app,
+			Instance:    instance,
+			Protocol:    protocol,
+			URL:         uri,
+		})
+	}
+
+	for idx, app := range a.Deluge {
+		if app.Enabled() {
+			add(AppDeluge, idx+1, ProtocolTorrent, strings.TrimSuffix(app.Config.URL, "/json"), app, &app.ExtraConfig)
+		}
+	}
+
+	for idx, app := range a.NZBGet {
+		if app.Enabled() {
+			add(AppNZBGet, idx+1, ProtocolUsenet, app.Config.URL, app, &app.ExtraConfig)
+		}
+	}
+
+	for idx, app := range a.Qbit {
+		if app.Enabled() {
+			add(AppQbit, idx+1, ProtocolTorrent, app.URL, app, &app.ExtraConfig)
+		}
+	}
+
+	for idx, app := range a.Rtorrent {
+		if app.Enabled() {
+			add(AppRtorrent, idx+1, ProtocolTorrent, app.URL, app, &app.ExtraConfig)
+		}
+	}
+
+	for idx, app := range a.SabNZB {
+		if app.Enabled() {
+			add(AppSabNZB, idx+1, ProtocolUsenet, app.URL, app, &app.ExtraConfig)
+		}
+	}
+
+	for idx, app := range a.Transmission {
+		if app.Enabled() {
+			add(AppXmission, idx+1, ProtocolTorrent, app.URL, app, &app.ExtraConfig)
+		}
+	}
+
+	return clients
+}
+
+// DownloadClient returns one enabled download client instance, or nil if it's not found.
+func (a *Apps) DownloadClient(app starr.App, instance int) *DownloadClient {
+	for _, client := range a.DownloadClients() {
+		if client.App == app && client.Instance == instance {
+			return client
+		}
+	}
+
+	return nil
+}
+
+// String returns the client name and instance for logs.
+func (d *DownloadClient) String() string {
+	if d.Name != "" {
+		return fmt.Sprintf("%s %d (%s)", d.App, d.Instance, d.Name)
+	}
+
+	return fmt.Sprintf("%s %d", d.App, d.Instance)
+}
+
+// Redacted returns the URL without a username or password.
+func (d *DownloadClient) Redacted() string {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return d.URL
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
+// needIDs returns an error if there are no IDs to act on.
+func needIDs(ids []string) error {
+	if len(ids) == 0 {
+		return ErrNoTransferIDs
+	}
+
+	return nil
+}
+
+// downloaderHandler is an API handler that acts on one download client instance.
+type downloaderHandler func(req *http.Request, client *DownloadClient) (int, interface{})
+
+func (a *Apps) downloaderHandlers() {
+	for _, app := range []starr.App{AppDeluge, AppNZBGet, AppQbit, AppRtorrent, AppSabNZB, AppXmission} {
+		a.HandleAPIpath(app, "/status", a.handleDownloader(app, downloaderStatus), "GET")
+		a.HandleAPIpath(app, "/transfers", a.handleDownloader(app, downloaderTransfers), "GET")
+		a.HandleAPIpath(app, "/transfers/pause", a.handleDownloader(app, downloaderPause), "POST")
+		a.HandleAPIpath(app, "/transfers/resume", a.handleDownloader(app, downloaderResume), "POST")
+		a.HandleAPIpath(app, "/transfers/remove", a.handleDownloader(app, downloaderRemove), "POST")
+		a.HandleAPIpath(app, "/transfers/category", a.handleDownloader(app, downloaderCategory), "POST")
+		a.HandleAPIpath(app, "/pause", a.handleDownloader(app, downloaderPauseAll), "POST")
+		a.HandleAPIpath(app, "/resume", a.handleDownloader(app, downloaderResumeAll), "POST")
+		a.HandleAPIpath(app, "/limits", a.handleDownloader(app, downloaderLimits), "POST")
+	}
+}
+
+// handleDownloader finds the download client instance for a request.
+// handleAPI stores the 0-indexed instance ID in the request context for non-starr apps.
+func (a *Apps) handleDownloader(app starr.App, handler downloaderHandler) APIHandler {
+	return func(req *http.Request) (int, interface{}) {
+		idx, _ := req.Context().Value(app).(int)
+
+		client := a.DownloadClient(app, idx+1)
+		if client == nil {
+			return http.StatusUnprocessableEntity, fmt.Errorf("%s %d: %w", app, idx+1, ErrNoDownloader)
+		}
+
+		return handler(req, client)
+	}
+}
+
+// TransferIDs is the input payload for the download client transfer endpoints.
+type TransferIDs struct {
+	IDs        []string `json:"ids"`
+	DeleteData bool     `json:"deleteData"` // remove only.
+	Category   string   `json:"category"`   // category only.
+}
+
+// SpeedLimits is the input payload for the download client limits endpoint.
+// Limits are bytes per second, and zero is unlimited. Usenet clients ignore the upload limit.
+type SpeedLimits struct {
+	Download int64 `json:"download"`
+	Upload   int64 `json:"upload"`
+}
+
+func getTransferIDs(req *http.Request) (*TransferIDs, error) {
+	var payload TransferIDs
+
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	} else if err := needIDs(payload.IDs); err != nil {
+		return nil, err
+	}
+
+	return &payload, nil
+}
+
+// @Description  Returns the speeds, speed limits and free space of a download client.
+// @Summary      Get download client status
+// @Tags         Download Clients
+// @Produce      json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Success      200  {object} apps.Respond.apiResponse{message=apps.DownloaderStatus} "client status"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/status [get]
+// @Security     ApiKeyAuth
+func downloaderStatus(req *http.Request, client *DownloadClient) (int, interface{}) {
+	status, err := client.GetStatus(req.Context())
+	if err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, status
+}
+
+// @Description  Returns every transfer in a download client. Usenet clients include their history.
+// @Summary      Get download client transfers
+// @Tags         Download Clients
+// @Produce      json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Success      200  {object} apps.Respond.apiResponse{message=[]apps.Transfer} "transfers"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/transfers [get]
+// @Security     ApiKeyAuth
+func downloaderTransfers(req *http.Request, client *DownloadClient) (int, interface{}) {
+	transfers, err := client.GetTransfers(req.Context())
+	if err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, transfers
+}
+
+// @Description  Pauses transfers in a download client.
+// @Summary      Pause download client transfers
+// @Tags         Download Clients
+// @Produce      json
+// @Accept       json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Param        POST body apps.TransferIDs true "transfer IDs to pause"
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "ok"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "bad json payload or no IDs"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/transfers/pause [post]
+// @Security     ApiKeyAuth
+func downloaderPause(req *http.Request, client *DownloadClient) (int, interface{}) {
+	payload, err := getTransferIDs(req)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if err := client.PauseTransfers(req.Context(), payload.IDs...); err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, fmt.Sprintf("paused %d transfers", len(payload.IDs))
+}
+
+// @Description  Resumes transfers in a download client.
+// @Summary      Resume download client transfers
+// @Tags         Download Clients
+// @Produce      json
+// @Accept       json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Param        POST body apps.TransferIDs true "transfer IDs to resume"
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "ok"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "bad json payload or no IDs"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/transfers/resume [post]
+// @Security     ApiKeyAuth
+func downloaderResume(req *http.Request, client *DownloadClient) (int, interface{}) {
+	payload, err := getTransferIDs(req)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if err := client.ResumeTransfers(req.Context(), payload.IDs...); err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, fmt.Sprintf("resumed %d transfers", len(payload.IDs))
+}
+
+// @Description  Removes transfers from a download client, and optionally deletes their data.
+// @Summary      Remove download client transfers
+// @Tags         Download Clients
+// @Produce      json
+// @Accept       json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Param        POST body apps.TransferIDs true "transfer IDs to remove"
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "ok"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "bad json payload or no IDs"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/transfers/remove [post]
+// @Security     ApiKeyAuth
+func downloaderRemove(req *http.Request, client *DownloadClient) (int, interface{}) {
+	payload, err := getTransferIDs(req)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if err := client.RemoveTransfers(req.Context(), payload.DeleteData, payload.IDs...); err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, fmt.Sprintf("removed %d transfers", len(payload.IDs))
+}
+
+// @Description  Moves transfers in a download client into a category or label.
+// @Description  Qbit and Deluge create missing categories. An empty category removes the label or sets the default.
+// @Summary      Set download client transfer category
+// @Tags         Download Clients
+// @Produce      json
+// @Accept       json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Param        POST body apps.TransferIDs true "transfer IDs and the category to move them into"
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "ok"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "bad json payload or no IDs"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/transfers/category [post]
+// @Security     ApiKeyAuth
+func downloaderCategory(req *http.Request, client *DownloadClient) (int, interface{}) {
+	payload, err := getTransferIDs(req)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if err := client.SetCategory(req.Context(), payload.Category, payload.IDs...); err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, fmt.Sprintf("moved %d transfers to category %q", len(payload.IDs), payload.Category)
+}
+
+// @Description  Pauses every transfer in a download client.
+// @Summary      Pause download client
+// @Tags         Download Clients
+// @Produce      json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "ok"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/pause [post]
+// @Security     ApiKeyAuth
+func downloaderPauseAll(req *http.Request, client *DownloadClient) (int, interface{}) {
+	if err := client.PauseAll(req.Context()); err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, "paused"
+}
+
+// @Description  Resumes every transfer in a download client.
+// @Summary      Resume download client
+// @Tags         Download Clients
+// @Produce      json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "ok"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/resume [post]
+// @Security     ApiKeyAuth
+func downloaderResumeAll(req *http.Request, client *DownloadClient) (int, interface{}) {
+	if err := client.ResumeAll(req.Context()); err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, "resumed"
+}
+
+// @Description  Sets the global speed limits in a download client. Limits are bytes per second, and zero is unlimited.
+// @Description  SABnzbd and NZBGet ignore the upload limit.
+// @Summary      Set download client speed limits
+// @Tags         Download Clients
+// @Produce      json
+// @Accept       json
+// @Param        app       path   string  true  "qbit, deluge, rtorrent, transmission, sabnzbd or nzbget"
+// @Param        instance  path   int64   true  "instance ID"
+// @Param        POST body apps.SpeedLimits true "new speed limits"
+// @Success      200  {object} apps.Respond.apiResponse{message=apps.SpeedLimits} "the limits that were set"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "bad json payload"
+// @Failure      422  {object} apps.Respond.apiResponse{message=string} "no client with this instance ID"
+// @Failure      503  {object} apps.Respond.apiResponse{message=string} "instance error"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/{app}/{instance}/limits [post]
+// @Security     ApiKeyAuth
+func downloaderLimits(req *http.Request, client *DownloadClient) (int, interface{}) {
+	var limits SpeedLimits
+
+	if err := json.NewDecoder(req.Body).Decode(&limits); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	limits.Download, limits.Upload = max(limits.Download, 0), max(limits.Upload, 0)
+
+	if err := client.SetSpeedLimits(req.Context(), limits.Download, limits.Upload); err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("%s: %w", client, err)
+	}
+
+	return http.StatusOK, limits
+}
@@ -25,6 +25,8 @@ type PlexConfig struct {
 	*plex.Config
 	*plex.Server
 	ExtraConfig
+	// BackupPath is the Plex Databases folder with the scheduled database backups.
+	BackupPath string `toml:"backup_path" xml:"backup_path" json:"backupPath"`
 }
 
 func (c *PlexConfig) Setup(maxBody int, logger mnd.Logger) {
@@ -56,6 +58,8 @@ func (c *PlexConfig) Enabled() bool {
 type TautulliConfig struct {
 	ExtraConfig
 	tautulli.Config
+	// BackupPath is the Tautulli backups folder with the scheduled database backups.
+	BackupPath string `toml:"backup_path" xml:"backup_path" json:"backupPath"`
 }
 
 func (c *TautulliConfig) Setup(maxBody int, logger mnd.Logger) {
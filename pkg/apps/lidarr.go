@@ -60,6 +60,7 @@ func (a *Apps) lidarrHandlers() {
 type LidarrConfig struct {
 	ExtraConfig
 	*starr.Config
+	QueueRules     []*QueueRule `toml:"queue_rule" xml:"queue_rule" json:"queueRules"`
 	*lidarr.Lidarr `toml:"-" xml:"-" json:"-"`
 	errorf         func(string, ...interface{}) `toml:"-" xml:"-" json:"-"`
 }
@@ -97,6 +98,10 @@ func (a *Apps) setupLidarr() error {
 		app.URL = strings.TrimRight(app.URL, "/")
 		app.Lidarr = lidarr.New(app.Config)
 
+		if err := validateQueueRules(starr.Lidarr, idx, app.QueueRules); err != nil {
+			return err
+		}
+
 		if app.Deletes > 0 {
 			app.delLimit = rate.NewLimiter(rate.Every(1*time.Hour/time.Duration(app.Deletes)), app.Deletes)
 		}
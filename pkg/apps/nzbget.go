@@ -0,0 +1,261 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"golift.io/nzbget"
+)
+
+/* These methods implement the Downloader interface for NZBGet. */
+
+// ErrNZBGetFailed is returned when NZBGet reports a failed queue edit.
+var ErrNZBGetFailed = fmt.Errorf("NZBGet queue edit failed")
+
+// nzbgetIDParam is the post-processing parameter Starr apps add to NZBGet downloads.
+// Its value is the download ID they store. Downloads added without it use the NZBID.
+const nzbgetIDParam = "drone"
+
+// nzbgetBytes combines the high and low 32 bits NZBGet returns sizes in.
+func nzbgetBytes(hi, lo int64) int64 {
+	return hi<<32 | lo //nolint:gomnd
+}
+
+// GetTransfers returns the queue and history in NZBGet.
+func (c *NZBGetConfig) GetTransfers(ctx context.Context) ([]*Transfer, error) {
+	queue, err := c.ListGroupsContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting file groups (queue): %w", err)
+	}
+
+	stat, err := c.StatusContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting status: %w", err)
+	}
+
+	hist, err := c.HistoryContext(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("getting history: %w", err)
+	}
+
+	transfers := make([]*Transfer, 0, len(queue)+len(hist))
+	ahead := int64(0) // bytes left in the queue ahead of this item, to guess an eta.
+
+	for _, xfer := range queue {
+		transfer := &Transfer{
+			ID:         strconv.FormatInt(xfer.NZBID, mnd.Base10),
+			Name:       xfer.NZBName,
+			Category:   xfer.Category,
+			State:      nzbgetState(xfer.Status),
+			Path:       xfer.DestDir,
+			Size:       nzbgetBytes(xfer.FileSizeHi, xfer.FileSizeLo),
+			Left:       nzbgetBytes(xfer.RemainingSizeHi, xfer.RemainingSizeLo),
+			Added:      xfer.MinPostTime.Time,
+			DownloadID: nzbgetDownloadID(xfer.Parameters),
+		}
+
+		transfer.Downloaded = transfer.Size - transfer.Left
+
+		if transfer.State == TransferDownloading {
+			transfer.DownRate = stat.DownloadRate
+		}
+
+		if ahead += transfer.Left; stat.DownloadRate > 0 && transfer.Left > 0 {
+			transfer.Eta = time.Now().Add(time.Duration(ahead/stat.DownloadRate) * time.Second).Round(time.Second)
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	for _, xfer := range hist {
+		transfer := &Transfer{
+			ID:         strconv.FormatInt(xfer.NZBID, mnd.Base10),
+			Name:       xfer.Name,
+			Category:   xfer.Category,
+			State:      TransferCompleted,
+			Path:       xfer.FinalDir,
+			Size:       nzbgetBytes(xfer.FileSizeHi, xfer.FileSizeLo),
+			Downloaded: nzbgetBytes(xfer.DownloadedSizeHi, xfer.DownloadedSizeLo),
+			Done:       true,
+			Completed:  xfer.HistoryTime.Time,
+			DownloadID: nzbgetDownloadID(xfer.Parameters),
+		}
+
+		if transfer.Path == "" {
+			transfer.Path = xfer.DestDir
+		}
+
+		if !strings.HasPrefix(xfer.Status, "SUCCESS") {
+			transfer.State = TransferFailed
+			transfer.Message = xfer.Status
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+// nzbgetDownloadID returns the download ID a Starr app stored in the NZB's parameters, if it has one.
+func nzbgetDownloadID(params []nzbget.Parameter) string {
+	for _, param := range params {
+		if param.Name == nzbgetIDParam {
+			return param.Value
+		}
+	}
+
+	return ""
+}
+
+// nzbgetState turns an NZBGet group status into a transfer state.
+func nzbgetState(status nzbget.GroupStatus) TransferState {
+	switch status {
+	case nzbget.GroupDOWNLOADING:
+		return TransferDownloading
+	case nzbget.GroupPAUSED:
+		return TransferPaused
+	case nzbget.GroupQUEUED, nzbget.GroupFETCHING:
+		return TransferQueued
+	default: // post-processing.
+		return TransferProcessing
+	}
+}
+
+// GetStatus returns the speed, speed limit, free space and download totals in NZBGet.
+func (c *NZBGetConfig) GetStatus(ctx context.Context) (*DownloaderStatus, error) {
+	stat, err := c.StatusContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting status: %w", err)
+	}
+
+	return &DownloaderStatus{
+		DownRate:   stat.DownloadRate,
+		DownLimit:  stat.DownloadLimit,
+		FreeSpace:  nzbgetBytes(stat.FreeDiskSpaceHi, stat.FreeDiskSpaceLo),
+		Downloaded: nzbgetBytes(stat.DownloadedSizeHi, stat.DownloadedSizeLo),
+		Day:        nzbgetBytes(stat.DaySizeHi, stat.DaySizeLo),
+		Month:      nzbgetBytes(stat.MonthSizeHi, stat.MonthSizeLo),
+		Paused:     stat.DownloadPaused,
+	}, nil
+}
+
+// PauseTransfers pauses NZBs in the NZBGet queue.
+func (c *NZBGetConfig) PauseTransfers(ctx context.Context, ids ...string) error {
+	return c.editQueue(ctx, "GroupPause", "", ids)
+}
+
+// ResumeTransfers resumes NZBs in the NZBGet queue.
+func (c *NZBGetConfig) ResumeTransfers(ctx context.Context, ids ...string) error {
+	return c.editQueue(ctx, "GroupResume", "", ids)
+}
+
+// RemoveTransfers removes NZBs from the NZBGet queue and history. NZBGet's DeleteCleanupDisk
+// setting decides if the data of a queued NZB is deleted, so deleteData is not used.
+func (c *NZBGetConfig) RemoveTransfers(ctx context.Context, _ bool, ids ...string) error {
+	if err := needIDs(ids); err != nil {
+		return err
+	}
+
+	queue, err := c.ListGroupsContext(ctx)
+	if err != nil {
+		return fmt.Errorf("getting file groups (queue): %w", err)
+	}
+
+	queued := make(map[string]bool)
+	for _, group := range queue {
+		queued[strconv.FormatInt(group.NZBID, mnd.Base10)] = true
+	}
+
+	var inQueue, inHistory []string
+
+	for _, id := range ids {
+		if queued[id] {
+			inQueue = append(inQueue, id)
+		} else {
+			inHistory = append(inHistory, id)
+		}
+	}
+
+	if len(inQueue) > 0 {
+		if err := c.editQueue(ctx, "GroupFinalDelete", "", inQueue); err != nil {
+			return err
+		}
+	}
+
+	if len(inHistory) == 0 {
+		return nil
+	}
+
+	return c.editQueue(ctx, "HistoryFinalDelete", "", inHistory)
+}
+
+// SetCategory moves NZBs in the NZBGet queue into a category.
+func (c *NZBGetConfig) SetCategory(ctx context.Context, category string, ids ...string) error {
+	return c.editQueue(ctx, "GroupApplyCategory", category, ids)
+}
+
+// PauseAll pauses downloading in NZBGet.
+func (c *NZBGetConfig) PauseAll(ctx context.Context) error {
+	if ok, err := c.PauseDownloadContext(ctx); err != nil {
+		return fmt.Errorf("pausing download: %w", err)
+	} else if !ok {
+		return fmt.Errorf("%w: pausedownload", ErrNZBGetFailed)
+	}
+
+	return nil
+}
+
+// ResumeAll resumes downloading in NZBGet.
+func (c *NZBGetConfig) ResumeAll(ctx context.Context) error {
+	if ok, err := c.ResumeDownloadContext(ctx); err != nil {
+		return fmt.Errorf("resuming download: %w", err)
+	} else if !ok {
+		return fmt.Errorf("%w: resumedownload", ErrNZBGetFailed)
+	}
+
+	return nil
+}
+
+// SetSpeedLimits sets the download speed limit in NZBGet. NZBGet limits are KB/s, and 0 is unlimited.
+func (c *NZBGetConfig) SetSpeedLimits(ctx context.Context, download, _ int64) error {
+	limit := int64(0)
+	if download > 0 {
+		limit = max(download/mnd.Kilobyte, 1)
+	}
+
+	if ok, err := c.RateContext(ctx, limit); err != nil {
+		return fmt.Errorf("setting rate: %w", err)
+	} else if !ok {
+		return fmt.Errorf("%w: rate", ErrNZBGetFailed)
+	}
+
+	return nil
+}
+
+// editQueue runs an editqueue command, with an optional parameter, on a list of NZBIDs.
+func (c *NZBGetConfig) editQueue(ctx context.Context, command, param string, ids []string) error {
+	if err := needIDs(ids); err != nil {
+		return err
+	}
+
+	nzbIDs := make([]int64, len(ids))
+
+	for idx, id := range ids {
+		var err error
+		if nzbIDs[idx], err = strconv.ParseInt(id, mnd.Base10, mnd.Bits64); err != nil {
+			return fmt.Errorf("invalid NZBID %q: %w", id, err)
+		}
+	}
+
+	if ok, err := c.EditQueueContext(ctx, command, param, nzbIDs); err != nil {
+		return fmt.Errorf("%s: %w", command, err)
+	} else if !ok {
+		return fmt.Errorf("%w: %s: %s", ErrNZBGetFailed, command, strings.Join(ids, ","))
+	}
+
+	return nil
+}
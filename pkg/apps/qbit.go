@@ -0,0 +1,312 @@
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"golift.io/cnfg"
+)
+
+/* The Qbit library only reads transfers. These methods implement the rest of the Downloader interface. */
+
+// qbitNoEta is the eta Qbit returns for transfers that are not downloading.
+const qbitNoEta = 8640000
+
+// ErrBadStatus is returned when a download client returns an unexpected http status.
+var ErrBadStatus = fmt.Errorf("unexpected http status")
+
+// GetTransfers returns all the torrents in Qbit.
+func (c *QbitConfig) GetTransfers(ctx context.Context) ([]*Transfer, error) {
+	xfers, err := c.GetXfersContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting transfers: %w", err)
+	}
+
+	transfers := make([]*Transfer, len(xfers))
+
+	for idx, xfer := range xfers {
+		transfers[idx] = &Transfer{
+			ID:         xfer.Hash,
+			Name:       xfer.Name,
+			Category:   xfer.Category,
+			State:      qbitState(xfer.State),
+			Path:       xfer.ContentPath,
+			Size:       xfer.Size,
+			Left:       int64(xfer.AmountLeft),
+			Downloaded: int64(xfer.Downloaded),
+			Uploaded:   xfer.Uploaded,
+			Ratio:      xfer.Ratio,
+			DownRate:   int64(xfer.Dlspeed),
+			UpRate:     xfer.Upspeed,
+			Seeding:    cnfg.Duration{Duration: time.Duration(xfer.SeedingTime) * time.Second},
+			Trackers:   TrackerHosts(xfer.Tracker),
+			Done:       xfer.AmountLeft == 0 && xfer.Progress >= 1,
+			Added:      time.Unix(int64(xfer.AddedOn), 0),
+		}
+
+		if xfer.Eta != qbitNoEta && xfer.Eta > 0 && xfer.AmountLeft > 0 {
+			transfers[idx].Eta = time.Now().Add(time.Duration(xfer.Eta) * time.Second).Round(time.Second)
+		}
+
+		if xfer.CompletionOn > 0 {
+			transfers[idx].Completed = time.Unix(int64(xfer.CompletionOn), 0)
+		}
+
+		if transfers[idx].State == TransferFailed {
+			transfers[idx].Message = xfer.State
+		}
+	}
+
+	return transfers, nil
+}
+
+// qbitState turns a Qbit torrent state into a transfer state.
+// Qbit 5 renamed paused to stopped, so both are here.
+func qbitState(state string) TransferState {
+	switch strings.ToLower(strings.TrimSpace(state)) {
+	case "uploading", "stalledup", "forcedup", "moving":
+		return TransferSeeding
+	case "downloading", "forceddl":
+		return TransferDownloading
+	case "pausedup", "pauseddl", "stoppedup", "stoppeddl":
+		return TransferPaused
+	case "queuedup", "queueddl", "allocating", "metadl", "forcedmetadl", "stalleddl":
+		return TransferQueued
+	case "checkingup", "checkingdl", "checkingresumedata":
+		return TransferChecking
+	default: // error, missingfiles, unknown
+		return TransferFailed
+	}
+}
+
+// GetStatus returns the speeds, speed limits and free space in Qbit.
+func (c *QbitConfig) GetStatus(ctx context.Context) (*DownloaderStatus, error) {
+	var data struct {
+		ServerState struct {
+			AlltimeDl        int64 `json:"alltime_dl"`
+			AlltimeUl        int64 `json:"alltime_ul"`
+			DlInfoSpeed      int64 `json:"dl_info_speed"`
+			UpInfoSpeed      int64 `json:"up_info_speed"`
+			DlRateLimit      int64 `json:"dl_rate_limit"`
+			UpRateLimit      int64 `json:"up_rate_limit"`
+			FreeSpaceOnDisk  int64 `json:"free_space_on_disk"`
+			UseAltSpeedLimit bool  `json:"use_alt_speed_limits"`
+		} `json:"server_state"`
+	}
+
+	if _, err := c.qbitReq(ctx, http.MethodGet, "api/v2/sync/maindata", url.Values{"rid": []string{"0"}}, &data); err != nil {
+		return nil, err
+	}
+
+	return &DownloaderStatus{
+		DownRate:   data.ServerState.DlInfoSpeed,
+		UpRate:     data.ServerState.UpInfoSpeed,
+		DownLimit:  data.ServerState.DlRateLimit,
+		UpLimit:    data.ServerState.UpRateLimit,
+		FreeSpace:  data.ServerState.FreeSpaceOnDisk,
+		Downloaded: data.ServerState.AlltimeDl,
+		Uploaded:   data.ServerState.AlltimeUl,
+	}, nil
+}
+
+// PauseTransfers pauses torrents in Qbit. Qbit 5 renamed pause to stop, so both are tried.
+func (c *QbitConfig) PauseTransfers(ctx context.Context, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	return c.qbitPost(ctx, "pause", "stop", url.Values{"hashes": []string{strings.Join(hashes, "|")}})
+}
+
+// ResumeTransfers resumes torrents in Qbit. Qbit 5 renamed resume to start, so both are tried.
+func (c *QbitConfig) ResumeTransfers(ctx context.Context, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	return c.qbitPost(ctx, "resume", "start", url.Values{"hashes": []string{strings.Join(hashes, "|")}})
+}
+
+// RemoveTransfers removes torrents from Qbit, and optionally deletes their data.
+func (c *QbitConfig) RemoveTransfers(ctx context.Context, deleteData bool, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	_, err := c.qbitReq(ctx, http.MethodPost, "api/v2/torrents/delete", url.Values{
+		"hashes":      []string{strings.Join(hashes, "|")},
+		"deleteFiles": []string{fmt.Sprint(deleteData)},
+	}, nil)
+
+	return err
+}
+
+// SetCategory moves torrents into a category in Qbit. A missing category is created.
+func (c *QbitConfig) SetCategory(ctx context.Context, category string, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	values := url.Values{"hashes": []string{strings.Join(hashes, "|")}, "category": []string{category}}
+
+	status, err := c.qbitReq(ctx, http.MethodPost, "api/v2/torrents/setCategory", values, nil)
+	if status != http.StatusConflict { // 409 means the category does not exist.
+		return err
+	}
+
+	_, err = c.qbitReq(ctx, http.MethodPost, "api/v2/torrents/createCategory", url.Values{"category": []string{category}}, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.qbitReq(ctx, http.MethodPost, "api/v2/torrents/setCategory", values, nil)
+
+	return err
+}
+
+// GetTrackers returns the host names of every tracker on a torrent in Qbit.
+// Transfers only include the working tracker, so this finds the trackers of torrents that have none working.
+func (c *QbitConfig) GetTrackers(ctx context.Context, hash string) ([]string, error) {
+	var trackers []struct {
+		URL string `json:"url"`
+	}
+
+	_, err := c.qbitReq(ctx, http.MethodGet, "api/v2/torrents/trackers", url.Values{"hash": []string{hash}}, &trackers)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := []string{}
+
+	for _, tracker := range trackers {
+		if !strings.HasPrefix(tracker.URL, "** [") { // DHT, PeX and LSD look like this: ** [DHT] **
+			hosts = append(hosts, TrackerHosts(tracker.URL)...)
+		}
+	}
+
+	return hosts, nil
+}
+
+// PauseAll pauses every torrent in Qbit.
+func (c *QbitConfig) PauseAll(ctx context.Context) error {
+	return c.PauseTransfers(ctx, "all")
+}
+
+// ResumeAll resumes every torrent in Qbit.
+func (c *QbitConfig) ResumeAll(ctx context.Context) error {
+	return c.ResumeTransfers(ctx, "all")
+}
+
+// SetSpeedLimits sets the global speed limits in Qbit. Qbit limits are bytes per second, and 0 is unlimited.
+func (c *QbitConfig) SetSpeedLimits(ctx context.Context, download, upload int64) error {
+	for path, limit := range map[string]int64{
+		"api/v2/transfer/setDownloadLimit": download,
+		"api/v2/transfer/setUploadLimit":   upload,
+	} {
+		values := url.Values{"limit": []string{strconv.FormatInt(limit, mnd.Base10)}}
+		if _, err := c.qbitReq(ctx, http.MethodPost, path, values, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// qbitPost posts to a torrents endpoint, and tries the Qbit 5 name for it if the old one is not found.
+func (c *QbitConfig) qbitPost(ctx context.Context, name, newName string, values url.Values) error {
+	status, err := c.qbitReq(ctx, http.MethodPost, "api/v2/torrents/"+name, values, nil)
+	if status == http.StatusNotFound {
+		_, err = c.qbitReq(ctx, http.MethodPost, "api/v2/torrents/"+newName, values, nil)
+	}
+
+	return err
+}
+
+// qbitReq makes a request to the Qbit web API with the library's http client, which holds the login cookie.
+// The library logs in when a request is forbidden, so a forbidden request makes a read request and tries again.
+// The response is decoded into `into` if it's not nil.
+func (c *QbitConfig) qbitReq(
+	ctx context.Context,
+	method, path string,
+	values url.Values,
+	into interface{},
+) (int, error) {
+	for retry := 0; ; retry++ {
+		var body io.Reader
+		if method == http.MethodPost {
+			body = strings.NewReader(values.Encode())
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, c.URL+path, body)
+		if err != nil {
+			return 0, fmt.Errorf("creating request: %w", err)
+		}
+
+		if method == http.MethodPost {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req.URL.RawQuery = values.Encode()
+		}
+
+		if c.HTTPUser != "" || c.HTTPPass != "" {
+			req.SetBasicAuth(c.HTTPUser, c.HTTPPass)
+		}
+
+		resp, err := c.Config.Client.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("making request: %w", err)
+		}
+
+		status, err := qbitResponse(resp, path, into, retry > 0)
+		if status != http.StatusForbidden || retry > 0 {
+			return status, err
+		}
+
+		if _, err := c.GetCategoriesContext(ctx); err != nil {
+			return status, fmt.Errorf("logging in: %w", err)
+		}
+	}
+}
+
+func qbitResponse(resp *http.Response, path string, into interface{}, retried bool) (int, error) {
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden && !retried:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	case resp.StatusCode != http.StatusOK:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, fmt.Errorf("%w: %s: %s", ErrBadStatus, path, resp.Status)
+	case into == nil:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+		return resp.StatusCode, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// TrackerHosts turns a tracker URL (or a bare host name) into a host name list.
+func TrackerHosts(tracker string) []string {
+	if tracker == "" {
+		return nil
+	}
+
+	if u, err := url.Parse(tracker); err == nil && u.Hostname() != "" {
+		return []string{u.Hostname()}
+	}
+
+	return []string{tracker}
+}
@@ -0,0 +1,121 @@
+package apps
+
+import (
+	"fmt"
+	"strings"
+
+	"golift.io/cnfg"
+	"golift.io/starr"
+)
+
+// QueueRule is a locally configured remediation for stuck Starr queue items.
+// An item matches when every provided condition matches, and the rule acts once it has matched for Age.
+// Every action removes the item from the Starr queue. Actions count against the instance's deletes limit.
+//
+//nolint:lll
+type QueueRule struct {
+	Name      string        `json:"name" toml:"name" xml:"name" yaml:"name"`
+	Status    []string      `json:"status" toml:"status" xml:"status" yaml:"status"`         // status, tracked status or tracked state: failed, warning, importBlocked.
+	Message   string        `json:"message" toml:"message" xml:"message" yaml:"message"`     // case-insensitive text in the error or status messages.
+	Protocol  string        `json:"protocol" toml:"protocol" xml:"protocol" yaml:"protocol"` // torrent or usenet.
+	Age       cnfg.Duration `json:"age" toml:"age" xml:"age" yaml:"age"`
+	Remove    bool          `json:"remove" toml:"remove" xml:"remove" yaml:"remove"` // remove from the download client.
+	Blocklist bool          `json:"blocklist" toml:"blocklist" xml:"blocklist" yaml:"blocklist"`
+	Search    bool          `json:"search" toml:"search" xml:"search" yaml:"search"` // search for a replacement; requires blocklist.
+	DryRun    bool          `json:"dryRun" toml:"dry_run" xml:"dry_run" yaml:"dryRun"`
+}
+
+// QueueItem is the part of a queue record from any Starr app that queue rules match.
+type QueueItem struct {
+	ID         int64
+	Title      string
+	DownloadID string
+	Protocol   string
+	States     []string // status, tracked download status and tracked download state.
+	Messages   []string // error message and status messages.
+}
+
+// Matches returns true if the queue item meets every condition in the rule.
+func (r *QueueRule) Matches(item *QueueItem) bool {
+	if r.Protocol != "" && !strings.EqualFold(r.Protocol, item.Protocol) {
+		return false
+	}
+
+	if len(r.Status) > 0 && !matchAny(r.Status, item.States) {
+		return false
+	}
+
+	if r.Message == "" {
+		return true
+	}
+
+	for _, msg := range item.Messages {
+		if strings.Contains(strings.ToLower(msg), strings.ToLower(r.Message)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAny(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(w, h) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// DeleteOpts returns the starr queue delete options for the rule's actions.
+func (r *QueueRule) DeleteOpts() *starr.QueueDeleteOpts {
+	return &starr.QueueDeleteOpts{
+		RemoveFromClient: &r.Remove,
+		BlockList:        r.Blocklist,
+		SkipRedownload:   !r.Search,
+	}
+}
+
+// Actions returns a short description of what the rule does, for logs.
+func (r *QueueRule) Actions() string {
+	actions := []string{"remove from queue"}
+
+	if r.Remove {
+		actions = append(actions, "remove from client")
+	}
+
+	if r.Blocklist {
+		actions = append(actions, "blocklist")
+	}
+
+	if r.Search {
+		actions = append(actions, "search")
+	}
+
+	return strings.Join(actions, ", ")
+}
+
+// validateQueueRules makes sure no rule matches every queue item.
+func validateQueueRules(app starr.App, idx int, rules []*QueueRule) error {
+	for ruleIdx, rule := range rules {
+		if rule == nil || (len(rule.Status) == 0 && rule.Message == "") {
+			return fmt.Errorf("%w: queue_rule %d needs a status or message: %s config %d",
+				ErrInvalidApp, ruleIdx+1, app, idx+1)
+		}
+
+		if rule.Search && !rule.Blocklist {
+			return fmt.Errorf("%w: queue_rule %d: search requires blocklist: %s config %d",
+				ErrInvalidApp, ruleIdx+1, app, idx+1)
+		}
+
+		if rule.Age.Duration < 0 {
+			return fmt.Errorf("%w: queue_rule %d: age may not be negative: %s config %d",
+				ErrInvalidApp, ruleIdx+1, app, idx+1)
+		}
+	}
+
+	return nil
+}
@@ -63,6 +63,7 @@ func (a *Apps) radarrHandlers() {
 type RadarrConfig struct {
 	ExtraConfig
 	*starr.Config
+	QueueRules     []*QueueRule `toml:"queue_rule" xml:"queue_rule" json:"queueRules"`
 	*radarr.Radarr `toml:"-" xml:"-" json:"-"`
 	errorf         func(string, ...interface{}) `toml:"-" xml:"-" json:"-"`
 }
@@ -100,6 +101,10 @@ func (a *Apps) setupRadarr() error {
 		app.URL = strings.TrimRight(app.URL, "/")
 		app.Radarr = radarr.New(app.Config)
 
+		if err := validateQueueRules(starr.Radarr, idx, app.QueueRules); err != nil {
+			return err
+		}
+
 		if app.Deletes > 0 {
 			app.delLimit = rate.NewLimiter(rate.Every(1*time.Hour/time.Duration(app.Deletes)), app.Deletes)
 		}
@@ -45,6 +45,7 @@ func (a *Apps) readarrHandlers() {
 type ReadarrConfig struct {
 	ExtraConfig
 	*starr.Config
+	QueueRules       []*QueueRule `toml:"queue_rule" xml:"queue_rule" json:"queueRules"`
 	*readarr.Readarr `toml:"-" xml:"-" json:"-"`
 	errorf           func(string, ...interface{}) `toml:"-" xml:"-" json:"-"`
 }
@@ -82,6 +83,10 @@ func (a *Apps) setupReadarr() error {
 		app.URL = strings.TrimRight(app.URL, "/")
 		app.Readarr = readarr.New(app.Config)
 
+		if err := validateQueueRules(starr.Readarr, idx, app.QueueRules); err != nil {
+			return err
+		}
+
 		if app.Deletes > 0 {
 			app.delLimit = rate.NewLimiter(rate.Every(1*time.Hour/time.Duration(app.Deletes)), app.Deletes)
 		}
@@ -0,0 +1,232 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrobinsn/go-rtorrent/rtorrent"
+	"golift.io/cnfg"
+)
+
+/* These methods implement the Downloader interface for rTorrent. The XMLRPC client takes no context. */
+
+// ErrInvalidResponse is returned when rTorrent returns data we cannot use.
+var ErrInvalidResponse = fmt.Errorf("invalid response")
+
+// rTorrent returns ratios multiplied by this.
+const rtorrentRatio = 1000
+
+// rtorrentFields are the transfer fields requested from rTorrent, in order.
+var rtorrentFields = []string{ //nolint:gochecknoglobals
+	rtorrent.DHash.Query(),
+	rtorrent.DName.Query(),
+	rtorrent.DLabel.Query(),
+	"d.message=",
+	rtorrent.DDirectory.Query(),
+	"d.state=",
+	rtorrent.DIsActive.Query(),
+	rtorrent.DComplete.Query(),
+	"d.is_hash_checking=",
+	rtorrent.DSizeInBytes.Query(),
+	rtorrent.DCompletedBytes.Query(),
+	"d.up.total=",
+	rtorrent.DRatio.Query(),
+	rtorrent.DDownRate.Query(),
+	rtorrent.DUpRate.Query(),
+	rtorrent.DFinishedTime.Query(),
+	"d.load_date=",
+}
+
+// GetTransfers returns all the torrents in rTorrent.
+func (c *RtorrentConfig) GetTransfers(_ context.Context) ([]*Transfer, error) {
+	args := []interface{}{"", string(rtorrent.ViewMain)}
+	for _, field := range rtorrentFields {
+		args = append(args, field)
+	}
+
+	results, err := c.Call("d.multicall2", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: d.multicall2 XMLRPC call failed", err)
+	}
+
+	transfers := []*Transfer{}
+	resInt, _ := results.([]interface{})
+
+	for _, outerResult := range resInt {
+		resOut, _ := outerResult.([]interface{})
+		for _, innerResult := range resOut {
+			data, ok := innerResult.([]interface{})
+			if !ok || len(data) != len(rtorrentFields) {
+				return nil, fmt.Errorf("%w: data returned from query is unusable", ErrInvalidResponse)
+			}
+
+			transfers = append(transfers, rtorrentTransfer(data))
+		}
+	}
+
+	return transfers, nil
+}
+
+// rtorrentTransfer turns one row of d.multicall2 output into a transfer.
+func rtorrentTransfer(data []interface{}) *Transfer {
+	str := func(idx int) string {
+		s, _ := data[idx].(string)
+		return s
+	}
+	num := func(idx int) int64 {
+		i, _ := data[idx].(int)
+		return int64(i)
+	}
+
+	transfer := &Transfer{
+		ID:         str(0), //nolint:gomnd // these numbers are the rtorrentFields indexes.
+		Name:       str(1),
+		Category:   str(2),
+		Message:    str(3),
+		Path:       str(4),
+		Size:       num(9),
+		Left:       num(9) - num(10),
+		Downloaded: num(10),
+		Uploaded:   num(11),
+		Ratio:      float64(num(12)) / rtorrentRatio,
+		DownRate:   num(13),
+		UpRate:     num(14),
+		Done:       num(7) > 0,
+		Added:      time.Unix(num(16), 0),
+	}
+
+	if finished := num(15); finished > 0 {
+		transfer.Completed = time.Unix(finished, 0)
+		transfer.Seeding = cnfg.Duration{Duration: time.Since(transfer.Completed).Round(time.Second)}
+	}
+
+	if transfer.DownRate > 0 && transfer.Left > 0 {
+		transfer.Eta = time.Now().Add(time.Duration(transfer.Left/transfer.DownRate) * time.Second).Round(time.Second)
+	}
+
+	switch {
+	case num(8) > 0:
+		transfer.State = TransferChecking
+	case num(5) == 0 || num(6) == 0: // stopped or paused.
+		transfer.State = TransferPaused
+	case transfer.Done:
+		transfer.State = TransferSeeding
+	default:
+		transfer.State = TransferDownloading
+	}
+
+	return transfer
+}
+
+// GetStatus returns the speeds and speed limits in rTorrent. rTorrent does not report free space.
+func (c *RtorrentConfig) GetStatus(_ context.Context) (*DownloaderStatus, error) {
+	var (
+		status = &DownloaderStatus{}
+		err    error
+	)
+
+	for method, value := range map[string]*int64{
+		"throttle.global_down.rate":     &status.DownRate,
+		"throttle.global_up.rate":       &status.UpRate,
+		"throttle.global_down.max_rate": &status.DownLimit,
+		"throttle.global_up.max_rate":   &status.UpLimit,
+		"throttle.global_down.total":    &status.Downloaded,
+		"throttle.global_up.total":      &status.Uploaded,
+	} {
+		if *value, err = c.rtorrentInt(method); err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// rtorrentInt calls a method that returns an integer.
+func (c *RtorrentConfig) rtorrentInt(method string) (int64, error) {
+	result, err := c.Call(method)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s XMLRPC call failed", err, method)
+	}
+
+	if totals, ok := result.([]interface{}); ok && len(totals) > 0 {
+		result = totals[0]
+	}
+
+	if total, ok := result.(int); ok {
+		return int64(total), nil
+	}
+
+	return 0, fmt.Errorf("%w: %s result isn't integer: %v", ErrInvalidResponse, method, result)
+}
+
+// PauseTransfers stops torrents in rTorrent.
+func (c *RtorrentConfig) PauseTransfers(_ context.Context, hashes ...string) error {
+	return c.rtorrentEach("d.stop", hashes)
+}
+
+// ResumeTransfers starts torrents in rTorrent.
+func (c *RtorrentConfig) ResumeTransfers(_ context.Context, hashes ...string) error {
+	return c.rtorrentEach("d.start", hashes)
+}
+
+// RemoveTransfers removes torrents from rTorrent. rTorrent cannot delete their data.
+func (c *RtorrentConfig) RemoveTransfers(_ context.Context, deleteData bool, hashes ...string) error {
+	if deleteData {
+		return ErrDeleteUnsupported
+	}
+
+	return c.rtorrentEach("d.erase", hashes)
+}
+
+// SetCategory sets the label on torrents in rTorrent. Labels are stored in d.custom1, like ruTorrent does.
+func (c *RtorrentConfig) SetCategory(_ context.Context, category string, hashes ...string) error {
+	return c.rtorrentEach("d.custom1.set", hashes, category)
+}
+
+// PauseAll stops every torrent in rTorrent.
+func (c *RtorrentConfig) PauseAll(_ context.Context) error {
+	if _, err := c.Call("d.multicall2", "", string(rtorrent.ViewMain), "d.stop="); err != nil {
+		return fmt.Errorf("%w: d.multicall2 d.stop XMLRPC call failed", err)
+	}
+
+	return nil
+}
+
+// ResumeAll starts every torrent in rTorrent.
+func (c *RtorrentConfig) ResumeAll(_ context.Context) error {
+	if _, err := c.Call("d.multicall2", "", string(rtorrent.ViewMain), "d.start="); err != nil {
+		return fmt.Errorf("%w: d.multicall2 d.start XMLRPC call failed", err)
+	}
+
+	return nil
+}
+
+// SetSpeedLimits sets the global speed limits in rTorrent. rTorrent limits are bytes per second, and 0 is unlimited.
+func (c *RtorrentConfig) SetSpeedLimits(_ context.Context, download, upload int64) error {
+	for method, limit := range map[string]int64{
+		"throttle.global_down.max_rate.set": download,
+		"throttle.global_up.max_rate.set":   upload,
+	} {
+		if _, err := c.Call(method, "", int(limit)); err != nil {
+			return fmt.Errorf("%w: %s XMLRPC call failed", err, method)
+		}
+	}
+
+	return nil
+}
+
+// rtorrentEach calls a method once for each torrent hash, with optional extra arguments.
+func (c *RtorrentConfig) rtorrentEach(method string, hashes []string, args ...interface{}) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if _, err := c.Call(method, append([]interface{}{hash}, args...)...); err != nil {
+			return fmt.Errorf("%w: %s XMLRPC call failed: %s", err, method, hash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps/apppkg/sabnzbd"
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+/* These methods implement the Downloader interface for SABnzbd. */
+
+// ErrSabNZBFailed is returned when SABnzbd reports a failed API action.
+var ErrSabNZBFailed = fmt.Errorf("SABnzbd action failed")
+
+// GetTransfers returns the queue and history in SABnzbd.
+func (c *SabNZBConfig) GetTransfers(ctx context.Context) ([]*Transfer, error) {
+	queue, err := c.GetQueue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting queue: %w", err)
+	}
+
+	hist, err := c.GetHistory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting history: %w", err)
+	}
+
+	transfers := make([]*Transfer, 0, len(queue.Slots)+len(hist.Slots))
+
+	for _, xfer := range queue.Slots {
+		transfer := &Transfer{
+			ID:       xfer.NzoID,
+			Name:     xfer.Filename,
+			Category: xfer.Cat,
+			State:    sabnzbQueueState(xfer.Status),
+			Size:     xfer.Size.Bytes,
+			Left:     xfer.Sizeleft.Bytes,
+		}
+
+		transfer.Downloaded = transfer.Size - transfer.Left
+
+		if transfer.State == TransferDownloading {
+			transfer.DownRate = int64(queue.Kbpersec * mnd.Kilobyte)
+		}
+
+		if !xfer.Eta.IsZero() {
+			transfer.Eta = xfer.Eta.Round(time.Second).UTC()
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	for _, xfer := range hist.Slots {
+		transfers = append(transfers, sabnzbHistory(&xfer))
+	}
+
+	return transfers, nil
+}
+
+func sabnzbHistory(xfer *sabnzbd.HistorySlots) *Transfer {
+	transfer := &Transfer{
+		ID:         xfer.NzoID,
+		Name:       xfer.Name,
+		Category:   xfer.Category,
+		Path:       xfer.Storage,
+		Size:       xfer.Bytes,
+		Downloaded: xfer.Downloaded,
+		Done:       true,
+		Completed:  time.Unix(xfer.Completed, 0).Round(time.Second).UTC(),
+	}
+
+	switch strings.ToLower(xfer.Status) {
+	case "completed":
+		transfer.State = TransferCompleted
+	case "failed":
+		transfer.State = TransferFailed
+		transfer.Message = xfer.FailMessage
+	default: // queued, verifying, repairing, extracting, moving, running, etc.
+		transfer.State = TransferProcessing
+	}
+
+	return transfer
+}
+
+// sabnzbQueueState turns a SABnzbd queue status into a transfer state.
+func sabnzbQueueState(status string) TransferState {
+	switch strings.ToLower(status) {
+	case "downloading":
+		return TransferDownloading
+	case "paused":
+		return TransferPaused
+	case "checking", "quickcheck":
+		return TransferChecking
+	default: // queued, grabbing, fetching, propagating.
+		return TransferQueued
+	}
+}
+
+// GetStatus returns the speed, speed limit, free space and download totals in SABnzbd.
+func (c *SabNZBConfig) GetStatus(ctx context.Context) (*DownloaderStatus, error) {
+	queue, err := c.GetQueue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting queue: %w", err)
+	}
+
+	// Only the totals are needed from the history.
+	var hist struct {
+		History *sabnzbd.History `json:"history"`
+	}
+
+	err = c.GetURLInto(ctx, url.Values{
+		"output": []string{"json"},
+		"mode":   []string{"history"},
+		"limit":  []string{"1"},
+		"apikey": []string{c.APIKey},
+	}, &hist)
+	if err != nil {
+		return nil, fmt.Errorf("getting history: %w", err)
+	} else if hist.History == nil {
+		hist.History = &sabnzbd.History{}
+	}
+
+	limit, _ := strconv.ParseFloat(queue.SpeedlimitAbs, mnd.Bits64)
+
+	return &DownloaderStatus{
+		DownRate:   int64(queue.Kbpersec * mnd.Kilobyte),
+		DownLimit:  int64(limit),
+		FreeSpace:  int64(queue.Diskspace1 * mnd.Megabyte * mnd.Kilobyte), // GB
+		Downloaded: hist.History.TotalSize.Bytes,
+		Day:        hist.History.DaySize.Bytes,
+		Week:       hist.History.WeekSize.Bytes,
+		Month:      hist.History.MonthSize.Bytes,
+		Paused:     queue.Paused,
+	}, nil
+}
+
+// PauseTransfers pauses NZBs in the SABnzbd queue.
+func (c *SabNZBConfig) PauseTransfers(ctx context.Context, ids ...string) error {
+	if err := needIDs(ids); err != nil {
+		return err
+	}
+
+	return c.sabnzbAction(ctx, url.Values{"mode": []string{"queue"}, "name": []string{"pause"}}, ids)
+}
+
+// ResumeTransfers resumes NZBs in the SABnzbd queue.
+func (c *SabNZBConfig) ResumeTransfers(ctx context.Context, ids ...string) error {
+	if err := needIDs(ids); err != nil {
+		return err
+	}
+
+	return c.sabnzbAction(ctx, url.Values{"mode": []string{"queue"}, "name": []string{"resume"}}, ids)
+}
+
+// RemoveTransfers removes NZBs from the SABnzbd queue and history, and optionally deletes their data.
+func (c *SabNZBConfig) RemoveTransfers(ctx context.Context, deleteData bool, ids ...string) error {
+	if err := needIDs(ids); err != nil {
+		return err
+	}
+
+	delFiles := "0"
+	if deleteData {
+		delFiles = "1"
+	}
+
+	for _, mode := range []string{"queue", "history"} {
+		err := c.sabnzbAction(ctx, url.Values{
+			"mode":      []string{mode},
+			"name":      []string{"delete"},
+			"del_files": []string{delFiles},
+		}, ids)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetCategory moves NZBs in the SABnzbd queue into a category. An empty category is the default category.
+func (c *SabNZBConfig) SetCategory(ctx context.Context, category string, ids ...string) error {
+	if err := needIDs(ids); err != nil {
+		return err
+	}
+
+	if category == "" {
+		category = "*"
+	}
+
+	return c.sabnzbAction(ctx, url.Values{"mode": []string{"change_cat"}, "value2": []string{category}}, ids)
+}
+
+// PauseAll pauses the SABnzbd queue.
+func (c *SabNZBConfig) PauseAll(ctx context.Context) error {
+	return c.sabnzbAction(ctx, url.Values{"mode": []string{"pause"}}, nil)
+}
+
+// ResumeAll resumes the SABnzbd queue.
+func (c *SabNZBConfig) ResumeAll(ctx context.Context) error {
+	return c.sabnzbAction(ctx, url.Values{"mode": []string{"resume"}}, nil)
+}
+
+// SetSpeedLimits sets the download speed limit in SABnzbd. SABnzbd does not upload.
+func (c *SabNZBConfig) SetSpeedLimits(ctx context.Context, download, _ int64) error {
+	limit := "0" // SABnzbd treats a bare number as a percent of its max line speed, and 0 as unlimited.
+	if download > 0 {
+		limit = strconv.FormatInt(max(download/mnd.Kilobyte, 1), mnd.Base10) + "K"
+	}
+
+	return c.sabnzbAction(ctx, url.Values{
+		"mode":  []string{"config"},
+		"name":  []string{"speedlimit"},
+		"value": []string{limit},
+	}, nil)
+}
+
+// sabnzbAction calls an API mode that acts on a list of nzo_ids.
+func (c *SabNZBConfig) sabnzbAction(ctx context.Context, params url.Values, ids []string) error {
+	params.Set("output", "json")
+	params.Set("apikey", c.APIKey)
+
+	if len(ids) > 0 {
+		params.Set("value", strings.Join(ids, ","))
+	}
+
+	var resp struct {
+		Status bool   `json:"status"`
+		Error  string `json:"error"`
+	}
+
+	if err := c.GetURLInto(ctx, params, &resp); err != nil {
+		return fmt.Errorf("%s %s: %w", params.Get("mode"), params.Get("name"), err)
+	} else if !resp.Status {
+		return fmt.Errorf("%w: %s %s: %s", ErrSabNZBFailed, params.Get("mode"), params.Get("name"), resp.Error)
+	}
+
+	return nil
+}
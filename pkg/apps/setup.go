@@ -150,6 +150,7 @@ func (a *Apps) InitHandlers() {
 	a.radarrHandlers()
 	a.readarrHandlers()
 	a.sonarrHandlers()
+	a.downloaderHandlers()
 }
 
 // DelOK returns true if the delete limit isn't reached.
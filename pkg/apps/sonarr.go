@@ -67,7 +67,8 @@ type SonarrConfig struct {
 	*sonarr.Sonarr `toml:"-" xml:"-" json:"-"`
 	ExtraConfig
 	*starr.Config
-	errorf func(string, ...interface{}) `toml:"-" xml:"-" json:"-"`
+	QueueRules []*QueueRule                 `toml:"queue_rule" xml:"queue_rule" json:"queueRules"`
+	errorf     func(string, ...interface{}) `toml:"-" xml:"-" json:"-"`
 }
 
 func getSonarr(r *http.Request) *SonarrConfig {
@@ -103,6 +104,10 @@ func (a *Apps) setupSonarr() error {
 		app.URL = strings.TrimRight(app.URL, "/")
 		app.Sonarr = sonarr.New(app.Config)
 
+		if err := validateQueueRules(starr.Sonarr, idx, app.QueueRules); err != nil {
+			return err
+		}
+
 		if app.Deletes > 0 {
 			app.delLimit = rate.NewLimiter(rate.Every(1*time.Hour/time.Duration(app.Deletes)), app.Deletes)
 		}
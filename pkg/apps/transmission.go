@@ -0,0 +1,298 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hekmon/transmissionrpc/v3"
+	"golift.io/cnfg"
+)
+
+/* These methods implement the Downloader interface for Transmission. */
+
+// Transmission speed limits are in kB/s, and Transmission's kB is 1000 bytes.
+const xmissionKilobyte = 1000
+
+// GetTransfers returns all the torrents in Transmission.
+func (c *XmissionConfig) GetTransfers(ctx context.Context) ([]*Transfer, error) {
+	xfers, err := c.TorrentGetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting transfers: %w", err)
+	}
+
+	transfers := make([]*Transfer, 0, len(xfers))
+
+	for _, xfer := range xfers {
+		if xfer.HashString == nil {
+			continue
+		}
+
+		transfer := &Transfer{ID: *xfer.HashString, State: TransferQueued}
+
+		for _, tracker := range xfer.Trackers {
+			transfer.Trackers = append(transfer.Trackers, TrackerHosts(tracker.Announce)...)
+		}
+
+		if len(xfer.Labels) > 0 {
+			transfer.Category = xfer.Labels[0]
+		}
+
+		xmissionTransfer(transfer, &xfer)
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+// xmissionTransfer copies the fields Transmission returned into a transfer.
+//
+//nolint:cyclop,funlen
+func xmissionTransfer(transfer *Transfer, xfer *transmissionrpc.Torrent) {
+	if xfer.Name != nil {
+		transfer.Name = *xfer.Name
+	}
+
+	if xfer.DownloadDir != nil {
+		transfer.Path = *xfer.DownloadDir
+	}
+
+	if xfer.SizeWhenDone != nil {
+		transfer.Size = int64(xfer.SizeWhenDone.Byte())
+	}
+
+	if xfer.LeftUntilDone != nil {
+		transfer.Left = *xfer.LeftUntilDone
+	}
+
+	if xfer.DownloadedEver != nil {
+		transfer.Downloaded = *xfer.DownloadedEver
+	}
+
+	if xfer.UploadedEver != nil {
+		transfer.Uploaded = *xfer.UploadedEver
+	}
+
+	if xfer.UploadRatio != nil && *xfer.UploadRatio > 0 {
+		transfer.Ratio = *xfer.UploadRatio
+	}
+
+	if xfer.RateDownload != nil {
+		transfer.DownRate = *xfer.RateDownload
+	}
+
+	if xfer.RateUpload != nil {
+		transfer.UpRate = *xfer.RateUpload
+	}
+
+	if xfer.TimeSeeding != nil {
+		transfer.Seeding = cnfg.Duration{Duration: *xfer.TimeSeeding}
+	}
+
+	if xfer.PercentDone != nil {
+		transfer.Done = *xfer.PercentDone >= 1
+	}
+
+	if xfer.AddedDate != nil {
+		transfer.Added = *xfer.AddedDate
+	}
+
+	if xfer.DoneDate != nil && xfer.DoneDate.Unix() > 0 {
+		transfer.Completed = *xfer.DoneDate
+	}
+
+	if xfer.ETA != nil && *xfer.ETA > 0 && !transfer.Done {
+		transfer.Eta = time.Now().Add(time.Duration(*xfer.ETA) * time.Second).Round(time.Second)
+	}
+
+	if xfer.Status != nil {
+		transfer.State = xmissionState(*xfer.Status)
+	}
+
+	if xfer.ErrorString != nil && *xfer.ErrorString != "" {
+		transfer.Message = *xfer.ErrorString
+		transfer.State = TransferFailed
+	}
+}
+
+// xmissionState turns a Transmission torrent status into a transfer state.
+func xmissionState(status transmissionrpc.TorrentStatus) TransferState {
+	switch status {
+	case transmissionrpc.TorrentStatusSeed:
+		return TransferSeeding
+	case transmissionrpc.TorrentStatusDownload:
+		return TransferDownloading
+	case transmissionrpc.TorrentStatusStopped:
+		return TransferPaused
+	case transmissionrpc.TorrentStatusCheck, transmissionrpc.TorrentStatusCheckWait:
+		return TransferChecking
+	case transmissionrpc.TorrentStatusIsolated:
+		return TransferFailed
+	default: // download wait, seed wait.
+		return TransferQueued
+	}
+}
+
+// GetStatus returns the speeds, speed limits and free space in Transmission.
+func (c *XmissionConfig) GetStatus(ctx context.Context) (*DownloaderStatus, error) {
+	stats, err := c.SessionStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting session stats: %w", err)
+	}
+
+	args, err := c.SessionArgumentsGet(ctx, []string{
+		"download-dir", "speed-limit-down", "speed-limit-down-enabled", "speed-limit-up", "speed-limit-up-enabled",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting session arguments: %w", err)
+	}
+
+	status := &DownloaderStatus{
+		DownRate:   stats.DownloadSpeed,
+		UpRate:     stats.UploadSpeed,
+		Downloaded: stats.CumulativeStats.DownloadedBytes,
+		Uploaded:   stats.CumulativeStats.UploadedBytes,
+	}
+
+	if args.SpeedLimitDownEnabled != nil && *args.SpeedLimitDownEnabled && args.SpeedLimitDown != nil {
+		status.DownLimit = *args.SpeedLimitDown * xmissionKilobyte
+	}
+
+	if args.SpeedLimitUpEnabled != nil && *args.SpeedLimitUpEnabled && args.SpeedLimitUp != nil {
+		status.UpLimit = *args.SpeedLimitUp * xmissionKilobyte
+	}
+
+	if args.DownloadDir != nil {
+		free, _, err := c.FreeSpace(ctx, *args.DownloadDir)
+		if err != nil {
+			return nil, fmt.Errorf("getting free space: %w", err)
+		}
+
+		status.FreeSpace = int64(free.Byte())
+	}
+
+	return status, nil
+}
+
+// PauseTransfers stops torrents in Transmission.
+func (c *XmissionConfig) PauseTransfers(ctx context.Context, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	if err := c.TorrentStopHashes(ctx, hashes); err != nil {
+		return fmt.Errorf("stopping torrents: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeTransfers starts torrents in Transmission.
+func (c *XmissionConfig) ResumeTransfers(ctx context.Context, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	if err := c.TorrentStartHashes(ctx, hashes); err != nil {
+		return fmt.Errorf("starting torrents: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveTransfers removes torrents from Transmission, and optionally deletes their data.
+// Transmission only removes torrents by ID, so the hashes are turned into IDs first.
+func (c *XmissionConfig) RemoveTransfers(ctx context.Context, deleteData bool, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	ids, err := c.xmissionIDs(ctx, hashes)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	err = c.TorrentRemove(ctx, transmissionrpc.TorrentRemovePayload{IDs: ids, DeleteLocalData: deleteData})
+	if err != nil {
+		return fmt.Errorf("removing torrents: %w", err)
+	}
+
+	return nil
+}
+
+// xmissionIDs turns torrent hashes into Transmission torrent IDs.
+func (c *XmissionConfig) xmissionIDs(ctx context.Context, hashes []string) ([]int64, error) {
+	xfers, err := c.TorrentGetHashes(ctx, []string{"id"}, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("getting torrent IDs: %w", err)
+	}
+
+	ids := []int64{}
+
+	for _, xfer := range xfers {
+		if xfer.ID != nil {
+			ids = append(ids, *xfer.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+// SetCategory sets the label on torrents in Transmission. An empty category removes their labels.
+func (c *XmissionConfig) SetCategory(ctx context.Context, category string, hashes ...string) error {
+	if err := needIDs(hashes); err != nil {
+		return err
+	}
+
+	ids, err := c.xmissionIDs(ctx, hashes)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	labels := []string{}
+	if category != "" {
+		labels = append(labels, category)
+	}
+
+	if err := c.TorrentSet(ctx, transmissionrpc.TorrentSetPayload{IDs: ids, Labels: labels}); err != nil {
+		return fmt.Errorf("setting labels: %w", err)
+	}
+
+	return nil
+}
+
+// PauseAll stops every torrent in Transmission.
+func (c *XmissionConfig) PauseAll(ctx context.Context) error {
+	if err := c.TorrentStopIDs(ctx, nil); err != nil {
+		return fmt.Errorf("stopping torrents: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeAll starts every torrent in Transmission.
+func (c *XmissionConfig) ResumeAll(ctx context.Context) error {
+	if err := c.TorrentStartIDs(ctx, nil); err != nil {
+		return fmt.Errorf("starting torrents: %w", err)
+	}
+
+	return nil
+}
+
+// SetSpeedLimits sets the global speed limits in Transmission. A zero limit disables it.
+func (c *XmissionConfig) SetSpeedLimits(ctx context.Context, download, upload int64) error {
+	down, up := max(download/xmissionKilobyte, 1), max(upload/xmissionKilobyte, 1)
+	downOn, upOn := download > 0, upload > 0
+
+	err := c.SessionArgumentsSet(ctx, transmissionrpc.SessionArguments{
+		SpeedLimitDownEnabled: &downOn,
+		SpeedLimitDown:        &down,
+		SpeedLimitUpEnabled:   &upOn,
+		SpeedLimitUp:          &up,
+	})
+	if err != nil {
+		return fmt.Errorf("setting session arguments: %w", err)
+	}
+
+	return nil
+}
@@ -136,6 +136,9 @@ function addServiceCheck()
                     '<option value="tcp">TCP Port</option>'+
                     '<option value="ping">UDP Ping</option>'+
                     '<option value="icmp">ICMP Ping</option>'+
+                    '<option value="dns">DNS Lookup</option>'+
+                    '<option value="zfs">ZFS Pool</option>'+
+                    '<option value="container">Container</option>'+
                 '</select>'+
             '</div>'+
         '</div>'+
@@ -231,6 +234,7 @@ function addServiceCheck()
                 '<input type="number" min="0" onChange="checkExpectChange($(this));" title="Minimum number of proesses allowed to run." placeholder="Min" data-index="'+ index +'" data-app="checks" class="form-control input-sm serviceProcessParam serviceProcessParamMin" value="0" style="width:30%;display:none;">'+
                 '<input type="number" min="0" onChange="checkExpectChange($(this));" title="Maximm number of process allowed to run." placeholder="Max" data-index="'+ index +'" data-app="checks" class="form-control input-sm serviceProcessParam serviceProcessParamMax" value="0" style="width:30%;display:none;">'+
                 '<input disabled type="text" data-app="checks" value="unused" class="form-control input-sm serviceTCPParam" style="display:none;">'+
+                '<input type="text" onChange="checkExpectChange($(this));" title="Record type and optional answers, ex: A:10.1.1.2" placeholder="A:10.1.1.2" class="form-control input-sm serviceDNSParam" value="A" style="display:none;">'+
             '</div>'+
         '</div>'+
     '</td>'+
@@ -294,6 +298,7 @@ function checkTypeChange(from)
     ctl.find('.serviceHTTPParam').hide();
     ctl.find('.serviceTCPParam').hide();
     ctl.find('.servicePingParam').hide();
+    ctl.find('.serviceDNSParam').hide();
 
     switch (from.val()) {
         case "process":
@@ -303,12 +308,17 @@ function checkTypeChange(from)
             checkExpectChange(ctl.find('.serviceHTTPParam').show());
             break;
         case "tcp":
+        case "zfs":
+        case "container":
             checkExpectChange(ctl.find('.serviceTCPParam').show());
             break;
         case "ping":
         case "icmp":
             checkExpectChange(ctl.find('.servicePingParam').show());
             break;
+        case "dns":
+            checkExpectChange(ctl.find('.serviceDNSParam').show());
+            break;
     }
 
     toggleServiceTypeSelects();
@@ -331,6 +341,8 @@ function checkExpectChange(from) {
         expect.val(from.val().join());
     } else if (from.hasClass('serviceTCPParam')) { // it's a "tcp" check.
         expect.val('');
+    } else if (from.hasClass('serviceDNSParam')) { // it's a "dns" check.
+        expect.val(from.val());
     } else if (run) { // it's a "process" check in "running" mode.
         // Copy "running" into real 'expect' value that is POSTed.
         expect.val('running');
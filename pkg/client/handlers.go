@@ -103,6 +103,16 @@ func (c *Client) httpAPIHandlers() {
 	// Aggregate handlers. Non-app specific.
 	c.Config.HandleAPIpath("", "/trash/{app}", c.triggers.CFSync.Handler, "POST")
 
+	// Service check history. These return 501 if no history file is configured.
+	c.Config.HandleAPIpath("", "services/history/{service}", c.Config.Services.HandleHistory, "GET")
+	c.Config.HandleAPIpath("", "services/uptime", c.Config.Services.HandleUptime, "GET")
+	c.Config.HandleAPIpath("", "services/uptime/{service}", c.Config.Services.HandleUptime, "GET")
+
+	if c.Config.Metrics {
+		c.Config.Router.Handle(path.Join(c.Config.URLBase, "metrics"),
+			c.checkMetricsKey(http.HandlerFunc(c.handleMetrics))).Methods("GET")
+	}
+
 	if c.Config.Plex.Enabled() {
 		c.Config.HandleAPIpath(starr.Plex, "sessions", c.Config.Plex.HandleSessions, "GET")
 		c.Config.HandleAPIpath(starr.Plex, "directory", c.Config.Plex.HandleDirectory, "GET")
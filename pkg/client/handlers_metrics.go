@@ -0,0 +1,324 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/services"
+	"github.com/Notifiarr/notifiarr/pkg/snapshot"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/data"
+	"golift.io/version"
+)
+
+/* This file exports our expvar counters and the latest snapshot in the Prometheus text format. */
+
+const metricsPrefix = "notifiarr_"
+
+// promWriter collects metrics and writes them in the Prometheus text exposition format (version 0.0.4).
+// Samples are grouped by metric family, in the order each family was first seen.
+type promWriter struct {
+	families []*promFamily
+	index    map[string]*promFamily
+}
+
+type promFamily struct {
+	name    string
+	kind    string
+	help    string
+	samples []string
+}
+
+// promLabels is a list of label name/value pairs: name1, value1, name2, value2...
+type promLabels []string
+
+// metric adds one sample to a metric family.
+func (p *promWriter) metric(name, kind, help string, labels promLabels, value float64) {
+	family := p.index[name]
+	if family == nil {
+		family = &promFamily{name: metricsPrefix + name, kind: kind, help: help}
+		p.index[name] = family
+		p.families = append(p.families, family)
+	}
+
+	sample := family.name
+
+	if len(labels) > 1 {
+		pairs := make([]string, 0, len(labels)/2) //nolint:gomnd
+		for idx := 0; idx+1 < len(labels); idx += 2 {
+			pairs = append(pairs, labels[idx]+`="`+promEscape(labels[idx+1])+`"`)
+		}
+
+		sample += "{" + strings.Join(pairs, ",") + "}"
+	}
+
+	family.samples = append(family.samples, sample+" "+strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// writeTo writes all the collected metrics.
+func (p *promWriter) writeTo(output io.Writer) {
+	buf := bufio.NewWriter(output)
+	defer buf.Flush()
+
+	for _, family := range p.families {
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", family.name, family.help, family.name, family.kind)
+
+		for _, sample := range family.samples {
+			buf.WriteString(sample + "\n")
+		}
+	}
+}
+
+func promEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// promName turns an expvar key like "Request Errors" into "request_errors".
+func promName(key string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, key), "_")
+}
+
+// sortedKeys returns the keys of an expvar output map in a stable order.
+func sortedKeys[V any](input map[string]V) []string {
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// handleMetrics exports client metrics for Prometheus.
+// Snapshot values are only available when the snapshot timer is enabled.
+func (c *Client) handleMetrics(response http.ResponseWriter, _ *http.Request) {
+	response.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	prom := &promWriter{index: make(map[string]*promFamily)}
+	defer prom.writeTo(response)
+
+	expvar := mnd.GetAllData()
+
+	prom.metric("info", "gauge", "Notifiarr client information.",
+		promLabels{"version", version.Version, "os", runtime.GOOS, "docker", fmt.Sprint(mnd.IsDocker)}, 1)
+	c.promServices(prom, expvar.ServiceChecks)
+	promSuffixCounters(prom, "website", "Outbound requests to notifiarr.com.", "method", expvar.Website)
+	promSuffixCounters(prom, "filewatch", "File watcher counters.", "path", expvar.FileWatcher)
+	c.promTriggers(prom, expvar.TimerEvents)
+	promSnapshot(prom)
+}
+
+// promServices exports the current service check states and the expvar check counters.
+func (c *Client) promServices(prom *promWriter, counters map[string]map[string]interface{}) {
+	results := c.Config.Services.GetResults()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	for _, svc := range results {
+		labels := promLabels{"name", svc.Name, "type", string(svc.Type)}
+		up := 0.0
+
+		if svc.State == services.StateOK || svc.State == services.StateWarning {
+			up = 1
+		}
+
+		prom.metric("service_state", "gauge", "Service check state: 0 OK, 1 Warning, 2 Critical, 3 Unknown.",
+			labels, float64(svc.State))
+		prom.metric("service_up", "gauge", "Service check is OK or Warning.", labels, up)
+		prom.metric("service_latency_seconds", "gauge", "How long the last service check took.",
+			labels, svc.Latency.Seconds())
+		prom.metric("service_flapping", "gauge", "Service check is flapping.", labels, promBool(svc.Flapping))
+
+		if !svc.Time.IsZero() {
+			prom.metric("service_last_check_timestamp_seconds", "gauge", "When the service was last checked.",
+				labels, float64(svc.Time.Unix()))
+			prom.metric("service_state_since_timestamp_seconds", "gauge", "When the service entered its current state.",
+				labels, float64(svc.Since.Unix()))
+		}
+	}
+
+	for _, name := range sortedKeys(counters) {
+		for _, result := range sortedKeys(counters[name]) {
+			value, _ := counters[name][result].(int64)
+			prom.metric("service_checks_total", "counter", "Service check results by name and result.",
+				promLabels{"name", name, "result", result}, float64(value))
+		}
+	}
+}
+
+// promSuffixCounters exports expvar counters that are named "<label><suffix>",
+// like "POST Bytes Sent", as <prefix>_bytes_sent_total{method="POST"}.
+func promSuffixCounters(prom *promWriter, prefix, help, label string, counters map[string]interface{}) {
+	suffixes := []string{mnd.BytesSent, mnd.BytesReceived, mnd.Requests, " Retries", " Matched", " Errors", " Lines", " Bytes"}
+
+	for _, key := range sortedKeys(counters) {
+		value, _ := counters[key].(int64)
+
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(key, suffix) {
+				name := prefix + "_" + promName(suffix) + "_total"
+				prom.metric(name, "counter", help, promLabels{label, strings.TrimSuffix(key, suffix)}, float64(value))
+
+				break
+			}
+		}
+	}
+}
+
+// promTriggers exports trigger and timer run counts by trigger name and event source.
+func (c *Client) promTriggers(prom *promWriter, events map[string]map[string]interface{}) {
+	for _, event := range sortedKeys(events) {
+		for _, trigger := range sortedKeys(events[event]) {
+			value, _ := events[event][trigger].(int64)
+			prom.metric("trigger_runs_total", "counter", "Triggers and timers executed by name and event source.",
+				promLabels{"trigger", trigger, "event", event}, float64(value))
+		}
+	}
+}
+
+// promSnapshot exports the most recent system snapshot.
+func promSnapshot(prom *promWriter) {
+	item := data.Get("snapshot")
+	if item == nil {
+		return
+	}
+
+	snap, _ := item.Data.(*snapshot.Snapshot)
+	if snap == nil {
+		return
+	}
+
+	prom.metric("snapshot_timestamp_seconds", "gauge", "When the last system snapshot was taken.",
+		nil, float64(item.Time.Unix()))
+	prom.metric("system_cpu_percent", "gauge", "CPU usage percent.", nil, snap.System.CPU)
+	prom.metric("system_memory_free_bytes", "gauge", "Free memory.", nil, float64(snap.System.MemFree))
+	prom.metric("system_memory_used_bytes", "gauge", "Used memory.", nil, float64(snap.System.MemUsed))
+	prom.metric("system_memory_total_bytes", "gauge", "Total memory.", nil, float64(snap.System.MemTotal))
+	prom.metric("system_users", "gauge", "Logged in users.", nil, float64(snap.System.Users))
+
+	if snap.System.InfoStat != nil {
+		prom.metric("system_uptime_seconds", "gauge", "System uptime.", nil, float64(snap.System.Uptime))
+	}
+
+	if snap.System.AvgStat != nil {
+		prom.metric("system_load", "gauge", "System load average.", promLabels{"period", "1m"}, snap.System.Load1)
+		prom.metric("system_load", "gauge", "System load average.", promLabels{"period", "5m"}, snap.System.Load5)
+		prom.metric("system_load", "gauge", "System load average.", promLabels{"period", "15m"}, snap.System.Load15)
+	}
+
+	for _, sensor := range sortedKeys(snap.System.Temps) {
+		prom.metric("system_temperature_celsius", "gauge", "System temperature sensors.",
+			promLabels{"sensor", sensor}, snap.System.Temps[sensor])
+	}
+
+	promPartitions(prom, "disk", "Disk usage", snap.DiskUsage)
+	promPartitions(prom, "quota", "Quota usage", snap.Quotas)
+	promPartitions(prom, "zfs_pool", "ZFS pool usage", snap.ZFSPool)
+
+	for _, pool := range sortedKeys(snap.ZFSHealth) {
+		health, labels := snap.ZFSHealth[pool], promLabels{"pool", pool}
+		prom.metric("zfs_pool_info", "gauge", "ZFS pool state, always 1.",
+			promLabels{"pool", pool, "state", health.State}, 1)
+		prom.metric("zfs_pool_healthy", "gauge", "ZFS pool state is ONLINE.", labels, promBool(health.State == snapshot.ZFSOnline))
+		prom.metric("zfs_pool_vdev_errors", "gauge", "ZFS pool vdev read, write and checksum errors.",
+			labels, float64(health.VdevErrors()))
+		prom.metric("zfs_pool_data_errors", "gauge", "ZFS pool permanent data errors.", labels, float64(health.DataErrors))
+		prom.metric("zfs_pool_scan_errors", "gauge", "ZFS pool errors found by the last scrub or resilver.",
+			labels, float64(health.Scan.Errors))
+	}
+
+	if snap.ZFSARC != nil {
+		prom.metric("zfs_arc_hits_total", "counter", "ZFS ARC cache hits.", nil, float64(snap.ZFSARC.Hits))
+		prom.metric("zfs_arc_misses_total", "counter", "ZFS ARC cache misses.", nil, float64(snap.ZFSARC.Misses))
+		prom.metric("zfs_arc_size_bytes", "gauge", "ZFS ARC cache size.", nil, float64(snap.ZFSARC.Size))
+	}
+
+	for _, drive := range sortedKeys(snap.DriveTemps) {
+		prom.metric("drive_temperature_celsius", "gauge", "Drive temperature from SMART data.",
+			promLabels{"device", drive}, float64(snap.DriveTemps[drive]))
+	}
+
+	for _, drive := range sortedKeys(snap.DriveAges) {
+		prom.metric("drive_power_on_hours", "gauge", "Drive age from SMART data.",
+			promLabels{"device", drive}, float64(snap.DriveAges[drive]))
+	}
+
+	for _, drive := range sortedKeys(snap.DiskHealth) {
+		prom.metric("drive_healthy", "gauge", "Drive SMART health passed.",
+			promLabels{"device", drive}, promBool(snap.DiskHealth[drive] == "PASSED" || snap.DiskHealth[drive] == "OK"))
+	}
+
+	for _, drive := range sortedKeys(snap.Smart) {
+		smart, labels := snap.Smart[drive], promLabels{"device", drive}
+		prom.metric("drive_reallocated_sectors", "gauge", "Drive reallocated sector count.", labels, float64(smart.Reallocated))
+		prom.metric("drive_pending_sectors", "gauge", "Drive current pending sector count.", labels, float64(smart.Pending))
+		prom.metric("drive_uncorrectable_sectors", "gauge", "Drive offline uncorrectable sector count.",
+			labels, float64(smart.Uncorrectable))
+		prom.metric("drive_crc_errors", "gauge", "Drive interface CRC error count.", labels, float64(smart.CRCErrors))
+		prom.metric("drive_media_errors", "gauge", "NVMe drive media and data integrity errors.", labels, float64(smart.MediaErrors))
+		prom.metric("drive_percent_used", "gauge", "NVMe drive endurance used.", labels, float64(smart.PercentUsed))
+	}
+
+	for _, container := range snap.Containers {
+		labels := promLabels{"container", container.Name, "image", container.Image}
+		prom.metric("container_running", "gauge", "Container state is running.",
+			labels, promBool(container.State == snapshot.ContainerRunning))
+		prom.metric("container_healthy", "gauge", "Container health check is not unhealthy.",
+			labels, promBool(container.Health != snapshot.ContainerUnhealthy))
+		prom.metric("container_restarts", "gauge", "Container restart count.", labels, float64(container.Restarts))
+		prom.metric("container_cpu_percent", "gauge", "Container CPU usage percent.", labels, container.CPU)
+		prom.metric("container_memory_used_bytes", "gauge", "Container memory usage.", labels, float64(container.MemUsed))
+	}
+}
+
+// promPartitions exports usage for disks, quotas and zfs pools.
+func promPartitions(prom *promWriter, name, help string, parts map[string]*snapshot.Partition) {
+	for _, dev := range sortedKeys(parts) {
+		part := parts[dev]
+		if part.Used == 0 && part.Total > part.Free {
+			part = &snapshot.Partition{Device: part.Device, Total: part.Total, Free: part.Free, Used: part.Total - part.Free}
+		}
+
+		labels := promLabels{"device", dev, "mount", part.Device}
+		prom.metric(name+"_total_bytes", "gauge", help+": total bytes.", labels, float64(part.Total))
+		prom.metric(name+"_free_bytes", "gauge", help+": free bytes.", labels, float64(part.Free))
+		prom.metric(name+"_used_bytes", "gauge", help+": used bytes.", labels, float64(part.Used))
+	}
+}
+
+func promBool(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// checkMetricsKey allows Prometheus to pass the API key as an apikey query parameter,
+// because many scrapers cannot set custom headers. The key is redacted from the http log.
+func (c *Client) checkMetricsKey(next http.Handler) http.Handler {
+	check := c.Config.Apps.CheckAPIKey(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { //nolint:varnamelen
+		if key := r.URL.Query().Get("apikey"); key != "" && r.Header.Get("X-API-Key") == "" {
+			r.Header.Set("X-API-Key", key)
+		}
+
+		check.ServeHTTP(w, r)
+	})
+}
@@ -136,6 +136,10 @@ func (c *Client) testInstance(response http.ResponseWriter, request *http.Reques
 		if len(config.Service) > index {
 			reply, code = testPing(request.Context(), config.Service[index])
 		}
+	case "Dns":
+		if len(config.Service) > index {
+			reply, code = testDNS(request.Context(), config.Service[index])
+		}
 	// Media
 	case "Plex":
 		reply, code = testPlex(request.Context(), config.Plex)
@@ -388,6 +392,19 @@ func testPing(ctx context.Context, svc *services.Service) (string, int) {
 	return "Ping Tested OK: " + res.Output, http.StatusOK
 }
 
+func testDNS(ctx context.Context, svc *services.Service) (string, int) {
+	if err := svc.Validate(); err != nil {
+		return validation + err.Error(), http.StatusBadRequest
+	}
+
+	res := svc.CheckOnly(ctx)
+	if res.State != services.StateOK {
+		return res.State.String() + " " + res.Output, http.StatusBadGateway
+	}
+
+	return "DNS Lookup Tested OK: " + res.Output, http.StatusOK
+}
+
 func testPlex(ctx context.Context, app *apps.PlexConfig) (string, int) {
 	app.Setup(0, nil)
 
@@ -113,6 +113,8 @@ func Start() error {
 		return err
 	case client.Flags.Curl != "": // curl a URL and exit.
 		return curlURL(client.Flags.Curl, client.Flags.Headers)
+	case client.Flags.CheckConfig: // validate the config and exit.
+		return client.checkConfig(ctx)
 	default:
 		return client.start(ctx)
 	}
@@ -181,6 +183,15 @@ func (c *Client) start(ctx context.Context) error { //nolint:cyclop
 	return c.Exit(ctx, cancel)
 }
 
+// checkConfig finds and validates the config file, prints a report, and returns an error if it has problems.
+func (c *Client) checkConfig(ctx context.Context) error {
+	if c.Flags.ConfigFile == "" {
+		c.Flags.ConfigFile, _, _ = c.Config.FindAndReturn(ctx, "", false)
+	}
+
+	return c.Config.Check(c.Flags).Print(os.Stdout) //nolint:wrapcheck
+}
+
 func (c *Client) makeNewConfigFile(ctx context.Context, newPassword string) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
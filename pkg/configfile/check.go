@@ -0,0 +1,312 @@
+package configfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golift.io/cnfg"
+	"golift.io/cnfgfile"
+)
+
+/* This file handles the --check-config cli mode. It loads the config like the app does,
+   validates as much as possible without connecting to anything, and prints a report. */
+
+// Check report severities.
+const (
+	CheckError   = "ERROR"
+	CheckWarning = "WARNING"
+)
+
+// ErrCheckFailed is returned by CheckReport.Print when the config has errors.
+var ErrCheckFailed = fmt.Errorf("config check failed")
+
+// CheckItem is one problem found in the config.
+type CheckItem struct {
+	Level   string `json:"level"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Section string `json:"section,omitempty"` // like "[[sonarr]] #2"
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+// CheckReport is the output from Check.
+type CheckReport struct {
+	Files []string     `json:"files"`
+	Items []*CheckItem `json:"items"`
+	lines []*fileLines
+}
+
+// fileLines is an index of section headers and keys in one toml config file.
+type fileLines struct {
+	file string
+	keys map[string]int // section#index/key -> line number.
+}
+
+// headerRe matches [table] and [[array]] headers, with optional trailing comments.
+var headerRe = regexp.MustCompile(`^\s*(\[\[?)\s*([A-Za-z0-9_.\-"]+)\s*\]\]?\s*(#.*)?$`)
+
+// keyRe matches the key on a key = value line.
+var keyRe = regexp.MustCompile(`^\s*([A-Za-z0-9_\-"]+)\s*=`)
+
+// Check loads the config files and environment variables the same way the app does and validates the result.
+// Nothing is started, and no network connections are made.
+// The report contains every problem found, with file and line references when the setting came from a file.
+func (c *Config) Check(flag *Flags) *CheckReport {
+	report := &CheckReport{Files: append([]string{}, flag.ExtraConf...)}
+	if flag.ConfigFile != "" {
+		report.Files = append([]string{flag.ConfigFile}, report.Files...)
+	}
+
+	if len(report.Files) == 0 {
+		report.add(CheckWarning, "", 0, "no config file provided; checking environment variables only")
+	}
+
+	// Load each file individually so errors can be attributed to the right file.
+	for _, file := range report.Files {
+		if !report.loadFile(c, file) {
+			return report
+		}
+	}
+
+	if _, err := cnfg.UnmarshalENV(c, flag.EnvPrefix); err != nil {
+		report.add(CheckError, "environment", 0, "parsing %s_ environment variables: %v", flag.EnvPrefix, err)
+		return report
+	}
+
+	if err := c.setupPassword(); err != nil {
+		report.item(CheckError, "", 0, "ui_password", "%v", err)
+	}
+
+	c.fixConfig()
+	c.checkAll(report)
+
+	return report
+}
+
+// loadFile unmarshals one config file into the config and indexes its lines.
+// Returns false if the file cannot be parsed; later checks would only report noise.
+func (r *CheckReport) loadFile(config *Config, file string) bool {
+	if err := cnfgfile.Unmarshal(config, file); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			r.Items = append(r.Items, &CheckItem{
+				Level:   CheckError,
+				File:    file,
+				Line:    parseErr.Position.Line,
+				Message: strings.TrimPrefix(parseErr.Error(), "toml: "),
+			})
+		} else {
+			r.Items = append(r.Items, &CheckItem{Level: CheckError, File: file, Message: err.Error()})
+		}
+
+		return false
+	}
+
+	ext := strings.ToLower(filepath.Ext(file))
+	if ext == ".json" || ext == ".xml" || ext == ".yaml" || ext == ".yml" {
+		return true // Line references are only available for toml files.
+	}
+
+	lines, err := indexLines(file)
+	if err != nil {
+		r.Items = append(r.Items, &CheckItem{Level: CheckWarning, File: file, Message: err.Error()})
+		return true
+	}
+
+	r.lines = append(r.lines, lines)
+	r.checkUnknownKeys(file)
+
+	return true
+}
+
+// checkUnknownKeys reports keys in a toml file that do not map to any config setting; usually typos.
+func (r *CheckReport) checkUnknownKeys(file string) {
+	meta, err := toml.DecodeFile(file, NewConfig(nil))
+	if err != nil {
+		return
+	}
+
+	for _, key := range meta.Undecoded() {
+		section, name := "", key.String()
+		if len(key) > 1 {
+			section, name = strings.Join(key[:len(key)-1], "."), key[len(key)-1]
+		}
+
+		item := &CheckItem{Level: CheckWarning, File: file, Key: name, Message: "unknown setting, it is ignored"}
+		if section != "" {
+			item.Section = "[" + section + "]"
+		}
+
+		for _, lines := range r.lines {
+			if lines.file == file {
+				item.Line = lines.find(section, name)
+			}
+		}
+
+		r.Items = append(r.Items, item)
+	}
+}
+
+// indexLines records the line number of every section header and key in a toml file.
+// Array sections are numbered from 1 in the order they appear: "sonarr#2/url".
+func indexLines(file string) (*fileLines, error) {
+	fileOpen, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("opening file for line numbers: %w", err)
+	}
+	defer fileOpen.Close()
+
+	lines := &fileLines{file: file, keys: make(map[string]int)}
+	counts := make(map[string]int)
+	section := ""
+	scanner := bufio.NewScanner(fileOpen)
+
+	for num := 1; scanner.Scan(); num++ {
+		text := scanner.Text()
+
+		if match := headerRe.FindStringSubmatch(text); match != nil {
+			section = strings.ReplaceAll(match[2], `"`, "")
+
+			if match[1] == "[[" {
+				counts[section]++
+				section += "#" + strconv.Itoa(counts[section])
+			}
+
+			if _, ok := lines.keys[section]; !ok {
+				lines.keys[section] = num
+			}
+
+			continue
+		}
+
+		if match := keyRe.FindStringSubmatch(text); match != nil {
+			key := section + "/" + strings.Trim(match[1], `"`)
+			if _, ok := lines.keys[key]; !ok {
+				lines.keys[key] = num
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading file for line numbers: %w", err)
+	}
+
+	return lines, nil
+}
+
+// find returns the line for a key in a section, or the section header if the key is not in the file.
+// Section may include an array index (sonarr#2). Without an index, the first array entry with the key wins.
+func (f *fileLines) find(section, key string) int {
+	if line, ok := f.keys[section+"/"+key]; ok && key != "" {
+		return line
+	}
+
+	if !strings.Contains(section, "#") {
+		for idx := 1; ; idx++ {
+			sec := section + "#" + strconv.Itoa(idx)
+			if _, ok := f.keys[sec]; !ok {
+				break
+			}
+
+			if line, ok := f.keys[sec+"/"+key]; ok && key != "" {
+				return line
+			}
+		}
+	}
+
+	return f.keys[section]
+}
+
+// add records a problem that belongs to a whole section, or the whole config when section is empty.
+func (r *CheckReport) add(level, section string, idx int, msg string, vals ...interface{}) {
+	r.item(level, section, idx, "", msg, vals...)
+}
+
+// item records a problem with a setting. Array sections have an idx starting at 1; tables use 0.
+// The file and line are looked up from the last config file that contains the setting.
+func (r *CheckReport) item(level, section string, idx int, key, msg string, vals ...interface{}) {
+	item := &CheckItem{Level: level, Key: key, Message: fmt.Sprintf(msg, vals...)}
+	lookup := section
+
+	switch {
+	case section == "environment":
+		item.Section = section
+	case idx > 0:
+		item.Section = fmt.Sprintf("[[%s]] #%d", section, idx)
+		lookup += "#" + strconv.Itoa(idx)
+	case section != "":
+		item.Section = "[" + section + "]"
+	}
+
+	for i := len(r.lines) - 1; i >= 0 && section != "environment"; i-- {
+		if line := r.lines[i].find(lookup, key); line != 0 {
+			item.File, item.Line = r.lines[i].file, line
+			break
+		}
+	}
+
+	r.Items = append(r.Items, item)
+}
+
+// Errors returns the number of errors in the report.
+func (r *CheckReport) Errors() (count int) {
+	for _, item := range r.Items {
+		if item.Level == CheckError {
+			count++
+		}
+	}
+
+	return count
+}
+
+// String turns a check item into one report line: file:line: LEVEL [section] key: message.
+func (i *CheckItem) String() string {
+	var out strings.Builder
+
+	switch {
+	case i.File != "" && i.Line != 0:
+		fmt.Fprintf(&out, "%s:%d: ", i.File, i.Line)
+	case i.File != "":
+		out.WriteString(i.File + ": ")
+	}
+
+	out.WriteString(i.Level)
+
+	if i.Section != "" {
+		out.WriteString(" " + i.Section)
+	}
+
+	if i.Key != "" {
+		out.WriteString(" " + i.Key)
+	}
+
+	return out.String() + ": " + i.Message
+}
+
+// Print writes the report and returns ErrCheckFailed if there are any errors.
+// Warnings are printed, but do not fail the check.
+func (r *CheckReport) Print(output io.Writer) error {
+	fmt.Fprintf(output, "Checked config files: %s\n", strings.Join(r.Files, ", "))
+
+	for _, item := range r.Items {
+		fmt.Fprintln(output, item)
+	}
+
+	errs := r.Errors()
+	fmt.Fprintf(output, "%d errors, %d warnings\n", errs, len(r.Items)-errs)
+
+	if errs > 0 {
+		return fmt.Errorf("%w: %d errors", ErrCheckFailed, errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,388 @@
+package configfile
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/services"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+)
+
+/* This file has the individual config validators used by Check. */
+
+// API key formats. Starr apps, Tautulli and SABnzbd all generate 32 character keys.
+var (
+	hexKeyRe = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+	sabKeyRe = regexp.MustCompile(`^[0-9a-zA-Z]{32}$`)
+)
+
+// appCheck is the common data checked for every app instance.
+type appCheck struct {
+	section string
+	idx     int
+	url     string
+	key     string
+	keyName string
+	keyRe   *regexp.Regexp
+}
+
+func (c *Config) checkAll(report *CheckReport) {
+	c.checkGlobal(report)
+	c.checkApps(report)
+	c.checkServices(report)
+	c.checkCommands(report)
+	c.checkWatchFiles(report)
+	c.checkSnapshot(report)
+	c.checkNotifiers(report)
+}
+
+func (c *Config) checkGlobal(report *CheckReport) {
+	switch length := len(c.APIKey); {
+	case length == 0:
+		report.item(CheckError, "", 0, "api_key", "missing; the app will not start without a notifiarr.com API key")
+	case length != website.APIKeyLength:
+		report.item(CheckError, "", 0, "api_key", "must be %d characters, found %d", website.APIKeyLength, length)
+	}
+
+	for _, key := range c.ExKeys {
+		if len(key) <= 3 { //nolint:gomnd // same as apps.InitHandlers.
+			report.item(CheckWarning, "", 0, "extra_keys", "key '%s' is too short and will be ignored", key)
+		}
+	}
+
+	if _, err := net.ResolveTCPAddr("tcp", cleanBindAddr(c.BindAddr)); err != nil {
+		report.item(CheckError, "", 0, "bind_addr", "must be ip:port or a port: %v", err)
+	}
+
+	if (c.SSLCrtFile == "") != (c.SSLKeyFile == "") {
+		report.item(CheckError, "", 0, "ssl_cert_file", "ssl_cert_file and ssl_key_file must be provided together")
+	}
+
+	for _, file := range []string{c.SSLCrtFile, c.SSLKeyFile} {
+		if _, err := os.Stat(file); file != "" && err != nil {
+			report.item(CheckError, "", 0, "ssl_cert_file", "%v", err)
+		}
+	}
+
+	for _, upstream := range c.Upstreams {
+		if _, _, err := net.ParseCIDR(upstream); err != nil && net.ParseIP(upstream) == nil {
+			report.item(CheckError, "", 0, "upstreams", "'%s' is not an IP or CIDR", upstream)
+		}
+	}
+
+	if c.Queue != nil && c.Queue.Size < 0 {
+		report.item(CheckError, "queue", 0, "size", "may not be negative")
+	}
+
+	if a := c.Archive; a != nil && (a.KeepLast < 0 || a.KeepDaily < 0 || a.KeepWeekly < 0) {
+		report.add(CheckError, "backup_archive", 0, "keep_last, keep_daily and keep_weekly may not be negative")
+	}
+
+	if c.Seeding != nil {
+		if err := c.Seeding.Validate(); err != nil {
+			report.add(CheckError, "seeding", 0, "%v", err)
+		}
+	}
+
+	if c.Throttle != nil {
+		if err := c.Throttle.Validate(); err != nil {
+			report.add(CheckError, "throttle", 0, "%v", err)
+		}
+	}
+}
+
+func (c *Config) checkApps(report *CheckReport) { //nolint:cyclop
+	checks := []*appCheck{}
+
+	for idx, app := range c.Sonarr {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{"sonarr", idx + 1, app.URL, app.APIKey, "api_key", hexKeyRe})
+		}
+	}
+
+	for idx, app := range c.Radarr {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{"radarr", idx + 1, app.URL, app.APIKey, "api_key", hexKeyRe})
+		}
+	}
+
+	for idx, app := range c.Lidarr {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{"lidarr", idx + 1, app.URL, app.APIKey, "api_key", hexKeyRe})
+		}
+	}
+
+	for idx, app := range c.Readarr {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{"readarr", idx + 1, app.URL, app.APIKey, "api_key", hexKeyRe})
+		}
+	}
+
+	for idx, app := range c.Prowlarr {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{"prowlarr", idx + 1, app.URL, app.APIKey, "api_key", hexKeyRe})
+		}
+	}
+
+	for idx, app := range c.SabNZB {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{"sabnzbd", idx + 1, app.URL, app.APIKey, "api_key", sabKeyRe})
+		}
+	}
+
+	for idx, app := range c.Deluge {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{"deluge", idx + 1, app.URL, app.Password, "password", nil})
+		}
+	}
+
+	for idx, app := range c.Qbit {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{section: "qbit", idx: idx + 1, url: app.URL})
+		}
+	}
+
+	for idx, app := range c.NZBGet {
+		if app != nil && app.Config != nil {
+			checks = append(checks, &appCheck{section: "nzbget", idx: idx + 1, url: app.URL})
+		}
+	}
+
+	for idx, app := range c.Rtorrent {
+		if app != nil {
+			checks = append(checks, &appCheck{section: "rtorrent", idx: idx + 1, url: app.URL})
+		}
+	}
+
+	for idx, app := range c.Transmission {
+		if app != nil {
+			checks = append(checks, &appCheck{section: "transmission", idx: idx + 1, url: app.URL})
+		}
+	}
+
+	if c.Tautulli != nil && c.Tautulli.URL != "" {
+		checks = append(checks, &appCheck{"tautulli", 0, c.Tautulli.URL, c.Tautulli.APIKey, "api_key", hexKeyRe})
+	}
+
+	if c.Plex != nil && c.Plex.Config != nil && c.Plex.URL != "" {
+		checks = append(checks, &appCheck{"plex", 0, c.Plex.URL, c.Plex.Token, "token", nil})
+	}
+
+	for _, check := range checks {
+		check.check(report)
+	}
+
+	if c.Plex != nil && c.Plex.BackupPath != "" {
+		if _, err := os.Stat(c.Plex.BackupPath); err != nil {
+			report.item(CheckWarning, "plex", 0, "backup_path", "%v", err)
+		}
+	}
+
+	if c.Tautulli != nil && c.Tautulli.BackupPath != "" {
+		if _, err := os.Stat(c.Tautulli.BackupPath); err != nil {
+			report.item(CheckWarning, "tautulli", 0, "backup_path", "%v", err)
+		}
+	}
+
+	// Setup catches anything else the app would refuse to start with.
+	if report.Errors() == 0 {
+		if err := c.Apps.Setup(); err != nil {
+			report.add(CheckError, "", 0, "apps: %v", err)
+		}
+	}
+}
+
+func (a *appCheck) check(report *CheckReport) {
+	if a.url == "" {
+		report.item(CheckError, a.section, a.idx, "url", "missing")
+	} else if uri, err := url.Parse(a.url); err != nil {
+		report.item(CheckError, a.section, a.idx, "url", "invalid: %v", err)
+	} else if a.section != "rtorrent" && uri.Scheme != "http" && uri.Scheme != "https" {
+		report.item(CheckError, a.section, a.idx, "url", "must begin with http:// or https://")
+	} else if uri.Host == "" {
+		report.item(CheckError, a.section, a.idx, "url", "missing a host name")
+	}
+
+	switch {
+	case a.keyName == "":
+	case a.key == "":
+		report.item(CheckError, a.section, a.idx, a.keyName, "missing")
+	case a.keyRe != nil && !a.keyRe.MatchString(a.key):
+		report.item(CheckError, a.section, a.idx, a.keyName, "does not look valid; expected 32 letters and numbers")
+	}
+}
+
+func (c *Config) checkServices(report *CheckReport) {
+	if c.Services.Interval.Duration != 0 && c.Services.Interval.Duration < services.MinimumSendInterval {
+		report.item(CheckWarning, "services", 0, "interval", "raised to the minimum: %v", services.MinimumSendInterval)
+	}
+
+	if c.Services.Parallel > services.MaximumParallel {
+		report.item(CheckWarning, "services", 0, "parallel", "lowered to the maximum: %d", services.MaximumParallel)
+	}
+
+	names := make(map[string]int)
+	failed := false
+
+	for idx, svc := range c.Service {
+		if err := svc.Validate(); err != nil {
+			report.add(CheckError, "service", idx+1, "%v", err)
+			failed = true
+		}
+
+		if first, ok := names[svc.Name]; ok && svc.Name != "" {
+			report.item(CheckError, "service", idx+1, "name", "'%s' is already used by service #%d", svc.Name, first)
+			failed = true
+		} else {
+			names[svc.Name] = idx + 1
+		}
+	}
+
+	// Setup checks dependencies and the service checks generated from apps.
+	if !failed {
+		if err := c.Services.Setup(c.Service); err != nil {
+			report.item(CheckError, "service", serviceIndex(c.Service, err), "depends_on", "%v", err)
+		}
+	}
+}
+
+// serviceIndex finds the service an error belongs to; dependency errors begin with the service name.
+func serviceIndex(svcs []*services.Service, err error) int {
+	for idx, svc := range svcs {
+		if strings.HasPrefix(err.Error(), svc.Name+":") {
+			return idx + 1
+		}
+	}
+
+	return 0
+}
+
+func (c *Config) checkCommands(report *CheckReport) {
+	names := make(map[string]int)
+
+	for idx, cmd := range c.Commands {
+		if strings.TrimSpace(cmd.Command) == "" {
+			report.item(CheckError, "command", idx+1, "command", "missing")
+			continue
+		}
+
+		if err := cmd.SetupRegexpArgs(); err != nil {
+			report.item(CheckError, "command", idx+1, "command", "%v", err)
+		}
+
+		if cmd.Timeout.Duration < 0 {
+			report.item(CheckError, "command", idx+1, "timeout", "may not be negative")
+		}
+
+		if first, ok := names[cmd.Name]; ok && cmd.Name != "" {
+			report.item(CheckWarning, "command", idx+1, "name", "'%s' is already used by command #%d", cmd.Name, first)
+		} else {
+			names[cmd.Name] = idx + 1
+		}
+	}
+}
+
+func (c *Config) checkWatchFiles(report *CheckReport) {
+	for idx, watch := range c.WatchFiles {
+		if watch.Path == "" {
+			report.item(CheckError, "watch_file", idx+1, "path", "missing")
+		} else if _, err := os.Stat(watch.Path); err != nil && watch.MustExist {
+			report.item(CheckError, "watch_file", idx+1, "path", "%v", err)
+		} else if err != nil {
+			report.item(CheckWarning, "watch_file", idx+1, "path", "%v", err)
+		}
+
+		if watch.Regexp == "" {
+			report.item(CheckError, "watch_file", idx+1, "regex", "missing")
+		} else if _, err := regexp.Compile(watch.Regexp); err != nil {
+			report.item(CheckError, "watch_file", idx+1, "regex", "%v", err)
+		}
+
+		if _, err := regexp.Compile(watch.Skip); err != nil {
+			report.item(CheckError, "watch_file", idx+1, "skip", "%v", err)
+		}
+	}
+}
+
+func (c *Config) checkSnapshot(report *CheckReport) { //nolint:cyclop
+	snap := c.Snapshot
+
+	if snap.Timeout.Duration < 0 || snap.Interval.Duration < 0 {
+		report.add(CheckError, "snapshot", 0, "timeout and interval may not be negative")
+	}
+
+	for key, val := range map[string]int{"iotop": snap.IOTop, "pstop": snap.PSTop, "mytop": snap.MyTop} {
+		if val < 0 {
+			report.item(CheckError, "snapshot", 0, key, "may not be negative")
+		}
+	}
+
+	if (snap.UseSudo || snap.IPMISudo) && (mnd.IsDocker || mnd.IsWindows) {
+		report.item(CheckWarning, "snapshot", 0, "use_sudo", "sudo is not available on this system and will be disabled")
+	}
+
+	for _, pool := range snap.ZFSPools {
+		if strings.TrimSpace(pool) == "" {
+			report.item(CheckError, "snapshot", 0, "zfs_pools", "pool names may not be empty")
+		}
+	}
+
+	if snap.Plugins == nil {
+		return
+	}
+
+	if nv := snap.Nvidia; nv != nil && !nv.Disabled && nv.SMIPath != "" {
+		if _, err := os.Stat(nv.SMIPath); err != nil {
+			report.item(CheckError, "snapshot.nvidia", 0, "smi_path", "%v", err)
+		}
+	}
+
+	if ct := snap.Containers; ct != nil && !ct.Disabled && ct.Socket != "" {
+		if _, err := os.Stat(strings.TrimPrefix(ct.Socket, "unix://")); err != nil {
+			report.item(CheckError, "snapshot.containers", 0, "socket", "%v", err)
+		}
+	}
+
+	if btrfs := snap.Btrfs; btrfs != nil && btrfs.Enabled && !mnd.IsLinux {
+		report.item(CheckWarning, "snapshot.btrfs", 0, "enabled", "btrfs is only collected on Linux")
+	}
+
+	if lvm := snap.LVM; lvm != nil && lvm.Enabled && !mnd.IsLinux {
+		report.item(CheckWarning, "snapshot.lvm", 0, "enabled", "lvm is only collected on Linux")
+	}
+
+	if alerts := snap.DriveAlerts; alerts != nil {
+		if alerts.MaxTemp < 0 || alerts.MaxWear < 0 {
+			report.item(CheckError, "snapshot.drive_alerts", 0, "max_temp", "max_temp and max_wear may not be negative")
+		}
+
+		for idx, drive := range alerts.Drives {
+			if drive == nil || drive.Name == "" {
+				report.item(CheckError, "snapshot.drive_alerts.drive", idx+1, "name", "missing")
+			} else if drive.MaxTemp < 0 {
+				report.item(CheckError, "snapshot.drive_alerts.drive", idx+1, "max_temp", "may not be negative")
+			}
+		}
+	}
+
+	for idx, mysql := range snap.MySQL {
+		if mysql.Host == "" {
+			report.item(CheckError, "snapshot.mysql", idx+1, "host", "missing")
+		} else if _, _, err := net.SplitHostPort(mysql.Host); err != nil && !filepath.IsAbs(mysql.Host) {
+			report.item(CheckError, "snapshot.mysql", idx+1, "host", "must be host:port or a socket path: %v", err)
+		}
+	}
+}
+
+func (c *Config) checkNotifiers(report *CheckReport) {
+	for idx, sink := range c.Notifiers {
+		if err := sink.Validate(); err != nil {
+			report.add(CheckError, "notifier", idx+1, "%v", err)
+		}
+	}
+}
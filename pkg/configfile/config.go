@@ -21,11 +21,16 @@ import (
 	"github.com/Notifiarr/notifiarr/pkg/apps"
 	"github.com/Notifiarr/notifiarr/pkg/logs"
 	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/notifier"
 	"github.com/Notifiarr/notifiarr/pkg/services"
 	"github.com/Notifiarr/notifiarr/pkg/snapshot"
 	"github.com/Notifiarr/notifiarr/pkg/triggers"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/backups"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/commands"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/data"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/filewatch"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/seeding"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/throttle"
 	"github.com/Notifiarr/notifiarr/pkg/ui"
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/Notifiarr/notifiarr/pkg/website/clientinfo"
@@ -62,8 +67,14 @@ type Config struct {
 	Services   *services.Config       `json:"services" toml:"services" xml:"services" yaml:"services"`
 	Service    []*services.Service    `json:"service" toml:"service" xml:"service" yaml:"service"`
 	EnableApt  bool                   `json:"apt" toml:"apt" xml:"apt" yaml:"apt"`
+	Metrics    bool                   `json:"metrics" toml:"metrics" xml:"metrics" yaml:"metrics"`
 	WatchFiles []*filewatch.WatchFile `json:"watchFiles" toml:"watch_file" xml:"watch_file" yaml:"watchFiles"`
 	Commands   []*commands.Command    `json:"commands" toml:"command" xml:"command" yaml:"commands"`
+	Notifiers  []*notifier.Config     `json:"notifiers" toml:"notifier" xml:"notifier" yaml:"notifiers"`
+	Queue      *website.QueueConfig   `json:"queue" toml:"queue" xml:"queue" yaml:"queue"`
+	Archive    *backups.ArchiveConfig `json:"backupArchive" toml:"backup_archive" xml:"backup_archive" yaml:"backupArchive"`
+	Seeding    *seeding.Config        `json:"seeding" toml:"seeding" xml:"seeding" yaml:"seeding"`
+	Throttle   *throttle.Config       `json:"throttle" toml:"throttle" xml:"throttle" yaml:"throttle"`
 	*logs.LogConfig
 	*apps.Apps
 	Allow AllowedIPs `json:"-" toml:"-" xml:"-" yaml:"-"`
@@ -81,10 +92,22 @@ func NewConfig(logger mnd.Logger) *Config {
 			Logger:   logger,
 		},
 		BindAddr: mnd.DefaultBindAddr,
+		Queue:    &website.QueueConfig{},
+		Archive:  &backups.ArchiveConfig{},
+		Seeding:  &seeding.Config{DryRun: true},
+		Throttle: &throttle.Config{},
 		Snapshot: &snapshot.Config{
 			Timeout: cnfg.Duration{Duration: snapshot.DefaultTimeout},
 			Plugins: &snapshot.Plugins{
 				Nvidia: &snapshot.NvidiaConfig{},
+				DriveAlerts: &snapshot.DriveAlerts{
+					MaxWear:  snapshot.DefaultDriveWear,
+					Cooldown: cnfg.Duration{Duration: snapshot.DefaultDriveCooldown},
+				},
+				Containers: &snapshot.ContainerConfig{},
+				Btrfs:      &snapshot.BtrfsConfig{},
+				LVM:        &snapshot.LVMConfig{},
+				SnapRAID:   &snapshot.SnapRAIDConfig{},
 			},
 		},
 		LogConfig: &logs.LogConfig{
@@ -144,6 +167,10 @@ func (c *Config) Get(flag *Flags, logger *logs.Logger) (*website.Server, *trigge
 
 	c.fixConfig()
 	logger.LogConfig = c.LogConfig // this is sorta hacky.
+	// State that must survive a restart is kept next to the config file, like config backups.
+	if flag.ConfigFile != "" {
+		data.SetStateDir(filepath.Join(filepath.Dir(flag.ConfigFile), "state"))
+	}
 
 	err := c.Services.Setup(c.Service)
 	if err != nil {
@@ -155,18 +182,25 @@ func (c *Config) Get(flag *Flags, logger *logs.Logger) (*website.Server, *trigge
 		return nil, nil, fmt.Errorf("setting up app: %w", err)
 	}
 
+	notifiers, err := notifier.New(c.Notifiers, c.Apps.Logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("local notifiers: %w", err)
+	}
+
 	// Make sure the port is not in use before starting the web server.
 	c.BindAddr, err = CheckPort(c.BindAddr)
 	// This function returns the notifiarr package Config struct too.
 	// This config contains [some of] the same data as the normal Config.
 	c.Services.Website = website.New(&website.Config{
-		Apps:     c.Apps,
-		Logger:   c.Apps.Logger,
-		BaseURL:  website.BaseURL,
-		Timeout:  c.Timeout,
-		Retries:  c.Retries,
-		HostID:   c.HostID,
-		BindAddr: c.BindAddr,
+		Apps:      c.Apps,
+		Logger:    c.Apps.Logger,
+		BaseURL:   website.BaseURL,
+		Timeout:   c.Timeout,
+		Retries:   c.Retries,
+		HostID:    c.HostID,
+		BindAddr:  c.BindAddr,
+		Notifiers: notifiers,
+		Queue:     c.Queue,
 	})
 
 	return c.Services.Website, c.setup(logger), err
@@ -213,6 +247,9 @@ func (c *Config) setup(logger *logs.Logger) *triggers.Actions {
 		WatchFiles: c.WatchFiles,
 		LogFiles:   c.LogConfig.GetActiveLogFilePaths(),
 		Commands:   c.Commands,
+		Archive:    c.Archive,
+		Seeding:    c.Seeding,
+		Throttle:   c.Throttle,
 		CIC:        cic,
 		Services:   c.Services,
 		Logger:     logger,
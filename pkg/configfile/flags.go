@@ -24,6 +24,7 @@ type Flags struct {
 	Fortune       bool     `json:"fortune"`
 	Write         string   `json:"write"`
 	Reset         bool     `json:"reset"`
+	CheckConfig   bool     `json:"checkConfig"`
 	Curl          string   `json:"curl"`
 	ConfigFile    string   `json:"configFile"`
 	ExtraConf     []string `json:"extraConf"`
@@ -48,6 +49,7 @@ func (f *Flags) ParseArgs(args []string) {
 	f.StringSliceVar(&f.Headers, "header", nil, "Use with --curl to add a request header.")
 	f.BoolVar(&f.PSlist, "ps", false, "Print the system process list; useful for 'process' service checks.")
 	f.BoolVar(&f.Reset, "reset", false, "Reset the admin password and write it to the config file.")
+	f.BoolVar(&f.CheckConfig, "check-config", false, "Validate the config file(s) and env variables, print a report and exit.")
 	f.StringVarP(&f.Write, "write", "w", "", "Write new config file to provided path. Use - to overwrite '--config' file.")
 	f.StringVarP(&f.Assets, "assets", "a", "", "Provide path to custom web assets: static files and templates")
 	f.BoolVar(&f.AptHook, "apthook", false, "Process a payload from a dpkg Pre-Install-Pkgs hook.")
@@ -84,13 +84,7 @@ func MakeIPs(upstreams []string) AllowedIPs {
 // CheckPort attempts to bind to a port to check if it's in use or not.
 // We use this to check the port before starting the webserver.
 func CheckPort(addr string) (string, error) {
-	// Cleanup user input.
-	addr = strings.TrimPrefix(strings.TrimPrefix(strings.TrimRight(addr, "/"), "http://"), "https://")
-	if addr == "" {
-		addr = mnd.DefaultBindAddr
-	} else if !strings.Contains(addr, ":") {
-		addr = "0.0.0.0:" + addr
-	}
+	addr = cleanBindAddr(addr)
 
 	a, err := net.ResolveTCPAddr("tcp", addr)
 	if err != nil {
@@ -106,6 +100,18 @@ func CheckPort(addr string) (string, error) {
 	return addr, nil
 }
 
+// cleanBindAddr cleans up user input for the bind address.
+func cleanBindAddr(addr string) string {
+	addr = strings.TrimPrefix(strings.TrimPrefix(strings.TrimRight(addr, "/"), "http://"), "https://")
+	if addr == "" {
+		return mnd.DefaultBindAddr
+	} else if !strings.Contains(addr, ":") {
+		return "0.0.0.0:" + addr
+	}
+
+	return addr
+}
+
 // BackupFile makes a config file backup file.
 func BackupFile(configFile string) error {
 	date := time.Now().Format("20060102T150405") // for file names.
@@ -144,6 +144,11 @@ timeout = "{{.Timeout}}"
 apt = {{.EnableApt}}
 {{- end}}
 
+## Set metrics to true to enable a Prometheus /metrics endpoint at your urlbase.
+## Requests must include your api_key in an X-API-Key header or an apikey query parameter.
+##
+metrics = {{.Metrics}}
+
 ## Setting serial to true makes the app use fewer threads when polling apps.
 ## This spreads CPU usage out and uses a bit less memory.
 serial = {{.Serial}}
@@ -153,6 +158,40 @@ serial = {{.Serial}}
 ## Setting this to 0 will take the default of 4. Use 1 to disable retrying.
 retries = {{.Retries}}
 
+## Requests to notifiarr.com that fail because the website is unreachable can be queued on disk
+## and replayed once the website is back. Set a directory to enable the queue. size is the maximum
+## number of queued requests; the oldest are dropped first. Requests expire per route, see ttl.
+## Routes not listed in ttl use defaults: 1h for services, stuck and plex; dashboard, snapshot,
+## downloads and test data is never queued; every other notification is kept for 24h.
+##
+[queue]
+{{- if .Queue.Dir}}
+  dir  = '''{{.Queue.Dir}}'''
+{{- else}}
+  #dir = '/config/queue'
+{{- end}}
+  size = {{.Queue.Size}} # 0 = 1000{{if .Queue.TTL}}
+  [queue.ttl]{{range $route, $ttl := .Queue.TTL}}
+    {{$route}} = "{{$ttl}}"{{end}}{{else}}
+  #[queue.ttl]
+  #  logWatcher = "48h"
+  #  services   = "0s" # 0 disables queueing for a route.{{end}}
+
+## Starr backups downloaded by the corruption checker can be archived locally. Set a directory to enable
+## the archive. Only backups that pass the sqlite integrity check are kept. Backups are saved in
+## <dir>/<app>/<instance>/ and any backup selected by one of the keep rules is retained; the rest are deleted.
+## With no keep rules, the last 7 backups for each instance are kept.
+##
+[backup_archive]
+{{- if .Archive.Dir}}
+  dir         = '''{{.Archive.Dir}}'''
+{{- else}}
+  #dir        = '/backups/starr'
+{{- end}}
+  keep_last   = {{.Archive.KeepLast}}
+  keep_daily  = {{.Archive.KeepDaily}}
+  keep_weekly = {{.Archive.KeepWeekly}}
+
 ##################
 # Starr Settings #
 ##################
@@ -179,6 +218,19 @@ retries = {{.Retries}}
   {{- if .ValidSSL}}
   valid_ssl = true
   {{- end}}
+  {{- range .QueueRules}}
+
+  [[lidarr.queue_rule]]
+    name      = '''{{.Name}}'''
+    status    = [{{range $s := .Status}}"{{$s}}",{{end}}]
+    message   = '''{{.Message}}'''
+    protocol  = "{{.Protocol}}"
+    age       = "{{.Age}}"
+    remove    = {{.Remove}}
+    blocklist = {{.Blocklist}}
+    search    = {{.Search}}
+    dry_run   = {{.DryRun}}
+  {{- end}}
 
 {{end}}
 {{else}}#[[lidarr]]
@@ -222,6 +274,19 @@ retries = {{.Retries}}
   {{- if .ValidSSL}}
   valid_ssl = true
   {{- end}}
+  {{- range .QueueRules}}
+
+  [[radarr.queue_rule]]
+    name      = '''{{.Name}}'''
+    status    = [{{range $s := .Status}}"{{$s}}",{{end}}]
+    message   = '''{{.Message}}'''
+    protocol  = "{{.Protocol}}"
+    age       = "{{.Age}}"
+    remove    = {{.Remove}}
+    blocklist = {{.Blocklist}}
+    search    = {{.Search}}
+    dry_run   = {{.DryRun}}
+  {{- end}}
 
 {{end}}
 {{else}}#[[radarr]]
@@ -244,6 +309,19 @@ retries = {{.Retries}}
   {{- if .ValidSSL}}
   valid_ssl = true
   {{- end}}
+  {{- range .QueueRules}}
+
+  [[readarr.queue_rule]]
+    name      = '''{{.Name}}'''
+    status    = [{{range $s := .Status}}"{{$s}}",{{end}}]
+    message   = '''{{.Message}}'''
+    protocol  = "{{.Protocol}}"
+    age       = "{{.Age}}"
+    remove    = {{.Remove}}
+    blocklist = {{.Blocklist}}
+    search    = {{.Search}}
+    dry_run   = {{.DryRun}}
+  {{- end}}
 
 {{end}}
 {{else}}#[[readarr]]
@@ -266,12 +344,52 @@ retries = {{.Retries}}
   {{- if .ValidSSL}}
   valid_ssl = true
   {{- end}}
+  {{- range .QueueRules}}
+
+  [[sonarr.queue_rule]]
+    name      = '''{{.Name}}'''
+    status    = [{{range $s := .Status}}"{{$s}}",{{end}}]
+    message   = '''{{.Message}}'''
+    protocol  = "{{.Protocol}}"
+    age       = "{{.Age}}"
+    remove    = {{.Remove}}
+    blocklist = {{.Blocklist}}
+    search    = {{.Search}}
+    dry_run   = {{.DryRun}}
+  {{- end}}
 
 {{end}}
 {{else}}#[[sonarr]]
 #name      = ""  # Set a name to enable checks of your service.
 #url       = "http://sonarr:8989/"
 #api_key   = ""
+#deletes   = 10 # Queue rules need deletes; this is the most queue items removed per hour.
+#
+### Queue rules remove stuck items from the queue. They work in every Starr app except Prowlarr.
+### An item matches when every condition provided matches: status, message (text in the error) and protocol.
+### The first matching rule acts once the item has matched for age. Set dry_run to only log what would happen.
+### Every action is logged with a "Queue Remediation" prefix.
+#  [[sonarr.queue_rule]]
+#    name      = "failed downloads"
+#    status    = ["failed"]
+#    age       = "2h"
+#    remove    = true # remove from the download client.
+#    blocklist = true
+#    search    = true # search for a replacement; requires blocklist.
+#    dry_run   = true
+#  [[sonarr.queue_rule]]
+#    name      = "not an upgrade"
+#    status    = ["importBlocked"]
+#    message   = "not an upgrade"
+#    remove    = true
+#  [[sonarr.queue_rule]]
+#    name      = "stalled torrents"
+#    status    = ["warning"]
+#    message   = "stalled"
+#    protocol  = "torrent"
+#    age       = "6h"
+#    remove    = true
+#    blocklist = true
 
 
 {{end -}}
@@ -390,6 +508,73 @@ retries = {{.Retries}}
 
 {{end -}}
 
+## Seeding goals pause or remove finished torrents in Qbit, Deluge and Transmission. The first rule that
+## matches a torrent's category (or label) and tracker sets its goal; every min_ set on the rule must be met.
+## action is pause, remove (keeps the data) or delete (also deletes the data). Torrents on a tracker are
+## protected until they also meet its hit-and-run requirement, and torrents in a Starr queue are never
+## touched. dry_run only logs.
+##
+[seeding]
+  interval = "{{.Seeding.Interval}}" # 0s = 30m
+  dry_run  = {{.Seeding.DryRun}}
+{{- range .Seeding.Rules}}
+
+  [[seeding.rule]]
+    name          = '''{{.Name}}'''
+    category      = [{{range $s := .Category}}"{{$s}}",{{end}}]
+    tracker       = [{{range $s := .Tracker}}"{{$s}}",{{end}}]
+    min_ratio     = {{.MinRatio}}
+    min_seed_time = "{{.MinSeedTime}}"
+    action        = "{{.Action}}"
+{{- else}}
+
+  #[[seeding.rule]]
+  #  name          = "tv"
+  #  category      = ["tv-sonarr"]
+  #  min_ratio     = 1.0
+  #  min_seed_time = "168h"
+  #  action        = "pause"
+{{- end}}
+{{- range .Seeding.Trackers}}
+
+  [[seeding.tracker]]
+    host          = '''{{.Host}}'''
+    min_ratio     = {{.MinRatio}}
+    min_seed_time = "{{.MinSeedTime}}"
+{{- else}}
+
+  #[[seeding.tracker]]
+  #  host          = "tracker.example.org"
+  #  min_ratio     = 1.0
+  #  min_seed_time = "240h"
+{{- end}}
+
+## Download throttling lowers download client speed limits while remote Plex streams use a lot of bandwidth.
+## Limits are lowered when remote streaming reaches start_mbps, and restored after it stays at or below stop_mbps
+## for restore_delay. Client limits are KiB/s; 0 leaves that limit alone. instance 0 is every instance of the app.
+## app is qbit, deluge, transmission, rtorrent, sabnzbd or nzbget. Requires Plex. start_mbps 0 disables this.
+##
+[throttle]
+  interval      = "{{.Throttle.Interval}}" # 0s = 1m
+  start_mbps    = {{.Throttle.StartMbps}}
+  stop_mbps     = {{.Throttle.StopMbps}}
+  restore_delay = "{{.Throttle.RestoreDelay}}" # 0s = 5m
+{{- range .Throttle.Clients}}
+
+  [[throttle.client]]
+    app      = "{{.App}}"
+    instance = {{.Instance}}
+    download = {{.Download}}
+    upload   = {{.Upload}}
+{{- else}}
+
+  #[[throttle.client]]
+  #  app      = "qbit"
+  #  instance = 0
+  #  download = 0
+  #  upload   = 1024
+{{- end}}
+
 #################
 # Plex Settings #
 #################
@@ -404,9 +589,13 @@ retries = {{.Retries}}
   {{- if .Plex.ValidSSL}}
   valid_ssl = true
   {{- end}}
+  {{- if .Plex.BackupPath}}
+  backup_path = '''{{.Plex.BackupPath}}''' # Plex Databases folder; enables backup corruption checks.
+  {{- end}}
 {{- else}}#[plex]
 #url     = "http://localhost:32400/" # Your plex URL
 #token   = "" # your plex token; get this from a web inspector
+#backup_path = "/var/lib/plexmediaserver/Library/Application Support/Plex Media Server/Plug-in Support/Databases"
 {{- end }}
 
 #####################
@@ -425,11 +614,15 @@ retries = {{.Retries}}
   {{- if .Tautulli.ValidSSL}}
   valid_ssl = true
   {{- end}}
+  {{- if .Tautulli.BackupPath}}
+  backup_path = '''{{.Tautulli.BackupPath}}''' # Tautulli backups folder; enables backup corruption checks.
+  {{- end}}
 {{- else}}
 #[tautulli]
 #  name    = "" # only set a name to enable service checks.
 #  url     = "http://localhost:8181/" # Your Tautulli URL
 #  api_key = "" # your tautulli api key; get this from settings
+#  backup_path = "/config/backups" # Tautulli backups folder; enables backup corruption checks.
 {{- end }}
 
 ##################
@@ -470,6 +663,87 @@ disabled = {{.Snapshot.Nvidia.Disabled}}
 smi_path = '''{{.Snapshot.Nvidia.SMIPath}}'''
 bus_ids  = [{{range $s := .Snapshot.Nvidia.BusIDs}}"{{$s}}",{{end}}]
 
+##################
+# Drive Warnings #
+##################
+
+# Snapshots with drive data (monitor_drives) compare SMART data to the previous snapshots.
+# Growing reallocated sectors, pending sectors and NVMe media errors always send a warning.
+# max_temp is in celsius and max_wear is the NVMe percentage used; 0 disables either one.
+# The same warning for a drive is not repeated until the cooldown passes.
+# Add [[snapshot.drive_alerts.drive]] to set a temperature limit for one drive, by device name or serial number.
+{{if .Snapshot.DriveAlerts}}
+[snapshot.drive_alerts]
+disabled = {{.Snapshot.DriveAlerts.Disabled}}
+max_temp = {{.Snapshot.DriveAlerts.MaxTemp}}
+max_wear = {{.Snapshot.DriveAlerts.MaxWear}}
+cooldown = "{{.Snapshot.DriveAlerts.Cooldown}}"
+{{- range .Snapshot.DriveAlerts.Drives}}{{if .}}
+
+[[snapshot.drive_alerts.drive]]
+  name     = '''{{.Name}}'''
+  max_temp = {{.MaxTemp}}
+{{- end}}{{end}}
+{{- else}}
+#[snapshot.drive_alerts]
+#disabled = false
+#max_temp = 0
+#max_wear = 90
+#cooldown = "24h"
+{{- end}}
+#[[snapshot.drive_alerts.drive]]
+#  name     = "/dev/sda"
+#  max_temp = 50
+
+######################
+# Container Snapshot #
+######################
+
+# The app will automatically collect Docker or Podman container data if the API socket is found and readable.
+# Container state, restart count, health check status, cpu and memory are collected for every container.
+# Socket is found automatically if left blank. Set it to use another socket, ex: "/run/user/1000/podman/podman.sock"
+{{if .Snapshot.Containers}}
+[snapshot.containers]
+disabled = {{.Snapshot.Containers.Disabled}}
+socket   = '''{{.Snapshot.Containers.Socket}}'''
+{{- else}}
+#[snapshot.containers]
+#disabled = false
+#socket   = ""
+{{- end}}
+
+###########################
+# Btrfs, LVM and SnapRAID #
+###########################
+
+# These are off by default. btrfs adds device error counters and the last scrub of every mounted btrfs filesystem.
+# lvm adds volume group and thin pool usage. Both are Linux only, and use sudo if use_sudo is set on the website.
+# snapraid adds the last sync, scrub and error counts from 'snapraid status'. Set diff to also run 'snapraid diff';
+# it reads every data disk, so it spins them up on every snapshot.
+{{if .Snapshot.Btrfs}}
+[snapshot.btrfs]
+enabled = {{.Snapshot.Btrfs.Enabled}}
+{{- else}}
+#[snapshot.btrfs]
+#enabled = false
+{{- end}}
+{{if .Snapshot.LVM}}
+[snapshot.lvm]
+enabled = {{.Snapshot.LVM.Enabled}}
+{{- else}}
+#[snapshot.lvm]
+#enabled = false
+{{- end}}
+{{if .Snapshot.SnapRAID}}
+[snapshot.snapraid]
+enabled = {{.Snapshot.SnapRAID.Enabled}}
+diff    = {{.Snapshot.SnapRAID.Diff}}
+{{- else}}
+#[snapshot.snapraid]
+#enabled = false
+#diff    = false
+{{- end}}
+
 ##################
 # Service Checks #
 ##################
@@ -485,6 +759,13 @@ bus_ids  = [{{range $s := .Snapshot.Nvidia.BusIDs}}"{{$s}}",{{end}}]
   parallel = {{.Services.Parallel}}     # How many services to check concurrently. 1 should be enough.
   interval = "{{.Services.Interval}}" # How often to send service states to Notifiarr.com. Minimum = 5m.
   log_file = '{{.Services.LogFile}}'    # Service Check logs go to the app log by default. Change that by setting a services.log file here.
+{{- if .Services.HistoryFile}}
+  history_file = '{{.Services.HistoryFile}}' # Service check results are stored here for the uptime api.
+  history_size = {{.Services.HistorySize}} # Results kept per service; 0 = 30 days at each service's interval, at most 8641. Results older than 30 days are always dropped.
+{{- else}}
+  #history_file = '/config/service-history.json' # Set this to store check results locally for the uptime api.
+  #history_size = 0 # Results kept per service; 0 = 30 days at each service's interval, at most 8641. Results older than 30 days are always dropped.
+{{- end}}
 
 ## Uncomment the following section to create a service check on a URL or IP:port.
 ## You may include as many [[service]] sections as you have services to check.
@@ -493,11 +774,28 @@ bus_ids  = [{{range $s := .Snapshot.Nvidia.BusIDs}}"{{$s}}",{{end}}]
 ## Example with comments follows.
 #[[service]]
 #  name     = "MyServer"          # name must be unique
-#  type     = "http"              # type can be "http" or "tcp"
-#  check    = 'http://127.0.0.1/'  # url for 'http', host/IP:port for 'tcp'
-#  expect   = "200"               # return code to expect (for http only)
+#  type     = "http"              # type can be "http", "tcp", "process", "ping", "icmp", "dns", "zfs" or "container"
+#  check    = 'http://127.0.0.1/'  # url for 'http', host/IP:port for 'tcp', name|resolver:port for 'dns', pool name for 'zfs', container name for 'container'
+#  expect   = "200"               # return code to expect for http, record type and answers for dns, ex: "A:10.1.1.2"
 #  timeout  = "10s"               # how long to wait for tcp or http checks.
 #  interval = "5m"                # how often to check this service.
+#  cert_check = false            # inspect tls certificates on https and tcp checks.
+#  starttls   = ""                # tcp only: negotiate tls with "smtp", "imap", "pop3" or "ftp" first.
+#  cert_warn  = "336h"            # warning when a certificate expires within this duration.
+#  cert_crit  = "72h"             # critical when a certificate expires within this duration.
+#  method       = "GET"                  # http only: request method.
+#  body         = ''                     # http only: request body.
+#  headers      = ['X-Api-Key: secret']  # http only: request headers.
+#  body_regex   = 'healthy'              # http only: response body must match this regular expression.
+#  json_path    = 'data.status'          # http only: path to a value in a json response body.
+#  json_value   = 'ok'                   # http only: expected value at json_path; any value if empty.
+#  resp_headers = ['Content-Type: application/json'] # http only: required response headers, value optional.
+#  max_latency  = "2s"                   # http only: warning when the response takes longer than this.
+#  depends_on   = ['NAS']                # names of services this service needs; alerts are suppressed while they are down.
+#  fail_count   = 1                      # failed checks in a row before the service changes to a failed state.
+#  pass_count   = 1                      # passed checks in a row before a failed service returns to OK.
+#  flap_changes = 0                      # state changes within flap_window that mark the service as flapping. 0 disables.
+#  flap_window  = "1h"                   # time window used to count state changes for flap detection.
 {{if not .Service}}
 ## Another example. Remember to uncomment [[service]] if you use this!
 ##
@@ -515,7 +813,24 @@ bus_ids  = [{{range $s := .Snapshot.Nvidia.BusIDs}}"{{$s}}",{{end}}]
   check    = '''{{.Value}}'''
   expect   = '''{{.Expect}}'''
   timeout  = "{{.Timeout}}"
-  interval = "{{.Interval}}"
+  interval = "{{.Interval}}"{{if .CertCheck}}
+  cert_check = true
+  starttls   = "{{.StartTLS}}"
+  cert_warn  = "{{.CertWarn}}"
+  cert_crit  = "{{.CertCrit}}"{{end}}{{if .Method}}
+  method   = "{{.Method}}"{{end}}{{if .Body}}
+  body     = '''{{toml .Body}}'''{{end}}{{if .Headers}}
+  headers  = [{{range $s := .Headers}}'''{{$s}}''',{{end}}]{{end}}{{if .BodyRegex}}
+  body_regex = '''{{.BodyRegex}}'''{{end}}{{if .JSONPath}}
+  json_path  = '''{{.JSONPath}}'''
+  json_value = '''{{.JSONValue}}'''{{end}}{{if .RespHeaders}}
+  resp_headers = [{{range $s := .RespHeaders}}'''{{$s}}''',{{end}}]{{end}}{{if .MaxLatency.Duration}}
+  max_latency  = "{{.MaxLatency}}"{{end}}{{if .DependsOn}}
+  depends_on   = [{{range $s := .DependsOn}}'''{{$s}}''',{{end}}]{{end}}{{if .FailCount}}
+  fail_count   = {{.FailCount}}{{end}}{{if .PassCount}}
+  pass_count   = {{.PassCount}}{{end}}{{if .FlapChanges}}
+  flap_changes = {{.FlapChanges}}
+  flap_window  = "{{.FlapWindow}}"{{end}}
 {{end}}{{end}}
 
 
@@ -581,4 +896,52 @@ bus_ids  = [{{range $s := .Snapshot.Nvidia.BusIDs}}"{{$s}}",{{end}}]
   notify  = {{$item.Notify}}
   timeout = "{{$item.Timeout}}"{{end}}
 {{end}}{{end}}
+
+#######################
+# Local Notifications #
+#######################
+
+## Send events to local notification sinks as well as (or instead of) notifiarr.com.
+## This lets the client alert you when the website is unreachable.
+## type can be "webhook", "discord", "gotify", "ntfy" or "smtp".
+## mode can be "always" (default; also send to notifiarr.com), "fallback" (only when sending
+## to notifiarr.com fails) or "instead" (do not send these routes to notifiarr.com).
+## routes are website routes: "services" (state changes), "logWatcher" (file matches), "command" (output).
+## Leave routes empty for all three. Gotify uses the server url and an app token; ntfy uses the topic url.
+##
+## Example (remove the leading # hashes to use it):
+
+#[[notifier]]
+#  name    = 'discord-alerts'
+#  type    = 'discord'
+#  url     = 'https://discord.com/api/webhooks/...'
+#  mode    = 'fallback'
+#  routes  = [ 'services', 'logWatcher' ]
+#  timeout = "10s"
+#
+## SMTP example. Use smtps:// for implicit TLS; smtp:// upgrades with STARTTLS when offered.
+#[[notifier]]
+#  type     = 'smtp'
+#  url      = 'smtp://mail.example.com:587'
+#  from     = 'notifiarr@example.com'
+#  to       = [ 'me@example.com' ]
+#  username = 'notifiarr@example.com'
+#  password = 'secret'
+{{if .Notifiers}}
+## Configured Notifiers:
+{{- range $item := .Notifiers}}{{if $item}}
+
+[[notifier]]
+  name    = '''{{$item.Name}}'''
+  type    = '{{$item.Type}}'
+  url     = '''{{$item.URL}}'''{{if $item.Token}}
+  token   = '''{{$item.Token}}'''{{end}}{{if $item.Mode}}
+  mode    = '{{$item.Mode}}'{{end}}{{if $item.Routes}}
+  routes  = [{{range $s := $item.Routes}}'{{$s}}',{{end}}]{{end}}{{if $item.Timeout.Duration}}
+  timeout = "{{$item.Timeout}}"{{end}}{{if $item.From}}
+  from    = '''{{$item.From}}'''{{end}}{{if $item.To}}
+  to      = [{{range $s := $item.To}}'''{{$s}}''',{{end}}]{{end}}{{if $item.Username}}
+  username = '''{{$item.Username}}'''{{end}}{{if $item.Password}}
+  password = '''{{$item.Password}}'''{{end}}{{end}}
+{{end}}{{end}}
 `
@@ -0,0 +1,270 @@
+// Package notifier delivers client events to local notification sinks.
+// These sinks work without notifiarr.com, so the client can still alert when the website is unreachable.
+// Supported sinks are generic webhooks, Discord webhooks, Gotify, ntfy and SMTP email.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"golift.io/cnfg"
+)
+
+// Sink types.
+const (
+	TypeWebhook = "webhook"
+	TypeDiscord = "discord"
+	TypeGotify  = "gotify"
+	TypeNtfy    = "ntfy"
+	TypeSMTP    = "smtp"
+)
+
+// Delivery modes.
+const (
+	// ModeAlways sends to this sink in addition to notifiarr.com.
+	ModeAlways = "always"
+	// ModeFallback sends to this sink only when sending to notifiarr.com fails.
+	ModeFallback = "fallback"
+	// ModeInstead sends to this sink and does not send the same routes to notifiarr.com.
+	ModeInstead = "instead"
+)
+
+// DefaultTimeout is used when a sink has no timeout configured.
+const DefaultTimeout = 10 * time.Second
+
+// DefaultRoutes are used when a sink has no routes configured.
+// These are the website routes that carry alert-worthy events.
+var DefaultRoutes = []string{"services", "logWatcher", "command"} //nolint:gochecknoglobals
+
+// Errors returned by this package.
+var (
+	ErrNoURL   = fmt.Errorf("notifier url is required")
+	ErrBadType = fmt.Errorf("notifier type must be one of %s, %s, %s, %s, %s",
+		TypeWebhook, TypeDiscord, TypeGotify, TypeNtfy, TypeSMTP)
+	ErrBadMode = fmt.Errorf("notifier mode must be one of %s, %s, %s", ModeAlways, ModeFallback, ModeInstead)
+	ErrNoTo    = fmt.Errorf("smtp notifiers require at least one to address")
+	ErrNon200  = fmt.Errorf("notifier returned a non-200 status")
+)
+
+// Level is the severity of a notice. Sinks use this for colors and priorities.
+type Level int
+
+// Notice levels.
+const (
+	LevelInfo Level = iota
+	LevelWarning
+	LevelCritical
+)
+
+// String turns a level into a human string.
+func (l Level) String() string {
+	switch l {
+	case LevelCritical:
+		return "critical"
+	case LevelWarning:
+		return "warning"
+	case LevelInfo:
+		fallthrough
+	default:
+		return "info"
+	}
+}
+
+// Notice is a human readable event delivered to notification sinks.
+type Notice struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Level   Level  `json:"level"`
+	// These are filled in by the notifier.
+	Route   string    `json:"route"`
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	Payload any       `json:"payload,omitempty"` // only sent to generic webhooks.
+}
+
+// Noticer is implemented by website payloads that can describe themselves to notification sinks.
+// Returning nil skips local notifications for that payload.
+type Noticer interface {
+	Notice() *Notice
+}
+
+// Config is one [[notifier]] section in the config file.
+type Config struct {
+	Name     string        `toml:"name" xml:"name" json:"name"`
+	Type     string        `toml:"type" xml:"type" json:"type"`
+	URL      string        `toml:"url" xml:"url" json:"url"`
+	Token    string        `toml:"token" xml:"token" json:"token"` // gotify app token, or bearer token for ntfy and webhooks.
+	Mode     string        `toml:"mode" xml:"mode" json:"mode"`
+	Routes   []string      `toml:"routes" xml:"routes" json:"routes"`
+	Timeout  cnfg.Duration `toml:"timeout" xml:"timeout" json:"timeout"`
+	From     string        `toml:"from" xml:"from" json:"from"` // smtp only.
+	To       []string      `toml:"to" xml:"to" json:"to"`       // smtp only.
+	Username string        `toml:"username" xml:"username" json:"username"`
+	Password string        `toml:"password" xml:"password" json:"password"`
+	routes   map[string]bool
+}
+
+// Notifiers holds all the configured sinks.
+type Notifiers struct {
+	sinks  []*Config
+	client *http.Client
+	mnd.Logger
+}
+
+// New validates the notifier configs and returns a ready-to-use set of sinks.
+// A nil Notifiers is valid and sends nothing.
+func New(configs []*Config, logger mnd.Logger) (*Notifiers, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	for idx, sink := range configs {
+		if err := sink.Validate(); err != nil {
+			return nil, fmt.Errorf("notifier %d (%s): %w", idx+1, sink.Name, err)
+		}
+	}
+
+	return &Notifiers{sinks: configs, client: &http.Client{}, Logger: logger}, nil
+}
+
+// Validate checks a notifier config and fills in defaults. New calls this for every sink.
+func (c *Config) Validate() error {
+	switch c.Type = strings.ToLower(c.Type); c.Type {
+	case TypeWebhook, TypeDiscord, TypeGotify, TypeNtfy, TypeSMTP:
+	default:
+		return fmt.Errorf("%w, not '%s'", ErrBadType, c.Type)
+	}
+
+	switch c.Mode = strings.ToLower(c.Mode); c.Mode {
+	case "":
+		c.Mode = ModeAlways
+	case ModeAlways, ModeFallback, ModeInstead:
+	default:
+		return fmt.Errorf("%w, not '%s'", ErrBadMode, c.Mode)
+	}
+
+	if c.URL == "" {
+		return ErrNoURL
+	} else if c.Type == TypeSMTP && len(c.To) == 0 {
+		return ErrNoTo
+	}
+
+	if c.Name == "" {
+		c.Name = c.Type
+	}
+
+	if c.Timeout.Duration == 0 {
+		c.Timeout.Duration = DefaultTimeout
+	}
+
+	routes := c.Routes
+	if len(routes) == 0 {
+		routes = DefaultRoutes
+	}
+
+	c.routes = make(map[string]bool)
+	for _, route := range routes {
+		c.routes[strings.ToLower(route)] = true
+	}
+
+	return nil
+}
+
+// Instead returns true if any sink replaces notifiarr.com for this route.
+func (n *Notifiers) Instead(route string) bool {
+	if n == nil {
+		return false
+	}
+
+	for _, sink := range n.sinks {
+		if sink.Mode == ModeInstead && sink.routes[strings.ToLower(route)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Send delivers a website payload to every sink configured for the route.
+// failed must be true if sending the same payload to notifiarr.com failed.
+// Payloads that do not implement Noticer are described with logMsg; if that is empty, nothing is sent.
+// Sinks run in the background, so this never blocks the website queue.
+func (n *Notifiers) Send(route, event string, payload any, logMsg string, failed bool) {
+	if n == nil {
+		return
+	}
+
+	var notice *Notice
+
+	for _, sink := range n.sinks {
+		if !sink.routes[strings.ToLower(route)] || (sink.Mode == ModeFallback && !failed) {
+			continue
+		}
+
+		if notice == nil {
+			if notice = makeNotice(route, event, payload, logMsg); notice == nil {
+				return
+			}
+		}
+
+		go n.send(sink, notice)
+	}
+}
+
+func makeNotice(route, event string, payload any, logMsg string) *Notice {
+	var notice *Notice
+
+	if noticer, ok := payload.(Noticer); ok {
+		if notice = noticer.Notice(); notice == nil {
+			return nil
+		}
+	} else if logMsg != "" {
+		notice = &Notice{Title: route + " (" + event + ")", Message: logMsg}
+	} else {
+		return nil
+	}
+
+	notice.Route = route
+	notice.Event = event
+	notice.Time = time.Now().Round(time.Second)
+	notice.Payload = payload
+
+	return notice
+}
+
+func (n *Notifiers) send(sink *Config, notice *Notice) {
+	defer n.CapturePanic()
+
+	ctx, cancel := context.WithTimeout(context.Background(), sink.Timeout.Duration)
+	defer cancel()
+
+	var err error
+
+	switch sink.Type {
+	case TypeWebhook:
+		err = n.sendWebhook(ctx, sink, notice)
+	case TypeDiscord:
+		err = n.sendDiscord(ctx, sink, notice)
+	case TypeGotify:
+		err = n.sendGotify(ctx, sink, notice)
+	case TypeNtfy:
+		err = n.sendNtfy(ctx, sink, notice)
+	case TypeSMTP:
+		err = sendSMTP(ctx, sink, notice)
+	}
+
+	mnd.Website.Add("Notifier "+sink.Type+mnd.Requests, 1)
+
+	if err != nil {
+		mnd.Website.Add("Notifier "+sink.Type+" Errors", 1)
+		n.ErrorfNoShare("Sending %s notice to notifier '%s' (%s): %v", notice.Route, sink.Name, sink.Type, err)
+
+		return
+	}
+
+	n.Debugf("Sent %s notice to notifier '%s' (%s): %s", notice.Route, sink.Name, sink.Type, notice.Title)
+}
@@ -0,0 +1,238 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+// Discord embed colors for each level.
+const (
+	colorInfo     = 0x2ecc71
+	colorWarning  = 0xf1c40f
+	colorCritical = 0xe74c3c
+)
+
+// maxDiscord is the longest description Discord accepts in an embed.
+const maxDiscord = 4000
+
+// sendHTTP sends a request to a sink and checks the response code.
+func (n *Notifiers) sendHTTP(ctx context.Context, method, uri string, body io.Reader, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", mnd.Title)
+
+	for key, val := range headers {
+		if val != "" {
+			req.Header.Set(key, val)
+		}
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode > http.StatusIMUsed {
+		reply, _ := io.ReadAll(io.LimitReader(resp.Body, mnd.Kilobyte))
+		return fmt.Errorf("%w: %s: %s", ErrNon200, resp.Status, strings.TrimSpace(string(reply)))
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+func (n *Notifiers) sendJSON(ctx context.Context, uri string, payload any, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+
+	headers["Content-Type"] = "application/json"
+
+	return n.sendHTTP(ctx, http.MethodPost, uri, bytes.NewReader(body), headers)
+}
+
+func bearer(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	return "Bearer " + token
+}
+
+// sendWebhook posts the whole notice, including the original payload, as json.
+func (n *Notifiers) sendWebhook(ctx context.Context, sink *Config, notice *Notice) error {
+	return n.sendJSON(ctx, sink.URL, notice, map[string]string{"Authorization": bearer(sink.Token)})
+}
+
+// sendDiscord posts the notice as an embed to a Discord webhook url.
+func (n *Notifiers) sendDiscord(ctx context.Context, sink *Config, notice *Notice) error {
+	color := colorInfo
+
+	switch notice.Level {
+	case LevelCritical:
+		color = colorCritical
+	case LevelWarning:
+		color = colorWarning
+	case LevelInfo:
+	}
+
+	message := notice.Message
+	if len(message) > maxDiscord {
+		cut := maxDiscord
+		for cut > 0 && !utf8.RuneStart(message[cut]) { // do not split a multi-byte character.
+			cut--
+		}
+
+		message = message[:cut] + "..."
+	}
+
+	return n.sendJSON(ctx, sink.URL, map[string]any{
+		"username": mnd.Title,
+		"embeds": []map[string]any{{
+			"title":       notice.Title,
+			"description": message,
+			"color":       color,
+			"timestamp":   notice.Time.Format(time.RFC3339),
+			"footer":      map[string]string{"text": notice.Route + " / " + notice.Event},
+		}},
+	}, map[string]string{})
+}
+
+// sendGotify posts the notice to a Gotify server. The url is the server's base url.
+func (n *Notifiers) sendGotify(ctx context.Context, sink *Config, notice *Notice) error {
+	priority := 2 //nolint:gomnd // gotify priorities are 0-10.
+
+	switch notice.Level {
+	case LevelCritical:
+		priority = 8
+	case LevelWarning:
+		priority = 5
+	case LevelInfo:
+	}
+
+	return n.sendJSON(ctx, strings.TrimSuffix(sink.URL, "/")+"/message", map[string]any{
+		"title":    notice.Title,
+		"message":  notice.Message,
+		"priority": priority,
+	}, map[string]string{"X-Gotify-Key": sink.Token})
+}
+
+// sendNtfy posts the notice to an ntfy topic. The url includes the topic.
+func (n *Notifiers) sendNtfy(ctx context.Context, sink *Config, notice *Notice) error {
+	priority := "default"
+
+	switch notice.Level {
+	case LevelCritical:
+		priority = "urgent"
+	case LevelWarning:
+		priority = "high"
+	case LevelInfo:
+	}
+
+	return n.sendHTTP(ctx, http.MethodPost, sink.URL, strings.NewReader(notice.Message), map[string]string{
+		"Title":         notice.Title,
+		"Priority":      priority,
+		"Tags":          notice.Level.String() + "," + notice.Route,
+		"Authorization": bearer(sink.Token),
+	})
+}
+
+// sendSMTP emails the notice. The url looks like smtp://mail.host:587 or smtps://mail.host:465.
+// Plain smtp connections are upgraded with STARTTLS when the server supports it.
+func sendSMTP(ctx context.Context, sink *Config, notice *Notice) error {
+	uri, err := url.Parse(sink.URL)
+	if err != nil {
+		return fmt.Errorf("parsing smtp url: %w", err)
+	}
+
+	host := uri.Hostname()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", uri.Host)
+	if err != nil {
+		return fmt.Errorf("connecting to smtp server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if uri.Scheme == "smtps" {
+		conn = tls.Client(conn, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("smtp greeting: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && uri.Scheme != "smtps" {
+		if err := client.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if sink.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", sink.Username, sink.Password, host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	return smtpSend(client, sink, notice)
+}
+
+func smtpSend(client *smtp.Client, sink *Config, notice *Notice) error {
+	from := sink.From
+	if from == "" {
+		from = sink.Username
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("smtp from: %w", err)
+	}
+
+	for _, addr := range sink.To {
+		if err := client.Rcpt(addr); err != nil {
+			return fmt.Errorf("smtp to %s: %w", addr, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+
+	// Newlines in the title would end the Subject header and start new ones.
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace("[" + mnd.Title + "] " + notice.Title)
+
+	fmt.Fprintf(writer, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		from, strings.Join(sink.To, ", "), mime.QEncoding.Encode("utf-8", subject), notice.Time.Format(time.RFC1123Z),
+		strings.ReplaceAll(notice.Message, "\n", "\r\n"))
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("smtp sending message: %w", err)
+	}
+
+	return client.Quit() //nolint:wrapcheck
+}
@@ -1,7 +1,6 @@
 package services
 
 import (
-	"net/url"
 	"strings"
 
 	"golift.io/cnfg"
@@ -166,164 +165,29 @@ func (c *Config) collectSonarrApps(svcs []*Service) []*Service {
 	return svcs
 }
 
-//nolint:funlen,cyclop,gocognit,gocyclo // split this one up.
+// collectDownloadApps turns every named download client into a service check.
+// These are reported as http checks, but ask the client for its status instead of making a plain request.
 func (c *Config) collectDownloadApps(svcs []*Service) []*Service {
-	// Deluge instanceapp.
-	for _, app := range c.Apps.Deluge {
-		if !app.Enabled() || app.Name == "" || app.Interval.Duration < 0 {
-			continue
-		}
-
-		interval := app.Interval
-		if interval.Duration == 0 {
-			interval.Duration = DefaultCheckInterval
-		}
-
-		if app.Name != "" {
-			svcs = append(svcs, &Service{
-				Name:     app.Name,
-				Type:     CheckHTTP,
-				Value:    strings.TrimSuffix(app.Config.URL, "/json"),
-				Expect:   "200",
-				Timeout:  cnfg.Duration{Duration: app.Timeout.Duration},
-				Interval: interval,
-				validSSL: app.ValidSSL,
-			})
-		}
-	}
-
-	// NZBGet instances.
-	for _, app := range c.Apps.NZBGet {
-		if !app.Enabled() || app.Name == "" || app.Interval.Duration < 0 {
-			continue
-		}
-
-		interval := app.Interval
-		if interval.Duration == 0 {
-			interval.Duration = DefaultCheckInterval
-		}
-
-		prefix := "" // add auth to the url here. woo, hacky, but it works!
-
-		if !strings.Contains(app.Config.URL, "@") {
-			user := url.PathEscape(app.User) + ":" + url.PathEscape(app.Pass) + "@"
-			if prefix = "http://" + user; strings.HasPrefix(app.Config.URL, "https://") {
-				prefix = "https://" + user
-			}
-		}
-
-		if app.Name != "" {
-			svcs = append(svcs, &Service{
-				Name:     app.Name,
-				Type:     CheckHTTP,
-				Value:    prefix + strings.TrimPrefix(strings.TrimPrefix(app.Config.URL, "https://"), "http://"),
-				Expect:   "200",
-				Timeout:  cnfg.Duration{Duration: app.Timeout.Duration},
-				Interval: interval,
-				validSSL: app.ValidSSL,
-			})
-		}
-	}
-
-	// Qbittorrent instanceapp.
-	for _, app := range c.Apps.Qbit {
-		if !app.Enabled() || app.Name == "" || app.Interval.Duration < 0 {
-			continue
-		}
-
-		interval := app.Interval
-		if interval.Duration == 0 {
-			interval.Duration = DefaultCheckInterval
-		}
-
-		if app.Name != "" {
-			svcs = append(svcs, &Service{
-				Name:     app.Name,
-				Type:     CheckHTTP,
-				Value:    app.URL,
-				Expect:   "200",
-				Timeout:  cnfg.Duration{Duration: app.Timeout.Duration},
-				Interval: interval,
-				validSSL: app.ValidSSL,
-			})
-		}
-	}
-
-	// rTorrent instanceapp.
-	for _, app := range c.Apps.Rtorrent {
-		if !app.Enabled() || app.Name == "" || app.Interval.Duration < 0 {
+	for _, client := range c.Apps.DownloadClients() {
+		if client.Name == "" || client.Interval.Duration < 0 {
 			continue
 		}
 
-		interval := app.Interval
+		interval := client.Interval
 		if interval.Duration == 0 {
 			interval.Duration = DefaultCheckInterval
 		}
 
-		if app.Name != "" {
-			svcs = append(svcs, &Service{
-				Name:     app.Name,
-				Type:     CheckHTTP,
-				Value:    app.URL,
-				Expect:   "200,401", // could not find a 200...
-				Timeout:  cnfg.Duration{Duration: app.Timeout.Duration},
-				Interval: interval,
-				validSSL: app.ValidSSL,
-			})
-		}
-	}
-
-	// SabNBZd instanceapp.
-	for _, app := range c.Apps.SabNZB {
-		if !app.Enabled() || app.Name == "" || app.Interval.Duration < 0 {
-			continue
-		}
-
-		interval := app.Interval
-		if interval.Duration == 0 {
-			interval.Duration = DefaultCheckInterval
-		}
-
-		if app.Name != "" {
-			svcs = append(svcs, &Service{
-				Name:     app.Name,
-				Type:     CheckHTTP,
-				Value:    app.URL + "/api?mode=version&apikey=" + app.APIKey,
-				Expect:   "200",
-				Timeout:  cnfg.Duration{Duration: app.Timeout.Duration},
-				Interval: interval,
-				validSSL: app.ValidSSL,
-			})
-		}
-	}
-
-	// Transmission instances.
-	for _, app := range c.Apps.Transmission {
-		if !app.Enabled() || app.Name == "" || app.Interval.Duration < 0 {
-			continue
-		}
-
-		interval := app.Interval
-		if interval.Duration == 0 {
-			interval.Duration = DefaultCheckInterval
-		}
-
-		expect := "401"
-		if app.User == "" {
-			expect = "409"
-		}
-
-		if app.Name != "" {
-			svcs = append(svcs, &Service{
-				Name:     app.Name,
-				Type:     CheckHTTP,
-				Value:    app.URL,
-				Expect:   expect, // no 200 from RPC endpoint.
-				Timeout:  cnfg.Duration{Duration: app.Timeout.Duration},
-				Interval: interval,
-				validSSL: app.ValidSSL,
-			})
-		}
+		svcs = append(svcs, &Service{
+			Name:       client.Name,
+			Type:       CheckHTTP,
+			Value:      client.Redacted(),
+			Expect:     "200",
+			Timeout:    client.Timeout,
+			Interval:   interval,
+			validSSL:   client.ValidSSL,
+			downloader: client,
+		})
 	}
 
 	return svcs
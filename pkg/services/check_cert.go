@@ -0,0 +1,279 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/hako/durafmt"
+)
+
+// Certificate check defaults.
+const (
+	DefaultCertWarn = 14 * 24 * time.Hour
+	DefaultCertCrit = 3 * 24 * time.Hour
+)
+
+// Custom errors.
+var (
+	ErrCertNoTLS    = fmt.Errorf("certificate checks require an https:// url")
+	ErrCertType     = fmt.Errorf("certificate checks only work with http and tcp check types")
+	ErrStartTLS     = fmt.Errorf("starttls must be one of smtp, imap, pop3 or ftp")
+	ErrCertNone     = fmt.Errorf("no peer certificates presented")
+	ErrStartTLSResp = fmt.Errorf("unexpected starttls response")
+)
+
+// Supported STARTTLS protocols for tcp certificate checks.
+const (
+	startTLSSMTP = "smtp"
+	startTLSIMAP = "imap"
+	startTLSPOP3 = "pop3"
+	startTLSFTP  = "ftp"
+)
+
+/*
+ * These all run once at startup to fill our check data.
+ * The service Lock is acquired before running any of this code.
+ */
+
+// certExpect is setup for each 'http' or 'tcp' service with certificate checks enabled.
+type certExpect struct {
+	warn     time.Duration
+	crit     time.Duration
+	startTLS string
+}
+
+func (s *Service) checkCertValues() error {
+	if !s.CertCheck {
+		if s.StartTLS != "" {
+			s.CertCheck = true // starttls implies a certificate check.
+		} else {
+			return nil
+		}
+	}
+
+	switch s.Type {
+	case CheckHTTP:
+		if !strings.HasPrefix(strings.ToLower(s.Value), "https://") {
+			return fmt.Errorf("%s: %w", s.Name, ErrCertNoTLS)
+		}
+
+		if s.StartTLS != "" {
+			return fmt.Errorf("%s: %w: not valid with http checks", s.Name, ErrStartTLS)
+		}
+	case CheckTCP:
+		switch s.StartTLS = strings.ToLower(s.StartTLS); s.StartTLS {
+		case "", startTLSSMTP, startTLSIMAP, startTLSPOP3, startTLSFTP:
+		default:
+			return fmt.Errorf("%s: %w: %s", s.Name, ErrStartTLS, s.StartTLS)
+		}
+	default:
+		return fmt.Errorf("%s: %w", s.Name, ErrCertType)
+	}
+
+	if s.CertWarn.Duration == 0 {
+		s.CertWarn.Duration = DefaultCertWarn
+	}
+
+	if s.CertCrit.Duration == 0 {
+		s.CertCrit.Duration = DefaultCertCrit
+	}
+
+	s.svc.cert = &certExpect{
+		warn:     s.CertWarn.Duration,
+		crit:     s.CertCrit.Duration,
+		startTLS: s.StartTLS,
+	}
+
+	return nil
+}
+
+// checkCerts inspects a peer certificate chain and downgrades the result state
+// if any certificate in the chain expires within the configured thresholds.
+// Certificate details are added to the result metadata.
+func (c *certExpect) checkCerts(res *result, certs []*x509.Certificate) *result {
+	if res.metadata == nil {
+		res.metadata = make(map[string]any)
+	}
+
+	if len(certs) == 0 {
+		res.metadata["cert_error"] = ErrCertNone.Error()
+
+		if res.state < StateCritical {
+			res.state = StateCritical
+			res.output += "; " + ErrCertNone.Error()
+		}
+
+		return res
+	}
+
+	leaf := certs[0]
+	chain := make([]map[string]any, len(certs))
+	expires := leaf
+
+	for idx, cert := range certs {
+		chain[idx] = map[string]any{
+			"subject":  cert.Subject.String(),
+			"issuer":   cert.Issuer.String(),
+			"notAfter": cert.NotAfter,
+		}
+
+		if cert.NotAfter.Before(expires.NotAfter) {
+			expires = cert
+		}
+	}
+
+	left := time.Until(expires.NotAfter)
+	res.metadata["cert_not_after"] = expires.NotAfter
+	res.metadata["cert_expires_in"] = int64(left.Seconds())
+	res.metadata["cert_issuer"] = leaf.Issuer.String()
+	res.metadata["cert_subject"] = leaf.Subject.String()
+	res.metadata["cert_sans"] = certNames(leaf)
+	res.metadata["cert_chain"] = chain
+
+	state, msg := StateOK, ""
+
+	switch {
+	case left <= 0:
+		state, msg = StateCritical, "certificate expired: "+expires.Subject.CommonName
+	case left < c.crit:
+		state, msg = StateCritical, fmt.Sprintf("certificate %s expires in %s",
+			expires.Subject.CommonName, durafmt.ParseShort(left.Round(time.Minute)))
+	case left < c.warn:
+		state, msg = StateWarning, fmt.Sprintf("certificate %s expires in %s",
+			expires.Subject.CommonName, durafmt.ParseShort(left.Round(time.Minute)))
+	}
+
+	if state > res.state {
+		res.state = state
+		res.output += "; " + msg
+	}
+
+	return res
+}
+
+// certNames returns all the DNS and IP SANs from a certificate.
+func certNames(cert *x509.Certificate) []string {
+	names := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+
+	return names
+}
+
+// checkTCPCert connects to a tcp port, optionally negotiates STARTTLS, and inspects the certificate chain.
+func (s *Service) checkTCPCert(ctx context.Context) *result {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout.Duration)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.Value)
+	if err != nil {
+		return &result{state: StateCritical, output: "connection error: " + err.Error()}
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err = startTLS(conn, s.svc.cert.startTLS); err != nil {
+		return &result{state: StateCritical, output: "starttls error: " + err.Error()}
+	}
+
+	host, _, _ := net.SplitHostPort(s.Value)
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: !s.validSSL}) //nolint:gosec
+
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return &result{state: StateCritical, output: "tls handshake error: " + err.Error()}
+	}
+
+	res := &result{state: StateOK, output: "tls connection to port " + strings.Split(s.Value, ":")[1] + " OK"}
+
+	return s.svc.cert.checkCerts(res, tlsConn.ConnectionState().PeerCertificates)
+}
+
+// startTLS runs the plain text part of a STARTTLS exchange.
+// The connection is ready for a tls handshake when this returns without an error.
+func startTLS(conn net.Conn, proto string) error {
+	switch proto {
+	case startTLSSMTP:
+		text := textproto.NewConn(conn)
+		if _, _, err := text.ReadResponse(220); err != nil { //nolint:gomnd
+			return fmt.Errorf("reading smtp greeting: %w", err)
+		} else if err := textCmd(text, 250, "EHLO notifiarr"); err != nil { //nolint:gomnd
+			return err
+		}
+
+		return textCmd(text, 220, "STARTTLS") //nolint:gomnd
+	case startTLSFTP:
+		text := textproto.NewConn(conn)
+		if _, _, err := text.ReadResponse(220); err != nil { //nolint:gomnd
+			return fmt.Errorf("reading ftp greeting: %w", err)
+		}
+
+		return textCmd(text, 234, "AUTH TLS") //nolint:gomnd
+	case startTLSIMAP:
+		return lineCmd(conn, "* OK", "a1 STARTTLS", "a1 OK")
+	case startTLSPOP3:
+		return lineCmd(conn, "+OK", "STLS", "+OK")
+	default:
+		return nil // direct tls.
+	}
+}
+
+// textCmd sends a command on a numeric-response protocol (smtp, ftp) and checks the response code.
+func textCmd(text *textproto.Conn, code int, cmd string) error {
+	id, err := text.Cmd(cmd)
+	if err != nil {
+		return fmt.Errorf("sending %s: %w", cmd, err)
+	}
+
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+
+	if _, _, err := text.ReadResponse(code); err != nil {
+		return fmt.Errorf("%s: %w", cmd, err)
+	}
+
+	return nil
+}
+
+// lineCmd waits for a greeting, sends a command, and waits for a line with the expected prefix.
+// This works for imap and pop3. Servers wait for our tls hello after accepting STARTTLS,
+// so the buffered reader never swallows handshake data.
+func lineCmd(conn net.Conn, greeting, cmd, expect string) error {
+	reader := bufio.NewReader(conn)
+
+	if line, err := reader.ReadString('\n'); err != nil {
+		return fmt.Errorf("reading greeting: %w", err)
+	} else if !strings.HasPrefix(line, greeting) {
+		return fmt.Errorf("%w: %s", ErrStartTLSResp, strings.TrimSpace(line))
+	}
+
+	if _, err := conn.Write([]byte(cmd + "\r\n")); err != nil {
+		return fmt.Errorf("sending %s: %w", cmd, err)
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading %s response: %w", cmd, err)
+		}
+
+		switch {
+		case strings.HasPrefix(line, expect):
+			return nil
+		case strings.HasPrefix(line, "*"):
+			continue // imap untagged response.
+		default:
+			return fmt.Errorf("%w: %s", ErrStartTLSResp, strings.TrimSpace(line))
+		}
+	}
+}
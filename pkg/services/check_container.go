@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/snapshot"
+)
+
+// checkContainer inspects the docker or podman container named in the check value.
+// A container that is missing, stopped, restarting or unhealthy is critical.
+// A health check that is still starting is a warning.
+func (s *Service) checkContainer(ctx context.Context) *result {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout.Duration)
+	defer cancel()
+
+	container, err := snapshot.GetContainer(ctx, s.containers, s.Value)
+	if err != nil {
+		return &result{state: StateCritical, output: "inspecting container: " + err.Error()}
+	}
+
+	meta := map[string]any{
+		"id":       container.ID,
+		"image":    container.Image,
+		"state":    container.State,
+		"health":   container.Health,
+		"restarts": container.Restarts,
+		"started":  container.Started,
+		"exitcode": container.ExitCode,
+	}
+	output := []string{container.Status}
+
+	if container.Restarts > 0 {
+		output = append(output, fmt.Sprintf("%d restarts", container.Restarts))
+	}
+
+	if container.State != snapshot.ContainerRunning && container.ExitCode != 0 {
+		output = append(output, fmt.Sprintf("exit code %d", container.ExitCode))
+	}
+
+	switch {
+	case container.State != snapshot.ContainerRunning, container.Health == snapshot.ContainerUnhealthy:
+		return &result{state: StateCritical, output: strings.Join(output, ", "), metadata: meta}
+	case container.Health == snapshot.ContainerStarting:
+		return &result{state: StateWarning, output: strings.Join(output, ", "), metadata: meta}
+	default:
+		return &result{state: StateOK, output: strings.Join(output, ", "), metadata: meta}
+	}
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// Custom errors.
+var (
+	ErrNoDNSVal     = fmt.Errorf("dns 'check' must not be empty")
+	ErrDNSType      = fmt.Errorf("dns expect record type must be one of A, AAAA, CNAME or TXT")
+	ErrDNSBadServer = fmt.Errorf("dns resolver must be an ip or host with an optional :port")
+)
+
+// DNS record types supported by the dns check.
+const (
+	dnsTypeA     = "A"
+	dnsTypeAAAA  = "AAAA"
+	dnsTypeCNAME = "CNAME"
+	dnsTypeTXT   = "TXT"
+	dnsPort      = "53"
+)
+
+/*
+ * These all run once at startup to fill our check data.
+ * The service Lock is acquired before running any of this code.
+ */
+
+// dnsExpect is setup for each 'dns' service from input data on initialization.
+type dnsExpect struct {
+	name     string   // the record name to look up.
+	server   string   // optional resolver ip:port, uses system resolver when empty.
+	rtype    string   // A, AAAA, CNAME or TXT.
+	answers  []string // optional list of answers that must be present.
+	resolver *net.Resolver
+}
+
+// checkDNSValues parses the check value and expect for a dns service.
+// Value format is: name|resolver:port, example: sonarr.lan|10.1.1.1:53
+// Expect format is: TYPE:answer,answer, example: A:10.1.1.5,10.1.1.6.
+func (s *Service) checkDNSValues() error {
+	splitVal := strings.Split(s.Value, "|")
+	if s.svc.dns = (&dnsExpect{name: strings.TrimSpace(splitVal[0])}); s.svc.dns.name == "" {
+		return ErrNoDNSVal
+	}
+
+	if len(splitVal) > 1 && strings.TrimSpace(splitVal[1]) != "" {
+		if err := s.fillDNSServer(strings.TrimSpace(splitVal[1])); err != nil {
+			return err
+		}
+	}
+
+	if s.Expect == "" {
+		s.Expect = dnsTypeA
+	}
+
+	rtype, answers, _ := strings.Cut(s.Expect, ":")
+
+	switch s.svc.dns.rtype = strings.ToUpper(strings.TrimSpace(rtype)); s.svc.dns.rtype {
+	case dnsTypeA, dnsTypeAAAA, dnsTypeCNAME, dnsTypeTXT:
+	default:
+		return fmt.Errorf("%w: %s", ErrDNSType, rtype)
+	}
+
+	for _, answer := range strings.Split(answers, expectdelim) {
+		if answer = strings.TrimSpace(answer); answer != "" {
+			s.svc.dns.answers = append(s.svc.dns.answers, answer)
+		}
+	}
+
+	return nil
+}
+
+// fillDNSServer sets up a custom resolver that only talks to the provided server.
+func (s *Service) fillDNSServer(server string) error {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		// Missing port, most likely. Try again with the default port.
+		server = net.JoinHostPort(strings.Trim(server, "[]"), dnsPort)
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			return fmt.Errorf("%w: %s", ErrDNSBadServer, server)
+		}
+	}
+
+	s.svc.dns.server = server
+	s.svc.dns.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server)
+		},
+	}
+
+	return nil
+}
+
+func (s *Service) checkDNS(ctx context.Context) *result {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout.Duration)
+	defer cancel()
+
+	answers, err := s.svc.dns.lookup(ctx)
+	meta := map[string]any{
+		"dns_name":    s.svc.dns.name,
+		"dns_type":    s.svc.dns.rtype,
+		"dns_answers": answers,
+	}
+
+	if s.svc.dns.server != "" {
+		meta["dns_server"] = s.svc.dns.server
+	}
+
+	if err != nil {
+		return dnsErrorResult(err, meta)
+	}
+
+	if len(answers) == 0 {
+		return &result{
+			state:    StateCritical,
+			output:   fmt.Sprintf("%s %s: no records returned", s.svc.dns.rtype, s.svc.dns.name),
+			metadata: meta,
+		}
+	}
+
+	if missing := s.svc.dns.missing(answers); len(missing) > 0 {
+		return &result{
+			state: StateWarning,
+			output: fmt.Sprintf("%s %s: answer mismatch, missing: %s, got: %s", s.svc.dns.rtype, s.svc.dns.name,
+				strings.Join(missing, expectdelim), strings.Join(answers, expectdelim)),
+			metadata: meta,
+		}
+	}
+
+	return &result{
+		state:    StateOK,
+		output:   fmt.Sprintf("%s %s: %s", s.svc.dns.rtype, s.svc.dns.name, strings.Join(answers, expectdelim)),
+		metadata: meta,
+	}
+}
+
+// dnsErrorResult turns a resolver error into a check result.
+func dnsErrorResult(err error, meta map[string]any) *result {
+	var dnsErr *net.DNSError
+
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		meta["dns_error"] = "NXDOMAIN"
+		return &result{state: StateCritical, output: "NXDOMAIN: " + err.Error(), metadata: meta}
+	case errors.As(err, &dnsErr) && dnsErr.IsTimeout, errors.Is(err, context.DeadlineExceeded):
+		meta["dns_error"] = "timeout"
+		return &result{state: StateCritical, output: "timeout: " + err.Error(), metadata: meta}
+	default:
+		meta["dns_error"] = err.Error()
+		return &result{state: StateCritical, output: "lookup error: " + err.Error(), metadata: meta}
+	}
+}
+
+// lookup runs the dns query and returns the answers as strings.
+func (d *dnsExpect) lookup(ctx context.Context) ([]string, error) {
+	resolver := d.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	var (
+		answers []string
+		err     error
+	)
+
+	switch d.rtype {
+	case dnsTypeCNAME:
+		var cname string
+		if cname, err = resolver.LookupCNAME(ctx, d.name); cname != "" {
+			answers = []string{cname}
+		}
+	case dnsTypeTXT:
+		answers, err = resolver.LookupTXT(ctx, d.name)
+	case dnsTypeAAAA:
+		answers, err = lookupIP(ctx, resolver, "ip6", d.name)
+	default:
+		answers, err = lookupIP(ctx, resolver, "ip4", d.name)
+	}
+
+	if err != nil {
+		return answers, fmt.Errorf("%s %s: %w", d.rtype, d.name, err)
+	}
+
+	return answers, nil
+}
+
+func lookupIP(ctx context.Context, resolver *net.Resolver, network, name string) ([]string, error) {
+	ips, err := resolver.LookupIP(ctx, network, name)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by caller.
+	}
+
+	answers := make([]string, len(ips))
+	for idx, ip := range ips {
+		answers[idx] = ip.String()
+	}
+
+	return answers, nil
+}
+
+// missing returns the expected answers that were not found in the provided answers.
+func (d *dnsExpect) missing(answers []string) []string {
+	missing := []string{}
+
+	for _, expect := range d.answers {
+		if !slices.ContainsFunc(answers, func(answer string) bool {
+			return strings.EqualFold(strings.TrimSuffix(answer, "."), strings.TrimSuffix(expect, "."))
+		}) {
+			missing = append(missing, expect)
+		}
+	}
+
+	return missing
+}
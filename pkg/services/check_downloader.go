@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps"
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+// checkDownloader asks a download client for its status. These checks are made from
+// download client configs, and are reported to the website as http checks.
+func (s *Service) checkDownloader(ctx context.Context) *result {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout.Duration)
+	defer cancel()
+
+	client, _ := s.downloader.(*apps.DownloadClient)
+	meta := map[string]any{}
+
+	if client != nil {
+		meta["app"] = string(client.App)
+		meta["instance"] = client.Instance
+	}
+
+	status, err := s.downloader.GetStatus(ctx)
+	if err != nil {
+		return &result{state: StateCritical, output: "getting status: " + err.Error(), metadata: meta}
+	}
+
+	meta["download_rate"] = status.DownRate
+	meta["upload_rate"] = status.UpRate
+	meta["download_limit"] = status.DownLimit
+	meta["upload_limit"] = status.UpLimit
+	meta["free_space"] = status.FreeSpace
+	meta["paused"] = status.Paused
+
+	output := fmt.Sprintf("down: %s/s, up: %s/s", mnd.FormatBytes(status.DownRate), mnd.FormatBytes(status.UpRate))
+	if status.FreeSpace > 0 {
+		output += ", free: " + mnd.FormatBytes(status.FreeSpace)
+	}
+
+	if status.Paused {
+		output += ", paused"
+	}
+
+	return &result{state: StateOK, output: output, metadata: meta}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Custom errors.
+var (
+	ErrHTTPMethod   = fmt.Errorf("invalid http method")
+	ErrHTTPHeader   = fmt.Errorf("http headers must be in the format 'Name: value'")
+	ErrJSONValue    = fmt.Errorf("json_value requires a json_path")
+	ErrJSONPathMiss = fmt.Errorf("json path not found")
+)
+
+// StringList is a list of strings provided in the config file.
+// This is its own type so the GUI form decoder does not split the values on spaces.
+type StringList []string
+
+/*
+ * These all run once at startup to fill our check data.
+ * The service Lock is acquired before running any of this code.
+ */
+
+// httpExpect is setup for each 'http' service from input data on initialization.
+type httpExpect struct {
+	method      string
+	headers     http.Header
+	respHeaders http.Header // empty value means the header only needs to exist.
+	bodyRE      *regexp.Regexp
+	jsonPath    []string
+}
+
+func (s *Service) checkHTTPValues() (err error) {
+	s.svc.http = &httpExpect{
+		method:      http.MethodGet,
+		headers:     make(http.Header),
+		respHeaders: make(http.Header),
+	}
+
+	if s.Method != "" {
+		switch s.svc.http.method = strings.ToUpper(s.Method); s.svc.http.method {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		default:
+			return fmt.Errorf("%s: %w: %s", s.Name, ErrHTTPMethod, s.Method)
+		}
+	}
+
+	for _, header := range s.Headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%s: %w: %s", s.Name, ErrHTTPHeader, header)
+		}
+
+		s.svc.http.headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	for _, header := range s.RespHeaders {
+		name, value, _ := strings.Cut(header, ":")
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%s: %w: %s", s.Name, ErrHTTPHeader, header)
+		}
+
+		s.svc.http.respHeaders.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	if s.BodyRegex != "" {
+		if s.svc.http.bodyRE, err = regexp.Compile(s.BodyRegex); err != nil {
+			return fmt.Errorf("%s: invalid body_regex %s: %w", s.Name, s.BodyRegex, err)
+		}
+	}
+
+	if s.JSONPath != "" {
+		s.svc.http.jsonPath = splitJSONPath(s.JSONPath)
+	} else if s.JSONValue != "" {
+		return fmt.Errorf("%s: %w", s.Name, ErrJSONValue)
+	}
+
+	return nil
+}
+
+// splitJSONPath turns a path like data.items[0].status into [data items 0 status].
+func splitJSONPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(strings.TrimPrefix(path, "$."))
+	split := []string{}
+
+	for _, key := range strings.Split(path, ".") {
+		if key != "" {
+			split = append(split, key)
+		}
+	}
+
+	return split
+}
+
+// checkHTTPAssertions runs all the configured response assertions after the status code was accepted.
+// Failed assertions are critical; a slow response is only a warning.
+func (s *Service) checkHTTPAssertions(res *result, resp *http.Response, body []byte, latency time.Duration) *result {
+	if msg := s.svc.http.checkHeaders(resp.Header); msg != "" {
+		res.state = StateCritical
+		res.output = resp.Status + ": " + msg
+
+		return res
+	}
+
+	if s.svc.http.bodyRE != nil && !s.svc.http.bodyRE.Match(body) {
+		res.state = StateCritical
+		res.output = resp.Status + ": body does not match regex: " + s.BodyRegex
+
+		return res
+	}
+
+	if len(s.svc.http.jsonPath) > 0 {
+		if msg := s.checkJSONPath(body); msg != "" {
+			res.state = StateCritical
+			res.output = resp.Status + ": " + msg
+
+			return res
+		}
+	}
+
+	if s.MaxLatency.Duration > 0 {
+		if res.metadata == nil {
+			res.metadata = make(map[string]any)
+		}
+
+		res.metadata["latency_ms"] = latency.Milliseconds()
+
+		if latency > s.MaxLatency.Duration {
+			res.state = StateWarning
+			res.output = fmt.Sprintf("%s: slow response: %s > %s", resp.Status,
+				latency.Round(time.Millisecond), s.MaxLatency.Duration)
+		}
+	}
+
+	return res
+}
+
+// checkHeaders returns a message if a required response header is missing or has the wrong value.
+func (h *httpExpect) checkHeaders(headers http.Header) string {
+	for name := range h.respHeaders {
+		expect := h.respHeaders.Get(name)
+		value := headers.Get(name)
+
+		switch {
+		case len(headers.Values(name)) == 0:
+			return "missing response header: " + name
+		case expect != "" && !strings.EqualFold(value, expect):
+			return fmt.Sprintf("response header %s: expected '%s', got '%s'", name, expect, value)
+		}
+	}
+
+	return ""
+}
+
+// checkJSONPath returns a message if the body is not json or the path does not have the expected value.
+func (s *Service) checkJSONPath(body []byte) string {
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "invalid json body: " + err.Error()
+	}
+
+	value, err := jsonPathValue(data, s.svc.http.jsonPath)
+	if err != nil {
+		return fmt.Sprintf("%v: %s", err, s.JSONPath)
+	}
+
+	if s.JSONValue == "" {
+		return ""
+	}
+
+	if got := jsonString(value); got != s.JSONValue {
+		return fmt.Sprintf("json path %s: expected '%s', got '%s'", s.JSONPath, s.JSONValue, got)
+	}
+
+	return ""
+}
+
+// jsonPathValue walks decoded json data and returns the value at the end of the path.
+func jsonPathValue(data any, path []string) (any, error) {
+	for _, key := range path {
+		switch node := data.(type) {
+		case map[string]any:
+			val, ok := node[key]
+			if !ok {
+				return nil, ErrJSONPathMiss
+			}
+
+			data = val
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, ErrJSONPathMiss
+			}
+
+			data = node[idx]
+		default:
+			return nil, ErrJSONPathMiss
+		}
+	}
+
+	return data, nil
+}
+
+// jsonString turns a decoded json value into a string for comparison.
+func jsonString(value any) string {
+	switch val := value.(type) {
+	case string:
+		return val
+	case nil:
+		return "null"
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		out, _ := json.Marshal(val)
+		return string(out)
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/snapshot"
+)
+
+// checkZFS runs zpool status for the pool named in the check value.
+// A pool that is not online is critical. Errors on an online pool are a warning.
+func (s *Service) checkZFS(ctx context.Context) *result {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout.Duration)
+	defer cancel()
+
+	health, err := snapshot.GetZFSPoolHealth(ctx, s.Value)
+	if err != nil {
+		return &result{state: StateCritical, output: "getting pool status: " + err.Error()}
+	}
+
+	meta := map[string]any{
+		"state":        health.State,
+		"vdev_errors":  health.VdevErrors(),
+		"data_errors":  health.DataErrors,
+		"scan_type":    health.Scan.Type,
+		"scan_state":   health.Scan.State,
+		"scan_errors":  health.Scan.Errors,
+		"scan_date":    health.Scan.Date,
+		"scan_percent": health.Scan.Progress,
+	}
+	output := []string{health.State}
+
+	if health.Scan.State == snapshot.ZFSScanRunning {
+		output = append(output, fmt.Sprintf("%s %.1f%% done", health.Scan.Type, health.Scan.Progress))
+	} else if health.Scan.Line != "" {
+		output = append(output, health.Scan.Line)
+	}
+
+	if count := health.VdevErrors(); count > 0 {
+		output = append(output, fmt.Sprintf("%d vdev read/write/checksum errors", count))
+	}
+
+	if health.DataErrors > 0 {
+		output = append(output, fmt.Sprintf("%d data errors", health.DataErrors))
+	}
+
+	switch {
+	case health.State != snapshot.ZFSOnline:
+		return &result{state: StateCritical, output: strings.Join(output, ", "), metadata: meta}
+	case health.VdevErrors() > 0 || health.DataErrors > 0 || health.Scan.Errors > 0:
+		return &result{state: StateWarning, output: strings.Join(output, ", "), metadata: meta}
+	default:
+		return &result{state: StateOK, output: strings.Join(output, ", "), metadata: meta}
+	}
+}
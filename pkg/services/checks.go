@@ -22,8 +22,12 @@ const (
 )
 
 type result struct {
-	output string
-	state  CheckState
+	output   string
+	state    CheckState
+	metadata map[string]any // merged with service tags in the check result.
+	// parentDown is true when the check did not run because a parent service is down.
+	parentDown bool
+	latency    time.Duration // how long the check took to run.
 }
 
 // triggerCheck is used to signal the check of one service.
@@ -55,10 +59,20 @@ func (s *Service) Validate() error { //nolint:cyclop
 				s.validSSL = true
 			}
 		}
+
+		if err := s.checkHTTPValues(); err != nil {
+			return err
+		}
 	case CheckTCP:
 		if !strings.Contains(s.Value, ":") {
 			return ErrBadTCP
 		}
+
+		for _, code := range strings.Split(s.Expect, expectdelim) {
+			if strings.EqualFold(code, sslstring) {
+				s.validSSL = true
+			}
+		}
 	case CheckPROC:
 		if err := s.checkProcValues(); err != nil {
 			return err
@@ -67,10 +81,22 @@ func (s *Service) Validate() error { //nolint:cyclop
 		if err := s.checkPingValues(s.Type == CheckICMP); err != nil {
 			return err
 		}
+	case CheckDNS:
+		if err := s.checkDNSValues(); err != nil {
+			return err
+		}
+	case CheckZFS:
+		// The value is a pool name; zpool status finds out if it exists.
+	case CheckContainer:
+		// The value is a container name or id; the container api finds out if it exists.
 	default:
 		return ErrInvalidType
 	}
 
+	if err := s.checkCertValues(); err != nil {
+		return err
+	}
+
 	if s.Timeout.Duration == 0 {
 		s.Timeout.Duration = DefaultTimeout
 	} else if s.Timeout.Duration < MinimumTimeout {
@@ -83,6 +109,10 @@ func (s *Service) Validate() error { //nolint:cyclop
 		s.Interval.Duration = MinimumCheckInterval
 	}
 
+	if s.FlapChanges > 0 && s.FlapWindow.Duration == 0 {
+		s.FlapWindow.Duration = DefaultFlapWindow
+	}
+
 	return nil
 }
 
@@ -94,27 +124,52 @@ func (s *Service) CheckOnly(ctx context.Context) *CheckResult {
 	return &CheckResult{
 		Output:   res.output,
 		State:    res.state,
-		Metadata: s.Tags,
+		Metadata: s.metadata(res.metadata),
 	}
 }
 
 func (s *Service) checkNow(ctx context.Context) (res *result) {
 	switch s.Type {
 	case CheckHTTP:
+		if s.downloader != nil {
+			return s.checkDownloader(ctx)
+		}
+
 		return s.checkHTTP(ctx)
 	case CheckTCP:
+		if s.svc.cert != nil {
+			return s.checkTCPCert(ctx)
+		}
+
 		return s.checkTCP()
 	case CheckPING, CheckICMP:
 		return s.checkPING()
 	case CheckPROC:
 		return s.checkProccess(ctx)
+	case CheckDNS:
+		return s.checkDNS(ctx)
+	case CheckZFS:
+		return s.checkZFS(ctx)
+	case CheckContainer:
+		return s.checkContainer(ctx)
 	default:
 		return nil
 	}
 }
 
 func (s *Service) check(ctx context.Context) bool {
-	return s.update(s.checkNow(ctx))
+	if res := s.checkParents(); res != nil {
+		return s.update(res)
+	}
+
+	start := time.Now()
+	res := s.checkNow(ctx)
+
+	if res != nil {
+		res.latency = time.Since(start)
+	}
+
+	return s.update(res)
 }
 
 // Return true if the service state changed.
@@ -130,15 +185,33 @@ func (s *Service) update(res *result) bool {
 	s.svc.Lock()
 	defer s.svc.Unlock()
 
-	if s.svc.LastCheck = time.Now().Round(time.Microsecond); s.svc.Since.IsZero() {
+	first := s.svc.Since.IsZero() // the first check is not a state change for flap detection.
+	if s.svc.LastCheck = time.Now().Round(time.Microsecond); first {
 		s.svc.Since = s.svc.LastCheck
 	}
 
-	s.svc.Output = res.output
+	s.expireFlapping(s.svc.LastCheck)
+	s.svc.latency = res.latency
 
 	if s.svc.State == res.state {
+		s.svc.streak = 0
+		s.svc.Output = res.output
+		s.svc.Metadata = res.metadata
+		s.svc.parentDown = res.parentDown
 		s.svc.log.Printf("Service Checked: %s, state: %s for %v, output: %s",
 			s.Name, s.svc.State, time.Since(s.svc.Since).Round(time.Second), s.svc.Output)
+
+		return false
+	}
+
+	held := s.holdState(res)
+	s.svc.Output = res.output
+	s.svc.Metadata = res.metadata
+	s.svc.parentDown = res.parentDown
+
+	if held {
+		s.svc.log.Printf("Service Checked: %s, state: %s for %v (held), output: %s",
+			s.Name, s.svc.State, time.Since(s.svc.Since).Round(time.Second), s.svc.Output)
 		return false
 	}
 
@@ -146,6 +219,10 @@ func (s *Service) update(res *result) bool {
 	s.svc.Since = s.svc.LastCheck
 	s.svc.State = res.state
 
+	if !first {
+		s.trackFlapping(s.svc.LastCheck)
+	}
+
 	return true
 }
 
@@ -156,11 +233,26 @@ func (s *Service) checkHTTPReq(ctx context.Context) (*http.Client, *http.Request
 	// Allow adding headers by appending them after a pipe symbol.
 	splitVal := strings.Split(s.Value, "|")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, splitVal[0], nil)
+	var body io.Reader
+	if s.Body != "" {
+		body = strings.NewReader(s.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, s.svc.http.method, splitVal[0], body)
 	if err != nil {
 		return nil, nil, err //nolint:wrapcheck // handled by caller
 	}
 
+	for name, values := range s.svc.http.headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+
+		if strings.EqualFold(name, "host") {
+			req.Host = s.svc.http.headers.Get(name)
+		}
+	}
+
 	for _, val := range splitVal[1:] {
 		// s.Value: http://url.com|header=value|another-header=val
 		if sv := strings.SplitN(val, ":", 2); len(sv) == 2 { //nolint:gomnd
@@ -200,6 +292,8 @@ func (s *Service) checkHTTP(ctx context.Context) *result {
 	// If there is an error at this point it's a bad request.
 	res.state = StateCritical
 
+	start := time.Now()
+
 	resp, err := client.Do(req)
 	if err != nil {
 		res.output = "making request: " + RemoveSecrets(s.Value, err.Error())
@@ -213,12 +307,15 @@ func (s *Service) checkHTTP(ctx context.Context) *result {
 		return res
 	}
 
+	latency := time.Since(start)
+
 	for _, code := range strings.Split(s.Expect, expectdelim) {
 		if strconv.Itoa(resp.StatusCode) == strings.TrimSpace(code) {
 			res.state = StateOK
 			res.output = resp.Status
+			res = s.checkHTTPAssertions(res, resp, body, latency)
 
-			return res
+			return s.checkHTTPCert(res, resp)
 		}
 	}
 
@@ -230,7 +327,20 @@ func (s *Service) checkHTTP(ctx context.Context) *result {
 	res.state = StateCritical
 	res.output = resp.Status + ": " + strings.TrimSpace(RemoveSecrets(s.Value, bodyStr))
 
-	return res
+	return s.checkHTTPCert(res, resp)
+}
+
+// checkHTTPCert adds certificate data to an http check result when certificate checks are enabled.
+func (s *Service) checkHTTPCert(res *result, resp *http.Response) *result {
+	if s.svc.cert == nil {
+		return res
+	}
+
+	if resp.TLS == nil {
+		return s.svc.cert.checkCerts(res, nil)
+	}
+
+	return s.svc.cert.checkCerts(res, resp.TLS.PeerCertificates)
 }
 
 // RemoveSecrets removes secret token values in a message parsed from a url.
@@ -28,9 +28,11 @@ const (
 var (
 	ErrNoName      = fmt.Errorf("service check is missing a unique name")
 	ErrNoCheck     = fmt.Errorf("service check is missing a check value")
-	ErrInvalidType = fmt.Errorf("service check type must be one of %s, %s, %s, %s, %s",
-		CheckTCP, CheckHTTP, CheckPROC, CheckPING, CheckICMP)
-	ErrBadTCP = fmt.Errorf("tcp checks must have an ip:port or host:port combo; the :port is required")
+	ErrInvalidType = fmt.Errorf("service check type must be one of %s, %s, %s, %s, %s, %s, %s, %s",
+		CheckTCP, CheckHTTP, CheckPROC, CheckPING, CheckICMP, CheckDNS, CheckZFS, CheckContainer)
+	ErrBadTCP       = fmt.Errorf("tcp checks must have an ip:port or host:port combo; the :port is required")
+	ErrNoHistory    = fmt.Errorf("service check history is not enabled, set a history_file")
+	ErrInvalidHours = fmt.Errorf("hours must be a positive integer")
 )
 
 // Config for this Services plugin comes from a config file.
@@ -39,6 +41,8 @@ type Config struct {
 	Parallel    uint              `toml:"parallel" xml:"parallel" json:"parallel"`
 	Disabled    bool              `toml:"disabled" xml:"disabled" json:"disabled"`
 	LogFile     string            `toml:"log_file" xml:"log_file" json:"logFile"`
+	HistoryFile string            `toml:"history_file" xml:"history_file" json:"historyFile"`
+	HistorySize uint              `toml:"history_size" xml:"history_size" json:"historySize"`
 	Apps        *apps.Apps        `toml:"-" json:"-"`
 	Website     *website.Server   `toml:"-" json:"-"`
 	Plugins     *snapshot.Plugins `toml:"-" json:"-"` // pass this in so we can service-check mysql
@@ -49,6 +53,9 @@ type Config struct {
 	stopChan    chan struct{}
 	triggerChan chan website.EventType
 	checkChan   chan triggerCheck
+	levels      [][]*Service          // services sorted by dependency depth; parents first.
+	history     *history              // nil if history_file is not set.
+	notified    map[string]CheckState // last state sent to local notifiers, per service.
 	stopLock    sync.Mutex
 }
 
@@ -62,6 +69,10 @@ const (
 	CheckPING CheckType = "ping"
 	CheckICMP CheckType = "icmp"
 	CheckPROC CheckType = "process"
+	CheckDNS  CheckType = "dns"
+	CheckZFS  CheckType = "zfs"
+	// CheckContainer checks a docker or podman container by name.
+	CheckContainer CheckType = "container"
 )
 
 // CheckState represents the current state of a service check.
@@ -81,6 +92,8 @@ type Results struct {
 	What     website.EventType `json:"what"`
 	Interval float64           `json:"interval"`
 	Svcs     []*CheckResult    `json:"services"`
+	Deps     []*DepNode        `json:"dependencies,omitempty"`
+	changes  []*stateChange    // state changes since the last results were sent.
 }
 
 // CheckResult represents the status of a service.
@@ -96,28 +109,60 @@ type CheckResult struct {
 	Check       string         `json:"-"`
 	Expect      string         `json:"-"`
 	IntervalDur time.Duration  `json:"-"`
+	DependsOn   []string       `json:"dependsOn,omitempty"` // parent service names.
+	Flapping    bool           `json:"flapping"`            // state changes too often.
+	Latency     time.Duration  `json:"-"`                   // how long the last check took to run.
 }
 
 // Service is a thing we check and report results for.
 type Service struct {
-	Name     string         `toml:"name" xml:"name" json:"name"`             // Radarr
-	Type     CheckType      `toml:"type" xml:"type" json:"type"`             // http
-	Value    string         `toml:"check" xml:"check" json:"value"`          // http://some.url
-	Expect   string         `toml:"expect" xml:"expect" json:"expect"`       // 200
-	Timeout  cnfg.Duration  `toml:"timeout" xml:"timeout" json:"timeout"`    // 10s
-	Interval cnfg.Duration  `toml:"interval" xml:"interval" json:"interval"` // 1m
-	Tags     map[string]any `toml:"tags" xml:"tags" json:"tags"`             // copied to Metadata.
-	validSSL bool           // can be set for https checks.
-	svc      service
+	Name        string                    `toml:"name" xml:"name" json:"name"`                        // Radarr
+	Type        CheckType                 `toml:"type" xml:"type" json:"type"`                        // http
+	Value       string                    `toml:"check" xml:"check" json:"value"`                     // http://some.url
+	Expect      string                    `toml:"expect" xml:"expect" json:"expect"`                  // 200
+	Timeout     cnfg.Duration             `toml:"timeout" xml:"timeout" json:"timeout"`               // 10s
+	Interval    cnfg.Duration             `toml:"interval" xml:"interval" json:"interval"`            // 1m
+	Tags        map[string]any            `toml:"tags" xml:"tags" json:"tags"`                        // copied to Metadata.
+	CertCheck   bool                      `toml:"cert_check" xml:"cert_check" json:"certCheck"`       // inspect tls certs on http and tcp checks.
+	StartTLS    string                    `toml:"starttls" xml:"starttls" json:"startTls"`            // tcp only: smtp, imap, pop3, ftp
+	CertWarn    cnfg.Duration             `toml:"cert_warn" xml:"cert_warn" json:"certWarn"`          // 336h (14 days)
+	CertCrit    cnfg.Duration             `toml:"cert_crit" xml:"cert_crit" json:"certCrit"`          // 72h (3 days)
+	Method      string                    `toml:"method" xml:"method" json:"method"`                  // http only: GET
+	Body        string                    `toml:"body" xml:"body" json:"body"`                        // http only: request body.
+	Headers     StringList                `toml:"headers" xml:"headers" json:"headers"`               // http only: "Name: value"
+	BodyRegex   string                    `toml:"body_regex" xml:"body_regex" json:"bodyRegex"`       // http only: body must match.
+	JSONPath    string                    `toml:"json_path" xml:"json_path" json:"jsonPath"`          // http only: data.status
+	JSONValue   string                    `toml:"json_value" xml:"json_value" json:"jsonValue"`       // http only: expected json_path value.
+	RespHeaders StringList                `toml:"resp_headers" xml:"resp_headers" json:"respHeaders"` // http only: "Name" or "Name: value"
+	MaxLatency  cnfg.Duration             `toml:"max_latency" xml:"max_latency" json:"maxLatency"`    // http only: warning when slower.
+	DependsOn   StringList                `toml:"depends_on" xml:"depends_on" json:"dependsOn"`       // parent service names.
+	FailCount   uint                      `toml:"fail_count" xml:"fail_count" json:"failCount"`       // failures in a row before alerting.
+	PassCount   uint                      `toml:"pass_count" xml:"pass_count" json:"passCount"`       // passes in a row before recovering.
+	FlapChanges uint                      `toml:"flap_changes" xml:"flap_changes" json:"flapChanges"` // state changes that count as flapping.
+	FlapWindow  cnfg.Duration             `toml:"flap_window" xml:"flap_window" json:"flapWindow"`    // 1h, window for flap_changes.
+	validSSL    bool                      // can be set for https checks.
+	downloader  apps.Downloader           // only set on checks made from download client configs.
+	containers  *snapshot.ContainerConfig // only used for container checks.
+	svc         service
 }
 
 type service struct {
-	Output       string     `json:"output"`
-	State        CheckState `json:"state"`
-	Since        time.Time  `json:"since"`
-	LastCheck    time.Time  `json:"lastCheck"`
+	Output       string         `json:"output"`
+	State        CheckState     `json:"state"`
+	Since        time.Time      `json:"since"`
+	LastCheck    time.Time      `json:"lastCheck"`
+	Metadata     map[string]any `json:"metadata"`
+	Flapping     bool           `json:"flapping"`
 	log          mnd.Logger
-	proc         *procExpect // only used for process checks.
-	ping         *pingExpect // only used for icmp/udp ping checks.
+	proc         *procExpect   // only used for process checks.
+	ping         *pingExpect   // only used for icmp/udp ping checks.
+	dns          *dnsExpect    // only used for dns checks.
+	cert         *certExpect   // only used for http and tcp checks with cert_check enabled.
+	http         *httpExpect   // only used for http checks.
+	parents      []*Service    // services this service depends on.
+	parentDown   bool          // true if the last check was suppressed because a parent is down.
+	streak       uint          // results in a row held back by fail_count or pass_count.
+	changes      []time.Time   // recent state changes, used for flap detection.
+	latency      time.Duration // how long the last check took to run.
 	sync.RWMutex `json:"-"`
 }
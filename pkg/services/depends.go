@@ -0,0 +1,163 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Custom errors.
+var (
+	ErrDepNotFound = fmt.Errorf("service depends on an unknown service")
+	ErrDepCycle    = fmt.Errorf("service dependency cycle detected")
+)
+
+// DepNode is one service in the dependency tree sent to the website.
+// Services with more than one parent appear under each parent.
+type DepNode struct {
+	Name     string     `json:"name"`
+	State    CheckState `json:"state"`
+	Children []*DepNode `json:"children,omitempty"`
+}
+
+// setupDepends links each service to its parents, checks for cycles,
+// and sorts the services into levels so parents are always checked before children.
+// This runs once at startup after all services are validated.
+func (c *Config) setupDepends() error {
+	for _, svc := range c.services {
+		svc.svc.parents = nil
+
+		for _, name := range svc.DependsOn {
+			parent, ok := c.services[name]
+			if !ok {
+				return fmt.Errorf("%s: %w: %s", svc.Name, ErrDepNotFound, name)
+			}
+
+			svc.svc.parents = append(svc.svc.parents, parent)
+		}
+	}
+
+	depth := make(map[string]int, len(c.services))
+	visiting := make(map[string]bool)
+
+	var visit func(svc *Service, path []string) (int, error)
+	visit = func(svc *Service, path []string) (int, error) {
+		if level, ok := depth[svc.Name]; ok {
+			return level, nil
+		} else if visiting[svc.Name] {
+			return 0, fmt.Errorf("%s: %w: %s", svc.Name, ErrDepCycle, strings.Join(append(path, svc.Name), " -> "))
+		}
+
+		visiting[svc.Name] = true
+		level := 0
+
+		for _, parent := range svc.svc.parents {
+			parentLevel, err := visit(parent, append(path, svc.Name))
+			if err != nil {
+				return 0, err
+			}
+
+			level = max(level, parentLevel+1)
+		}
+
+		visiting[svc.Name] = false
+		depth[svc.Name] = level
+
+		return level, nil
+	}
+
+	c.levels = nil
+
+	for _, name := range c.serviceNames() {
+		level, err := visit(c.services[name], nil)
+		if err != nil {
+			return err
+		}
+
+		for len(c.levels) <= level {
+			c.levels = append(c.levels, []*Service{})
+		}
+
+		c.levels[level] = append(c.levels[level], c.services[name])
+	}
+
+	return nil
+}
+
+// serviceNames returns the service names in a stable order.
+func (c *Config) serviceNames() []string {
+	names := make([]string, 0, len(c.services))
+	for name := range c.services {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// checkParents returns a result if any parent service is down, otherwise nil.
+// A parent is down if it is critical, or if it was suppressed because its own parent is down.
+func (s *Service) checkParents() *result {
+	down := []string{}
+
+	for _, parent := range s.svc.parents {
+		parent.svc.RLock()
+		if parent.svc.State == StateCritical || parent.svc.parentDown {
+			down = append(down, parent.Name)
+		}
+		parent.svc.RUnlock()
+	}
+
+	if len(down) == 0 {
+		return nil
+	}
+
+	return &result{
+		state:      StateUnknown,
+		output:     "unknown (parent down): " + strings.Join(down, ", "),
+		metadata:   map[string]any{"parent_down": down},
+		parentDown: true,
+	}
+}
+
+// depTree returns the dependency tree for all services that have parents or children.
+// Returns nil if no services have dependencies.
+func (c *Config) depTree() []*DepNode {
+	children := make(map[string][]*Service)
+	hasParent := make(map[string]bool)
+
+	for _, name := range c.serviceNames() {
+		for _, parent := range c.services[name].svc.parents {
+			children[parent.Name] = append(children[parent.Name], c.services[name])
+			hasParent[name] = true
+		}
+	}
+
+	if len(children) == 0 {
+		return nil
+	}
+
+	var build func(svc *Service) *DepNode
+	build = func(svc *Service) *DepNode {
+		svc.svc.RLock()
+		node := &DepNode{Name: svc.Name, State: svc.svc.State}
+		svc.svc.RUnlock()
+
+		for _, child := range children[svc.Name] {
+			node.Children = append(node.Children, build(child))
+		}
+
+		return node
+	}
+
+	tree := []*DepNode{}
+
+	for _, name := range c.serviceNames() {
+		if !hasParent[name] && len(children[name]) > 0 {
+			tree = append(tree, build(c.services[name]))
+		}
+	}
+
+	return tree
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+// DefaultFlapWindow is used when flap_changes is set without a flap_window.
+const DefaultFlapWindow = time.Hour
+
+// holdState returns true if a state change should be held back because the
+// result has not been seen enough times in a row. Changes from the Unknown state,
+// changes between failing states, and parent-down results are never held.
+// The service lock must be held when calling this.
+func (s *Service) holdState(res *result) bool {
+	need := s.FailCount
+	if res.state == StateOK {
+		need = s.PassCount
+	}
+
+	switch {
+	case res.parentDown, s.svc.State == StateUnknown:
+		fallthrough
+	case s.svc.State != StateOK && res.state != StateOK: // warning <-> critical
+		s.svc.streak = 0
+		return false
+	}
+
+	if s.svc.streak++; s.svc.streak >= need {
+		s.svc.streak = 0
+		return false
+	}
+
+	mnd.ServiceChecks.Add(s.Name+"&&Held", 1)
+
+	res.output = fmt.Sprintf("(%s %d/%d) %s", res.state, s.svc.streak, need, res.output)
+	if res.metadata == nil {
+		res.metadata = make(map[string]any)
+	}
+
+	res.metadata["pending_state"] = res.state.String()
+	res.metadata["pending_count"] = s.svc.streak
+
+	return true
+}
+
+// trackFlapping records a state change and updates the flapping status.
+// A service is flapping when it changes state flap_changes times within flap_window.
+// The service lock must be held when calling this.
+func (s *Service) trackFlapping(now time.Time) {
+	if s.FlapChanges == 0 {
+		return
+	}
+
+	changes := []time.Time{}
+
+	for _, change := range append(s.svc.changes, now) {
+		if now.Sub(change) < s.FlapWindow.Duration {
+			changes = append(changes, change)
+		}
+	}
+
+	s.svc.changes = changes
+	s.setFlapping(uint(len(changes)) >= s.FlapChanges)
+}
+
+// expireFlapping clears the flapping status when no state changes happened within the window.
+// The service lock must be held when calling this.
+func (s *Service) expireFlapping(now time.Time) {
+	if !s.svc.Flapping || len(s.svc.changes) == 0 {
+		return
+	}
+
+	if now.Sub(s.svc.changes[len(s.svc.changes)-1]) >= s.FlapWindow.Duration {
+		s.svc.changes = nil
+		s.setFlapping(false)
+	}
+}
+
+func (s *Service) setFlapping(flapping bool) {
+	if s.svc.Flapping == flapping {
+		return
+	}
+
+	if s.svc.Flapping = flapping; flapping {
+		mnd.ServiceChecks.Add(s.Name+"&&Flapping", 1)
+		s.svc.log.Printf("Service Flapping: %s, %d state changes within %s", s.Name, len(s.svc.changes), s.FlapWindow)
+	} else {
+		s.svc.log.Printf("Service Stopped Flapping: %s", s.Name)
+	}
+}
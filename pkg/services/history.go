@@ -0,0 +1,346 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MaximumHistoryAge is how long check results are kept, and the longest uptime window.
+const MaximumHistoryAge = 30 * 24 * time.Hour
+
+// DefaultHistoryMax caps the default history size at 30 days of checks at the default interval.
+// Services checked more often than that keep fewer days, so a 10s check does not keep 259k results.
+const DefaultHistoryMax = int(MaximumHistoryAge/DefaultCheckInterval) + 1
+
+// uptimeWindows are the windows we calculate uptime percentages for.
+var uptimeWindows = []struct { //nolint:gochecknoglobals
+	name string
+	dur  time.Duration
+}{
+	{name: "24h", dur: 24 * time.Hour},
+	{name: "7d", dur: 7 * 24 * time.Hour},
+	{name: "30d", dur: MaximumHistoryAge},
+}
+
+// HistoryEntry is one service check result stored in the local history file.
+// Output is only stored when the state is not OK, to keep the file small.
+type HistoryEntry struct {
+	Time   time.Time  `json:"time"`
+	State  CheckState `json:"state"`
+	Output string     `json:"output,omitempty"`
+}
+
+// Uptime contains uptime percentages for one service.
+// OK and Warning count as up, Critical counts as down, and Unknown is not counted.
+// Windows without any counted checks are left out of the maps.
+type Uptime struct {
+	Name   string             `json:"name"`
+	State  CheckState         `json:"state"`
+	Uptime map[string]float64 `json:"uptime"` // window -> percent, ie. "24h": 99.95
+	Checks map[string]int     `json:"checks"` // window -> count of checks used for the percentage.
+}
+
+// history is a file-backed ring of check results per service.
+type history struct {
+	file    string
+	size    int            // configured results per service; 0 keeps 30 days at each service's interval, up to a max.
+	limits  map[string]int // results kept per service.
+	dirty   bool
+	entries map[string][]*HistoryEntry
+	sync.RWMutex
+}
+
+func newHistory(file string, size uint, services map[string]*Service) *history {
+	h := &history{
+		file:    file,
+		size:    int(size),
+		limits:  make(map[string]int, len(services)),
+		entries: make(map[string][]*HistoryEntry),
+	}
+
+	for name, svc := range services {
+		h.limits[name] = h.size
+		if h.size == 0 && svc.Interval.Duration > 0 {
+			h.limits[name] = min(int(MaximumHistoryAge/svc.Interval.Duration)+1, DefaultHistoryMax)
+		}
+	}
+
+	return h
+}
+
+// load reads the history file. A missing file is not an error.
+func (h *history) load() error {
+	data, err := os.ReadFile(h.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading history file: %w", err)
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return fmt.Errorf("decoding history file %s: %w", h.file, err)
+	}
+
+	for name := range h.entries {
+		h.trim(name, time.Now())
+	}
+
+	return nil
+}
+
+// save writes the history file if anything changed since the last save.
+// The data is written to a temporary file first, so a crash cannot truncate the history.
+func (h *history) save() error {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return fmt.Errorf("encoding history: %w", err)
+	}
+
+	tmpFile := filepath.Join(filepath.Dir(h.file), "."+filepath.Base(h.file)+".tmp")
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil { //nolint:gomnd
+		return fmt.Errorf("writing history file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, h.file); err != nil {
+		return fmt.Errorf("renaming history file: %w", err)
+	}
+
+	h.dirty = false
+
+	return nil
+}
+
+// add records the current state of a service.
+func (h *history) add(svc *Service) {
+	if h == nil {
+		return
+	}
+
+	svc.svc.RLock()
+	entry := &HistoryEntry{Time: svc.svc.LastCheck, State: svc.svc.State}
+
+	if entry.State != StateOK {
+		entry.Output = svc.svc.Output
+	}
+	svc.svc.RUnlock()
+
+	h.Lock()
+	defer h.Unlock()
+
+	h.entries[svc.Name] = append(h.entries[svc.Name], entry)
+	h.trim(svc.Name, entry.Time)
+	h.dirty = true
+}
+
+// trim removes the oldest entries for a service. The lock must be held.
+// Services without a limit, like removed services still in the file, are only trimmed by age.
+func (h *history) trim(name string, now time.Time) {
+	entries := h.entries[name]
+	drop := 0
+
+	if limit := h.limits[name]; limit > 0 && len(entries) > limit {
+		drop = len(entries) - limit
+	}
+
+	for drop < len(entries) && now.Sub(entries[drop].Time) > MaximumHistoryAge {
+		drop++
+	}
+
+	if drop == 0 {
+		return
+	}
+
+	// Clear the dropped entries so they can be garbage collected without copying the rest.
+	// append moves the slice to a new array once it runs out of room at the end.
+	clear(entries[:drop])
+	h.entries[name] = entries[drop:]
+}
+
+// get returns the entries for a service newer than since.
+func (h *history) get(name string, since time.Time) []*HistoryEntry {
+	h.RLock()
+	defer h.RUnlock()
+
+	entries := []*HistoryEntry{}
+
+	for _, entry := range h.entries[name] {
+		if entry.Time.After(since) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// uptime calculates the uptime percentages for a service.
+func (h *history) uptime(name string, now time.Time) (map[string]float64, map[string]int) {
+	h.RLock()
+	defer h.RUnlock()
+
+	uptime := make(map[string]float64)
+	checks := make(map[string]int)
+
+	for _, window := range uptimeWindows {
+		up, total := 0, 0
+
+		for _, entry := range h.entries[name] {
+			if now.Sub(entry.Time) > window.dur {
+				continue
+			}
+
+			switch entry.State {
+			case StateOK, StateWarning:
+				up++
+				total++
+			case StateCritical:
+				total++
+			case StateUnknown:
+			}
+		}
+
+		if total > 0 {
+			uptime[window.name] = float64(up) / float64(total) * 100 //nolint:gomnd
+			checks[window.name] = total
+		}
+	}
+
+	return uptime, checks
+}
+
+// startHistory loads the history file if one is configured.
+func (c *Config) startHistory() {
+	if c.HistoryFile == "" {
+		c.history = nil
+		return
+	}
+
+	c.history = newHistory(c.HistoryFile, c.HistorySize, c.services)
+	if err := c.history.load(); err != nil {
+		c.Errorf("Service check history: %v", err)
+	}
+}
+
+// saveHistory writes the history file if one is configured.
+func (c *Config) saveHistory() {
+	if c.history == nil {
+		return
+	}
+
+	if err := c.history.save(); err != nil {
+		c.Errorf("Service check history: %v", err)
+	}
+}
+
+// GetHistory returns the stored check results for a service newer than since.
+func (c *Config) GetHistory(name string, since time.Time) ([]*HistoryEntry, error) {
+	if c.history == nil {
+		return nil, ErrNoHistory
+	} else if _, ok := c.services[name]; !ok {
+		return nil, fmt.Errorf("%w: service '%s' not found", ErrNoName, name)
+	}
+
+	return c.history.get(name, since), nil
+}
+
+// GetUptime returns uptime percentages for the provided services, or all services if none are provided.
+func (c *Config) GetUptime(names ...string) ([]*Uptime, error) {
+	if c.history == nil {
+		return nil, ErrNoHistory
+	}
+
+	if len(names) == 0 {
+		names = c.serviceNames()
+	}
+
+	now := time.Now()
+	uptimes := make([]*Uptime, len(names))
+
+	for idx, name := range names {
+		svc, ok := c.services[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: service '%s' not found", ErrNoName, name)
+		}
+
+		uptimes[idx] = &Uptime{Name: name, State: svc.copyResults().State}
+		uptimes[idx].Uptime, uptimes[idx].Checks = c.history.uptime(name, now)
+	}
+
+	return uptimes, nil
+}
+
+// @Description  Returns locally stored service check results for one service.
+// @Description  Requires a history_file in the services config.
+// @Summary      Get service check history.
+// @Tags         Services
+// @Produce      json
+// @Param        service  path   string  true  "Service name"
+// @Param        hours    query  int     false "Hours of history to return, default 24"
+// @Success      200  {object} apps.Respond.apiResponse{message=[]services.HistoryEntry} "service history"
+// @Failure      404  {object} apps.Respond.apiResponse{message=string} "service not found"
+// @Failure      501  {object} apps.Respond.apiResponse{message=string} "history not enabled"
+// @Router       /api/services/history/{service} [get]
+// @Security     ApiKeyAuth
+func (c *Config) HandleHistory(req *http.Request) (int, interface{}) {
+	hours := 24 //nolint:gomnd
+	if val := req.URL.Query().Get("hours"); val != "" {
+		var err error
+		if hours, err = strconv.Atoi(val); err != nil || hours <= 0 {
+			return http.StatusBadRequest, fmt.Errorf("%w: %s", ErrInvalidHours, val)
+		}
+	}
+
+	entries, err := c.GetHistory(mux.Vars(req)["service"], time.Now().Add(-time.Duration(hours)*time.Hour))
+	if errors.Is(err, ErrNoHistory) {
+		return http.StatusNotImplemented, err
+	} else if err != nil {
+		return http.StatusNotFound, err
+	}
+
+	return http.StatusOK, entries
+}
+
+// @Description  Returns uptime percentages over 24 hours, 7 days and 30 days for all services, or one service.
+// @Description  Requires a history_file in the services config.
+// @Summary      Get service uptime.
+// @Tags         Services
+// @Produce      json
+// @Param        service  path   string  false  "Service name"
+// @Success      200  {object} apps.Respond.apiResponse{message=[]services.Uptime} "service uptimes"
+// @Failure      404  {object} apps.Respond.apiResponse{message=string} "service not found"
+// @Failure      501  {object} apps.Respond.apiResponse{message=string} "history not enabled"
+// @Router       /api/services/uptime/{service} [get]
+// @Security     ApiKeyAuth
+func (c *Config) HandleUptime(req *http.Request) (int, interface{}) {
+	names := []string{}
+	if name := mux.Vars(req)["service"]; name != "" {
+		names = append(names, name)
+	}
+
+	uptimes, err := c.GetUptime(names...)
+	if errors.Is(err, ErrNoHistory) {
+		return http.StatusNotImplemented, err
+	} else if err != nil {
+		return http.StatusNotFound, err
+	}
+
+	return http.StatusOK, uptimes
+}
@@ -53,22 +53,25 @@ func (c *Config) runCheck(svc *Service, force bool) bool {
 }
 
 // runChecks runs checks that are due. Passing true, runs them even if they're not due.
+// Checks run one dependency level at a time, so parents finish before their children start.
 func (c *Config) runChecks(forceAll bool) {
 	if c.checks == nil || c.done == nil {
 		return
 	}
 
-	count := 0
+	for _, level := range c.levels {
+		count := 0
 
-	for s := range c.services {
-		if forceAll || c.services[s].Due() {
-			count++
-			c.checks <- c.services[s]
+		for _, svc := range level {
+			if forceAll || svc.Due() {
+				count++
+				c.checks <- svc
+			}
 		}
-	}
 
-	for ; count > 0; count-- {
-		<-c.done
+		for ; count > 0; count-- {
+			<-c.done
+		}
 	}
 }
 
@@ -100,13 +103,37 @@ func (s *Service) copyResults() *CheckResult {
 		Check:       s.Value,
 		Expect:      s.Expect,
 		IntervalDur: s.Interval.Duration,
-		Metadata:    s.Tags,
+		Metadata:    s.metadata(s.svc.Metadata),
+		DependsOn:   s.DependsOn,
+		Flapping:    s.svc.Flapping,
+		Latency:     s.svc.latency,
+	}
+}
+
+// metadata merges service tags with check result metadata.
+// Tags are returned as-is when there is no extra metadata.
+func (s *Service) metadata(extra map[string]any) map[string]any {
+	if len(extra) == 0 {
+		return s.Tags
 	}
+
+	meta := make(map[string]any, len(s.Tags)+len(extra))
+	for key, val := range extra {
+		meta[key] = val
+	}
+
+	for key, val := range s.Tags {
+		meta[key] = val // tags win.
+	}
+
+	return meta
 }
 
 // SendResults sends a set of Results to Notifiarr.
 func (c *Config) SendResults(results *Results) {
 	results.Interval = c.Interval.Seconds()
+	results.Deps = c.depTree()
+	results.changes = c.stateChanges(results.Svcs)
 
 	c.Website.SendData(&website.Request{
 		Route:      website.SvcRoute,
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/notifier"
+)
+
+// stateChange is used to tell local notifiers about a service changing state.
+type stateChange struct {
+	Name   string
+	From   CheckState
+	To     CheckState
+	Output string
+}
+
+// stateChanges compares results with the last results sent, and returns the services that changed state.
+// The first time a service is seen only Warning and Critical states are returned.
+// SendResults only runs from the service checker go routine, so this needs no lock.
+func (c *Config) stateChanges(results []*CheckResult) []*stateChange {
+	if c.notified == nil {
+		c.notified = make(map[string]CheckState)
+	}
+
+	changes := []*stateChange{}
+
+	for _, svc := range results {
+		last, seen := c.notified[svc.Name]
+		c.notified[svc.Name] = svc.State
+
+		if (seen && last != svc.State) || (!seen && (svc.State == StateWarning || svc.State == StateCritical)) {
+			if !seen {
+				last = StateUnknown
+			}
+
+			changes = append(changes, &stateChange{Name: svc.Name, From: last, To: svc.State, Output: svc.Output})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+
+	return changes
+}
+
+// Notice satisfies the notifier.Noticer interface.
+// Local notifiers only hear about services that changed state.
+func (r *Results) Notice() *notifier.Notice {
+	if len(r.changes) == 0 {
+		return nil
+	}
+
+	notice := &notifier.Notice{Level: notifier.LevelInfo}
+	lines := make([]string, len(r.changes))
+
+	for idx, change := range r.changes {
+		lines[idx] = fmt.Sprintf("%s: %s -> %s: %s", change.Name, change.From, change.To, change.Output)
+
+		switch {
+		case change.To == StateCritical:
+			notice.Level = notifier.LevelCritical
+		case change.To != StateOK && notice.Level < notifier.LevelWarning:
+			notice.Level = notifier.LevelWarning
+		}
+	}
+
+	if len(r.changes) == 1 {
+		notice.Title = fmt.Sprintf("Service %s is %s", r.changes[0].Name, r.changes[0].To)
+	} else {
+		notice.Title = fmt.Sprintf("%d services changed state", len(r.changes))
+	}
+
+	notice.Message = strings.Join(lines, "\n")
+
+	return notice
+}
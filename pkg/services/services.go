@@ -36,6 +36,10 @@ func (c *Config) setup(services []*Service) error {
 	c.services = make(map[string]*Service)
 
 	for idx, check := range services {
+		if check.Type == CheckContainer && c.Plugins != nil {
+			check.containers = c.Plugins.Containers // use the configured socket.
+		}
+
 		if err := services[idx].Validate(); err != nil {
 			return err
 		}
@@ -45,12 +49,14 @@ func (c *Config) setup(services []*Service) error {
 		mnd.ServiceChecks.Add(check.Name+"&&"+StateOK.String(), 0)
 		mnd.ServiceChecks.Add(check.Name+"&&"+StateWarning.String(), 0)
 		mnd.ServiceChecks.Add(check.Name+"&&"+StateCritical.String(), 0)
+		mnd.ServiceChecks.Add(check.Name+"&&Held", 0)
+		mnd.ServiceChecks.Add(check.Name+"&&Flapping", 0)
 
 		// Add this validated service to our service map.
 		c.services[services[idx].Name] = services[idx]
 	}
 
-	return nil
+	return c.setupDepends()
 }
 
 // Start begins the service check routines.
@@ -69,6 +75,7 @@ func (c *Config) Start(ctx context.Context) {
 
 	c.applyLocalOverrides()
 	c.loadServiceStates(ctx)
+	c.startHistory()
 	c.checks = make(chan *Service, DefaultBuffer)
 	c.done = make(chan bool)
 	c.stopChan = make(chan struct{})
@@ -87,7 +94,9 @@ func (c *Config) Start(ctx context.Context) {
 					return
 				}
 
-				c.done <- check.check(ctx)
+				changed := check.check(ctx)
+				c.history.add(check)
+				c.done <- changed
 			}
 		}()
 	}
@@ -158,6 +167,7 @@ func (c *Config) loadServiceStates(ctx context.Context) {
 					c.services[name].svc.State = svc.State
 					c.services[name].svc.Since = svc.Since
 					c.services[name].svc.LastCheck = svc.LastCheck
+					c.services[name].svc.Metadata = svc.Metadata
 				}
 
 				break
@@ -198,6 +208,7 @@ func (c *Config) runServiceChecker() { //nolint:cyclop
 			return
 		case <-ticker.C:
 			c.SendResults(&Results{What: website.EventCron, Svcs: c.GetResults()})
+			c.saveHistory()
 		case event := <-c.checkChan:
 			c.Printf("Running service check '%s' via event: %s, buffer: %d/%d",
 				event.Service.Name, event.Source, len(c.checks), cap(c.checks))
@@ -211,7 +222,11 @@ func (c *Config) runServiceChecker() { //nolint:cyclop
 				continue
 			}
 
-			data, err := json.MarshalIndent(&Results{Svcs: c.GetResults(), Interval: c.Interval.Seconds()}, "", " ")
+			data, err := json.MarshalIndent(&Results{
+				Svcs:     c.GetResults(),
+				Deps:     c.depTree(),
+				Interval: c.Interval.Seconds(),
+			}, "", " ")
 			if err != nil {
 				c.Errorf("Marshalling Service Checks: %v; payload: %s", err, string(data))
 				continue
@@ -243,6 +258,7 @@ func (c *Config) Stop() {
 	defer close(c.stopChan)
 	c.stopChan <- struct{}{}
 	<-c.stopChan // wait for all go routines to die off.
+	c.saveHistory()
 
 	close(c.triggerChan)
 	close(c.checkChan)
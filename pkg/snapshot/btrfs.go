@@ -0,0 +1,183 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// BtrfsConfig enables btrfs device stats and scrub status in snapshots. Linux only.
+type BtrfsConfig struct {
+	Enabled bool `toml:"enabled" xml:"enabled" json:"enabled"`
+}
+
+// BtrfsData is the health of one btrfs filesystem.
+type BtrfsData struct {
+	Mount   string                  `json:"mount"`
+	Devices map[string]*BtrfsDevice `json:"devices"`
+	Scrub   *BtrfsScrub             `json:"scrub,omitempty"`
+}
+
+// BtrfsDevice is the error counters for one device in a btrfs filesystem, from `btrfs device stats`.
+type BtrfsDevice struct {
+	WriteErrs      int64 `json:"writeIoErrs"`
+	ReadErrs       int64 `json:"readIoErrs"`
+	FlushErrs      int64 `json:"flushIoErrs"`
+	CorruptionErrs int64 `json:"corruptionErrs"`
+	GenerationErrs int64 `json:"generationErrs"`
+}
+
+// BtrfsScrub is the last scrub, from `btrfs scrub status`.
+type BtrfsScrub struct {
+	Status        string `json:"status"` // finished, running, aborted, interrupted, or "no stats available".
+	Started       string `json:"started"`
+	Duration      string `json:"duration"`
+	Summary       string `json:"summary"` // "no errors found" or a list of error types.
+	Corrected     int64  `json:"corrected"`
+	Uncorrectable int64  `json:"uncorrectable"`
+}
+
+// getBtrfsData collects device stats and scrub status for every mounted btrfs filesystem.
+func (s *Snapshot) getBtrfsData(ctx context.Context, useSudo bool, config *BtrfsConfig) []error {
+	if config == nil || !config.Enabled || !mnd.IsLinux {
+		return nil
+	}
+
+	partitions, err := disk.PartitionsWithContext(ctx, true)
+	if err != nil {
+		return []error{fmt.Errorf("unable to get partitions: %w", err)}
+	}
+
+	var errs []error
+
+	s.Btrfs = make(map[string]*BtrfsData)
+	seen := make(map[string]bool)
+
+	for _, part := range partitions {
+		// Subvolumes of the same filesystem are mounted from the same device; check each filesystem once.
+		if part.Fstype != "btrfs" || seen[part.Device] {
+			continue
+		}
+
+		seen[part.Device] = true
+		btrfs := &BtrfsData{Mount: part.Mountpoint, Devices: make(map[string]*BtrfsDevice)}
+		s.Btrfs[part.Mountpoint] = btrfs
+
+		if err := btrfs.getDeviceStats(ctx, useSudo); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := btrfs.getScrubStatus(ctx, useSudo); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// getDeviceStats parses this:
+/*
+$ btrfs device stats /mnt/data
+[/dev/sdb].write_io_errs    0
+[/dev/sdb].read_io_errs     0
+[/dev/sdb].flush_io_errs    0
+[/dev/sdb].corruption_errs  3
+[/dev/sdb].generation_errs  0
+*/
+func (b *BtrfsData) getDeviceStats(ctx context.Context, useSudo bool) error {
+	output, err := commandOutput(ctx, useSudo, "btrfs", "device", "stats", b.Mount)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[0], "[") { //nolint:gomnd
+			continue
+		}
+
+		device, stat, found := strings.Cut(strings.TrimPrefix(fields[0], "["), "].")
+		if !found {
+			continue
+		}
+
+		if b.Devices[device] == nil {
+			b.Devices[device] = &BtrfsDevice{}
+		}
+
+		value, _ := strconv.ParseInt(fields[1], mnd.Base10, mnd.Bits64)
+
+		switch stat {
+		case "write_io_errs":
+			b.Devices[device].WriteErrs = value
+		case "read_io_errs":
+			b.Devices[device].ReadErrs = value
+		case "flush_io_errs":
+			b.Devices[device].FlushErrs = value
+		case "corruption_errs":
+			b.Devices[device].CorruptionErrs = value
+		case "generation_errs":
+			b.Devices[device].GenerationErrs = value
+		}
+	}
+
+	return nil
+}
+
+// getScrubStatus parses this (btrfs-progs 5.x and newer):
+/*
+$ btrfs scrub status /mnt/data
+UUID:             8e3b1d5c-5c5c-4a3e-9e7e-1c2f0e9b2a11
+Scrub started:    Sun Oct  1 00:00:01 2023
+Status:           finished
+Duration:         2:03:11
+Total to scrub:   1.50TiB
+Rate:             212.71MiB/s
+Error summary:    csum=2
+  Corrected:      2
+  Uncorrectable:  0
+  Unverified:     0
+A filesystem that was never scrubbed only prints "no stats available".
+*/
+func (b *BtrfsData) getScrubStatus(ctx context.Context, useSudo bool) error {
+	output, err := commandOutput(ctx, useSudo, "btrfs", "scrub", "status", b.Mount)
+	if err != nil {
+		return err
+	}
+
+	b.Scrub = &BtrfsScrub{}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			if strings.Contains(line, "no stats available") {
+				b.Scrub.Status = strings.TrimSpace(line)
+			}
+
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "Scrub started":
+			b.Scrub.Started = value
+		case "Status":
+			b.Scrub.Status = value
+		case "Duration":
+			b.Scrub.Duration = value
+		case "Error summary":
+			b.Scrub.Summary = value
+		case "Corrected":
+			b.Scrub.Corrected, _ = strconv.ParseInt(value, mnd.Base10, mnd.Bits64)
+		case "Uncorrectable":
+			b.Scrub.Uncorrectable, _ = strconv.ParseInt(value, mnd.Base10, mnd.Bits64)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+// ContainerConfig is the [snapshot.containers] section of the config file.
+// Container data is collected automatically if a Docker or Podman socket is found.
+// Podman serves the same API as Docker, so both work the same way.
+type ContainerConfig struct {
+	Socket   string `toml:"socket" xml:"socket" json:"socket"` // found automatically if empty.
+	Disabled bool   `toml:"disabled" xml:"disabled" json:"disabled"`
+}
+
+// Container is the state of one Docker or Podman container.
+type Container struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Image    string    `json:"image"`
+	State    string    `json:"state"`  // created, running, paused, restarting, removing, exited or dead.
+	Status   string    `json:"status"` // state and health, like: running (healthy)
+	Health   string    `json:"health"` // healthy, unhealthy, starting, or empty without a health check.
+	Restarts int       `json:"restarts"`
+	Started  time.Time `json:"started"`
+	ExitCode int       `json:"exitCode"`
+	CPU      float64   `json:"cpuPerc"`
+	MemUsed  uint64    `json:"memUsed"`
+	MemLimit uint64    `json:"memLimit"`
+}
+
+// Container states and health statuses the service check looks for.
+const (
+	ContainerRunning   = "running"
+	ContainerUnhealthy = "unhealthy"
+	ContainerStarting  = "starting"
+)
+
+// Errors returned by the container collector.
+var (
+	ErrNoSocket     = fmt.Errorf("no docker or podman socket found")
+	ErrNoContainer  = fmt.Errorf("container not found")
+	ErrContainerAPI = fmt.Errorf("container api error")
+	// ErrSocketDenied is returned when a socket that was found automatically may not be used by this user.
+	ErrSocketDenied = fmt.Errorf("permission denied on docker or podman socket")
+)
+
+// dockerAPIs are reused for each socket, so every snapshot and service check does not leave idle connections open.
+var (
+	dockerAPIs   = make(map[string]*dockerAPI) //nolint:gochecknoglobals
+	dockerAPIsMu sync.Mutex                    //nolint:gochecknoglobals
+)
+
+// dockerSockets are checked in order when a socket is not configured.
+func dockerSockets() []string {
+	sockets := []string{"/var/run/docker.sock", "/run/podman/podman.sock"}
+
+	if host := os.Getenv("DOCKER_HOST"); strings.HasPrefix(host, "unix://") {
+		sockets = append([]string{strings.TrimPrefix(host, "unix://")}, sockets...)
+	}
+
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "podman", "podman.sock"), filepath.Join(dir, "docker.sock"))
+	}
+
+	return sockets
+}
+
+// socket returns the configured socket, or the first one found.
+func (c *ContainerConfig) socket() (string, error) {
+	if c != nil && c.Socket != "" {
+		return strings.TrimPrefix(c.Socket, "unix://"), nil
+	}
+
+	for _, socket := range dockerSockets() {
+		if _, err := os.Stat(socket); err == nil {
+			return socket, nil
+		}
+	}
+
+	return "", ErrNoSocket
+}
+
+// dockerAPI talks to a Docker compatible API on a unix socket.
+type dockerAPI struct {
+	*http.Client
+	socket string
+}
+
+func newDockerAPI(config *ContainerConfig) (*dockerAPI, error) {
+	if mnd.IsWindows {
+		return nil, ErrPlatformUnsup
+	}
+
+	socket, err := config.socket()
+	if err != nil {
+		return nil, err
+	}
+
+	dockerAPIsMu.Lock()
+	defer dockerAPIsMu.Unlock()
+
+	if api := dockerAPIs[socket]; api != nil {
+		return api, nil
+	}
+
+	dockerAPIs[socket] = &dockerAPI{socket: socket, Client: &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}}
+
+	return dockerAPIs[socket], nil
+}
+
+// get makes a request to the API and decodes the json response.
+func (d *dockerAPI) get(ctx context.Context, path string, output interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := d.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNoContainer
+	default:
+		return fmt.Errorf("%w: %s: %s", ErrContainerAPI, path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+		return fmt.Errorf("decoding %s response: %w", path, err)
+	}
+
+	return nil
+}
+
+// dockerInspect is the part of /containers/{id}/json that we use.
+type dockerInspect struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	RestartCount int    `json:"RestartCount"`
+	Config       struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+	State struct {
+		Status    string    `json:"Status"`
+		ExitCode  int       `json:"ExitCode"`
+		StartedAt time.Time `json:"StartedAt"`
+		Health    *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+}
+
+// dockerStats is the part of /containers/{id}/stats that we use.
+type dockerStats struct {
+	CPUStats    dockerCPU `json:"cpu_stats"`
+	PreCPUStats dockerCPU `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+}
+
+type dockerCPU struct {
+	CPUUsage struct {
+		TotalUsage uint64 `json:"total_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint64 `json:"online_cpus"`
+}
+
+// GetContainers returns every container and its stats. Returns nil if containers are not configured or
+// disabled, or if no socket is configured and none is found. Returns ErrSocketDenied if no socket is configured
+// and the one found may not be used; most systems have a docker socket that only root and the docker group can use.
+func (s *Snapshot) GetContainers(ctx context.Context, config *ContainerConfig) error {
+	if config == nil || config.Disabled {
+		return nil
+	}
+
+	api, err := newDockerAPI(config)
+	if err != nil {
+		if config.Socket != "" {
+			return err
+		}
+
+		return nil // no docker or podman here.
+	}
+
+	var list []struct {
+		ID string `json:"Id"`
+	}
+
+	if err := api.get(ctx, "/containers/json?all=1", &list); errors.Is(err, os.ErrPermission) && config.Socket == "" {
+		return fmt.Errorf("%w: %s: %v", ErrSocketDenied, api.socket, err)
+	} else if err != nil {
+		return fmt.Errorf("listing containers: %w", err)
+	}
+
+	var (
+		containers = make([]*Container, len(list))
+		errs       []string
+		lock       sync.Mutex
+		waitg      sync.WaitGroup
+	)
+
+	// Stats take a second or two each, so get them all at once.
+	for idx := range list {
+		waitg.Add(1)
+
+		go func(idx int) {
+			defer waitg.Done()
+
+			container, err := api.container(ctx, list[idx].ID, true)
+			if err != nil && !errors.Is(err, ErrNoContainer) {
+				lock.Lock()
+				errs = append(errs, err.Error())
+				lock.Unlock()
+			}
+
+			containers[idx] = container
+		}(idx)
+	}
+
+	waitg.Wait()
+
+	for _, container := range containers {
+		if container != nil { // removed since the list was made.
+			s.Containers = append(s.Containers, container)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrContainerAPI, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// GetContainer returns one container by name or ID, without cpu and memory stats.
+// The socket is found automatically if config is nil.
+func GetContainer(ctx context.Context, config *ContainerConfig, name string) (*Container, error) {
+	api, err := newDockerAPI(config)
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := api.container(ctx, name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return container, nil
+}
+
+// container inspects a container, and gets its stats if it's running and stats are requested.
+// The container is returned with what we have even if getting stats fails.
+func (d *dockerAPI) container(ctx context.Context, name string, withStats bool) (*Container, error) {
+	var inspect dockerInspect
+	if err := d.get(ctx, "/containers/"+url.PathEscape(name)+"/json", &inspect); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	container := &Container{
+		ID:       inspect.ID,
+		Name:     strings.TrimPrefix(inspect.Name, "/"),
+		Image:    inspect.Config.Image,
+		State:    inspect.State.Status,
+		Restarts: inspect.RestartCount,
+		Started:  inspect.State.StartedAt,
+		ExitCode: inspect.State.ExitCode,
+	}
+
+	if inspect.State.Health != nil {
+		container.Health = inspect.State.Health.Status
+	}
+
+	container.Status = container.State
+	if container.Health != "" {
+		container.Status += " (" + container.Health + ")"
+	}
+
+	if !withStats || container.State != ContainerRunning {
+		return container, nil
+	}
+
+	var stats dockerStats
+	if err := d.get(ctx, "/containers/"+url.PathEscape(inspect.ID)+"/stats?stream=false", &stats); err != nil {
+		return container, fmt.Errorf("%s stats: %w", container.Name, err)
+	}
+
+	container.CPU = stats.cpuPercent()
+	container.MemLimit = stats.MemoryStats.Limit
+	// Page cache is not counted, like `docker stats` does. cgroup v2 calls it inactive_file, v1 calls it cache.
+	container.MemUsed = stats.MemoryStats.Usage
+
+	if cache, ok := stats.MemoryStats.Stats["inactive_file"]; ok && cache < container.MemUsed {
+		container.MemUsed -= cache
+	} else if cache, ok := stats.MemoryStats.Stats["cache"]; ok && cache < container.MemUsed {
+		container.MemUsed -= cache
+	}
+
+	return container, nil
+}
+
+// cpuPercent is calculated the same way `docker stats` does it. 100% is one full cpu.
+func (d *dockerStats) cpuPercent() float64 {
+	cpuDelta := float64(d.CPUStats.CPUUsage.TotalUsage) - float64(d.PreCPUStats.CPUUsage.TotalUsage)
+	sysDelta := float64(d.CPUStats.SystemUsage) - float64(d.PreCPUStats.SystemUsage)
+
+	if cpuDelta <= 0 || sysDelta <= 0 {
+		return 0
+	}
+
+	return cpuDelta / sysDelta * float64(max(d.CPUStats.OnlineCPUs, 1)) * 100 //nolint:gomnd
+}
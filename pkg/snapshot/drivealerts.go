@@ -0,0 +1,51 @@
+package snapshot
+
+import (
+	"time"
+
+	"golift.io/cnfg"
+)
+
+// Defaults for drive alerts, used when the config does not have them.
+const (
+	DefaultDriveWear     = 90
+	DefaultDriveCooldown = 24 * time.Hour
+)
+
+// DriveAlerts configures warnings when SMART data gets worse between snapshots.
+// Growing reallocated, pending and NVMe media error counts always warn.
+//
+//nolint:lll
+type DriveAlerts struct {
+	Disabled bool          `toml:"disabled" xml:"disabled" json:"disabled"`
+	MaxTemp  int           `toml:"max_temp" xml:"max_temp" json:"maxTemp"`  // celsius; 0 disables.
+	MaxWear  int           `toml:"max_wear" xml:"max_wear" json:"maxWear"`  // NVMe percentage used; 0 disables.
+	Cooldown cnfg.Duration `toml:"cooldown" xml:"cooldown" json:"cooldown"` // how long to wait before repeating a warning.
+	Drives   []*DriveLimit `toml:"drive" xml:"drive" json:"drives"`
+}
+
+// DriveLimit overrides the temperature limit for one drive.
+type DriveLimit struct {
+	Name    string `toml:"name" xml:"name" json:"name"` // device name like /dev/sda, or the serial number.
+	MaxTemp int    `toml:"max_temp" xml:"max_temp" json:"maxTemp"`
+}
+
+// TempLimit returns the temperature limit for a drive. 0 means no limit.
+func (d *DriveAlerts) TempLimit(smart *SmartData) int {
+	for _, drive := range d.Drives {
+		if drive != nil && drive.Name != "" && (drive.Name == smart.Device || drive.Name == smart.Serial) {
+			return drive.MaxTemp
+		}
+	}
+
+	return d.MaxTemp
+}
+
+// CoolFor returns the configured cooldown, or the default.
+func (d *DriveAlerts) CoolFor() time.Duration {
+	if d.Cooldown.Duration <= 0 {
+		return DefaultDriveCooldown
+	}
+
+	return d.Cooldown.Duration
+}
@@ -0,0 +1,134 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+// LVMConfig enables LVM volume group and thin pool usage in snapshots. Linux only.
+type LVMConfig struct {
+	Enabled bool `toml:"enabled" xml:"enabled" json:"enabled"`
+}
+
+// LVMData is the usage of LVM volume groups and thin pools.
+type LVMData struct {
+	Groups    map[string]*LVMGroup    `json:"volumeGroups"`
+	ThinPools map[string]*LVMThinPool `json:"thinPools"` // keyed by vg/lv.
+}
+
+// LVMGroup is one volume group, from `vgs`.
+type LVMGroup struct {
+	Name    string `json:"name"`
+	Size    uint64 `json:"size"`
+	Free    uint64 `json:"free"`
+	PVs     int    `json:"pvCount"`
+	LVs     int    `json:"lvCount"`
+	Missing int    `json:"missingPvCount"`
+}
+
+// LVMThinPool is one thin pool, from `lvs`. Percents are how full the pool's data and metadata are.
+type LVMThinPool struct {
+	Group       string  `json:"group"`
+	Name        string  `json:"name"`
+	Size        uint64  `json:"size"`
+	DataPercent float64 `json:"dataPercent"`
+	MetaPercent float64 `json:"metadataPercent"`
+}
+
+// lvmReport is the output of vgs and lvs with --reportformat json. Every value is a string.
+type lvmReport struct {
+	Report []map[string][]map[string]string `json:"report"`
+}
+
+func (s *Snapshot) getLVMData(ctx context.Context, useSudo bool, config *LVMConfig) error {
+	if config == nil || !config.Enabled || !mnd.IsLinux {
+		return nil
+	}
+
+	s.LVM = &LVMData{Groups: make(map[string]*LVMGroup), ThinPools: make(map[string]*LVMThinPool)}
+
+	groups, err := lvmCommand(ctx, useSudo, "vgs", "vg", "vg_name,vg_size,vg_free,pv_count,lv_count,vg_missing_pv_count")
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		s.LVM.Groups[group["vg_name"]] = &LVMGroup{
+			Name:    group["vg_name"],
+			Size:    lvmUint(group["vg_size"]),
+			Free:    lvmUint(group["vg_free"]),
+			PVs:     int(lvmUint(group["pv_count"])),
+			LVs:     int(lvmUint(group["lv_count"])),
+			Missing: int(lvmUint(group["vg_missing_pv_count"])),
+		}
+	}
+
+	volumes, err := lvmCommand(ctx, useSudo, "lvs", "lv", "vg_name,lv_name,lv_size,segtype,data_percent,metadata_percent")
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range volumes {
+		if volume["segtype"] != "thin-pool" {
+			continue
+		}
+
+		s.LVM.ThinPools[volume["vg_name"]+"/"+volume["lv_name"]] = &LVMThinPool{
+			Group:       volume["vg_name"],
+			Name:        volume["lv_name"],
+			Size:        lvmUint(volume["lv_size"]),
+			DataPercent: lvmFloat(volume["data_percent"]),
+			MetaPercent: lvmFloat(volume["metadata_percent"]),
+		}
+	}
+
+	return nil
+}
+
+// lvmCommand runs vgs or lvs and returns the rows. The output looks like this:
+/*
+$ vgs --reportformat json --units b --nosuffix -o vg_name,vg_size,vg_free
+  {
+      "report": [
+          {
+              "vg": [
+                  {"vg_name":"vg0", "vg_size":"999649902592", "vg_free":"4194304"}
+              ]
+          }
+      ]
+  }
+*/
+func lvmCommand(ctx context.Context, useSudo bool, run, section, fields string) ([]map[string]string, error) {
+	output, err := commandOutput(ctx, useSudo, run, "--reportformat", "json", "--units", "b", "--nosuffix", "-o", fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var report lvmReport
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("parsing %s output: %w", run, err)
+	}
+
+	var rows []map[string]string
+
+	for _, item := range report.Report {
+		rows = append(rows, item[section]...)
+	}
+
+	return rows, nil
+}
+
+func lvmUint(value string) uint64 {
+	number, _ := strconv.ParseUint(strings.TrimSpace(value), mnd.Base10, mnd.Bits64)
+	return number
+}
+
+func lvmFloat(value string) float64 {
+	number, _ := strconv.ParseFloat(strings.TrimSpace(value), mnd.Bits64)
+	return number
+}
@@ -3,6 +3,7 @@ package snapshot
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"path"
 	"runtime"
@@ -54,6 +55,7 @@ func (s *Snapshot) getDriveData(ctx context.Context, run bool, useSudo bool) (er
 	s.DriveAges = make(map[string]int)
 	s.DriveTemps = make(map[string]int)
 	s.DiskHealth = make(map[string]string)
+	s.Smart = make(map[string]*SmartData)
 
 	for name, dev := range disks {
 		errs = append(errs, s.getDiskData(ctx, name, dev, useSudo))
@@ -126,24 +128,75 @@ func getParts(ctx context.Context, disks map[string]string) error {
 	return nil
 }
 
-//nolint:cyclop
 func (s *Snapshot) getDiskData(ctx context.Context, name, dev string, useSudo bool) error {
-	args := []string{"-AH", name}
+	args := smartArgs(name, dev)
+	if args == nil {
+		return nil
+	}
+
+	smart, err := getSmartJSON(ctx, name, useSudo, args)
+	if errors.Is(err, ErrNoSmartJSON) { // smartctl is older than 7.0.
+		return s.getSmartText(ctx, name, useSudo, args)
+	} else if smart == nil {
+		return err
+	}
+
+	s.Smart[name] = smart
+
+	if smart.Temperature > 0 {
+		s.DriveTemps[name] = smart.Temperature
+	}
+
+	if smart.PowerOnHours > 0 {
+		s.DriveAges[name] = int(smart.PowerOnHours)
+	}
+
+	if smart.Health != "" {
+		s.DiskHealth[name] = smart.Health
+	}
 
+	return err
+}
+
+// smartArgs returns the smartctl arguments for a disk, without the data flags.
+// Returns nil for devices that do not have SMART data.
+func smartArgs(name, dev string) []string {
 	switch {
 	case strings.HasPrefix(name, "/dev/md") || strings.HasPrefix(name, "/dev/ram") ||
 		strings.HasPrefix(name, "/dev/zram") || strings.HasPrefix(name, "/dev/synoboot") ||
 		strings.HasPrefix(name, "/dev/nbd") || strings.HasPrefix(name, "/dev/vda"):
 		return nil
 	case mnd.IsSynology:
-		args = []string{"-d", "sat", "-AH", name}
+		return []string{"-d", "sat", name}
 	case dev != "" && strings.Contains(name, ","):
-		args = []string{"-d", name, "-AH", dev}
+		return []string{"-d", name, dev}
 	case dev != "":
-		args = []string{"-d", dev, "-AH", name}
+		return []string{"-d", dev, name}
+	default:
+		return []string{name}
+	}
+}
+
+// getSmartJSON runs smartctl with json output. smartctl exits non-zero for failing drives,
+// so the exit status is only an error when smartctl could not read the drive.
+func getSmartJSON(ctx context.Context, name string, useSudo bool, args []string) (*SmartData, error) {
+	output, runErr := commandOutput(ctx, useSudo, "smartctl", append([]string{"--json", "-a"}, args...)...)
+
+	smart, err := parseSmartJSON(name, output)
+	if err != nil {
+		return nil, err
+	}
+
+	if smart.ExitStatus&smartExitFatal != 0 {
+		return smart, runErr
 	}
 
-	cmd, stdout, waitg, err := readyCommand(ctx, useSudo, "smartctl", args...)
+	return smart, nil
+}
+
+// getSmartText runs smartctl with text output, for versions without json output.
+func (s *Snapshot) getSmartText(ctx context.Context, name string, useSudo bool, args []string) error {
+	cmd, stdout, waitg, err := readyCommand(ctx, useSudo, "smartctl", append([]string{"-AH"}, args...)...)
 	if err != nil {
 		return err
 	}
@@ -154,6 +207,7 @@ func (s *Snapshot) getDiskData(ctx context.Context, name, dev string, useSudo bo
 }
 
 // scanSmartctl attempts to parse the varying outputs of smartctl disk health, age and temperature.
+// This is only used when smartctl is too old to output json.
 // Some disks seem to output in a completely different format than others, using the same tool.
 //
 //nolint:cyclop
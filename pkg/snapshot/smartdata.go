@@ -0,0 +1,352 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/* This file turns `smartctl --json -a` output into SmartData. smartctl 7.0 added json output.
+ * ATA, NVMe and SCSI drives report their counters in different places; they end up in the same fields.
+ */
+
+// ErrNoSmartJSON is returned when smartctl does not output json; it's probably older than 7.0.
+var ErrNoSmartJSON = fmt.Errorf("smartctl did not return json")
+
+// Health values, the same as the smartctl text output.
+const (
+	smartPassed = "PASSED"
+	smartFailed = "FAILED"
+)
+
+// smartctl exit status bits 0, 1 and 2 mean it could not read the drive. The others describe the drive.
+const smartExitFatal = 0b111
+
+// ATA SMART attribute IDs for the counters we pull out of the attribute table.
+const (
+	ataReallocated       = 5
+	ataReallocEvents     = 196
+	ataPending           = 197
+	ataUncorrectable     = 198
+	ataCRCErrors         = 199
+	ataReportedUncorrect = 187
+	ataCommandTimeout    = 188
+)
+
+// SmartData is the SMART information for one drive.
+type SmartData struct {
+	Device        string `json:"device"`
+	Type          string `json:"type"`     // smartctl device type: sat, nvme, scsi, etc.
+	Protocol      string `json:"protocol"` // ATA, NVMe or SCSI.
+	Model         string `json:"model"`
+	Serial        string `json:"serial"`
+	Firmware      string `json:"firmware"`
+	Capacity      int64  `json:"capacity"`               // bytes.
+	RotationRate  int    `json:"rotationRate,omitempty"` // 0 for solid state.
+	Health        string `json:"health"`                 // PASSED, FAILED, or empty if the drive does not say.
+	ExitStatus    int    `json:"exitStatus"`             // smartctl exit status bit mask.
+	Temperature   int    `json:"temperature"`
+	PowerOnHours  int64  `json:"powerOnHours"`
+	PowerCycles   int64  `json:"powerCycles"`
+	Reallocated   int64  `json:"reallocatedSectors"`   // ATA 5, or the SCSI grown defect list.
+	ReallocEvents int64  `json:"reallocationEvents"`   // ATA 196.
+	Pending       int64  `json:"pendingSectors"`       // ATA 197.
+	Uncorrectable int64  `json:"uncorrectableSectors"` // ATA 198, or SCSI total uncorrected errors.
+	ReportedUncor int64  `json:"reportedUncorrect"`    // ATA 187.
+	CmdTimeouts   int64  `json:"commandTimeouts"`      // ATA 188.
+	CRCErrors     int64  `json:"crcErrors"`            // ATA 199, cable or backplane problems.
+	MediaErrors   int64  `json:"mediaErrors"`          // NVMe.
+	ErrorLogCount int64  `json:"errorLogCount"`        // NVMe error log entries, or the ATA error log count.
+	PercentUsed   int    `json:"percentUsed"`          // NVMe and SCSI endurance used; may exceed 100.
+	Spare         int    `json:"availableSpare"`       // NVMe percent.
+	SpareLimit    int    `json:"availableSpareLimit"`  // NVMe percent.
+	CritWarning   int    `json:"criticalWarning"`      // NVMe bit mask.
+	UnsafeOff     int64  `json:"unsafeShutdowns"`      // NVMe.
+	// Failed lists ATA attributes at or below their threshold, now or in the past.
+	Failed     []string          `json:"failedAttributes,omitempty"`
+	Attributes []*SmartAttribute `json:"attributes,omitempty"`
+	SelfTests  []*SmartSelfTest  `json:"selfTests,omitempty"`
+	Messages   []string          `json:"messages,omitempty"` // smartctl warnings and errors.
+}
+
+// SmartAttribute is one row of the ATA SMART attribute table.
+type SmartAttribute struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Value      int    `json:"value"`
+	Worst      int    `json:"worst"`
+	Threshold  int    `json:"threshold"`
+	WhenFailed string `json:"whenFailed,omitempty"` // now, past or empty.
+	Prefailure bool   `json:"prefailure"`
+	Raw        int64  `json:"raw"`
+	RawString  string `json:"rawString"`
+}
+
+// SmartSelfTest is one entry in the drive's self-test log, newest first.
+type SmartSelfTest struct {
+	Type      string `json:"type"`   // Short offline, Extended offline, etc.
+	Status    string `json:"status"` // Completed without error, etc.
+	Passed    bool   `json:"passed"`
+	Remaining int    `json:"remaining,omitempty"` // percent remaining, when it's running.
+	Hours     int64  `json:"hours"`               // power on hours when it ran.
+	FirstLBA  int64  `json:"firstErrorLba,omitempty"`
+}
+
+// smartctlJSON is the part of `smartctl --json -a` output we use.
+type smartctlJSON struct {
+	JSONFormatVersion []int `json:"json_format_version"`
+	Smartctl          struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Device struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Protocol string `json:"protocol"`
+	} `json:"device"`
+	ModelName       string `json:"model_name"`
+	ScsiModelName   string `json:"scsi_model_name"`
+	SerialNumber    string `json:"serial_number"`
+	FirmwareVersion string `json:"firmware_version"`
+	ScsiRevision    string `json:"scsi_revision"`
+	UserCapacity    struct {
+		Bytes int64 `json:"bytes"`
+	} `json:"user_capacity"`
+	RotationRate int `json:"rotation_rate"`
+	SmartStatus  *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime struct {
+		Hours int64 `json:"hours"`
+	} `json:"power_on_time"`
+	PowerCycleCount    int64 `json:"power_cycle_count"`
+	AtaSmartAttributes struct {
+		Table []struct {
+			ID         int    `json:"id"`
+			Name       string `json:"name"`
+			Value      int    `json:"value"`
+			Worst      int    `json:"worst"`
+			Thresh     int    `json:"thresh"`
+			WhenFailed string `json:"when_failed"`
+			Flags      struct {
+				Prefailure bool `json:"prefailure"`
+			} `json:"flags"`
+			Raw struct {
+				Value  int64  `json:"value"`
+				String string `json:"string"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	AtaSmartErrorLog struct {
+		Summary struct {
+			Count int64 `json:"count"`
+		} `json:"summary"`
+	} `json:"ata_smart_error_log"`
+	AtaSmartSelfTestLog struct {
+		Standard struct {
+			Table []struct {
+				Type struct {
+					String string `json:"string"`
+				} `json:"type"`
+				Status struct {
+					String           string `json:"string"`
+					Passed           *bool  `json:"passed"`
+					RemainingPercent int    `json:"remaining_percent"`
+				} `json:"status"`
+				LifetimeHours int64 `json:"lifetime_hours"`
+				LBA           int64 `json:"lba"`
+			} `json:"table"`
+		} `json:"standard"`
+	} `json:"ata_smart_self_test_log"`
+	NvmeHealth *struct {
+		CriticalWarning         int   `json:"critical_warning"`
+		Temperature             int   `json:"temperature"`
+		AvailableSpare          int   `json:"available_spare"`
+		AvailableSpareThreshold int   `json:"available_spare_threshold"`
+		PercentageUsed          int   `json:"percentage_used"`
+		PowerCycles             int64 `json:"power_cycles"`
+		PowerOnHours            int64 `json:"power_on_hours"`
+		UnsafeShutdowns         int64 `json:"unsafe_shutdowns"`
+		MediaErrors             int64 `json:"media_errors"`
+		NumErrLogEntries        int64 `json:"num_err_log_entries"`
+	} `json:"nvme_smart_health_information_log"`
+	NvmeSelfTestLog struct {
+		Table []struct {
+			SelfTestCode struct {
+				String string `json:"string"`
+			} `json:"self_test_code"`
+			SelfTestResult struct {
+				Value  int    `json:"value"`
+				String string `json:"string"`
+			} `json:"self_test_result"`
+			PowerOnHours int64 `json:"power_on_hours"`
+			LBA          int64 `json:"lba"`
+		} `json:"table"`
+	} `json:"nvme_self_test_log"`
+	ScsiGrownDefectList int64 `json:"scsi_grown_defect_list"`
+	ScsiErrorLog        struct {
+		Read   scsiErrors `json:"read"`
+		Write  scsiErrors `json:"write"`
+		Verify scsiErrors `json:"verify"`
+	} `json:"scsi_error_counter_log"`
+	ScsiPercentUsed int `json:"scsi_percentage_used_endurance_indicator"`
+}
+
+type scsiErrors struct {
+	TotalUncorrected int64 `json:"total_uncorrected_errors"`
+}
+
+// parseSmartJSON turns smartctl json output into SmartData.
+func parseSmartJSON(name string, output []byte) (*SmartData, error) {
+	var raw smartctlJSON
+	if err := json.Unmarshal(output, &raw); err != nil || len(raw.JSONFormatVersion) == 0 {
+		return nil, fmt.Errorf("%s: %w", name, ErrNoSmartJSON)
+	}
+
+	smart := &SmartData{
+		Device:       raw.Device.Name,
+		Type:         raw.Device.Type,
+		Protocol:     raw.Device.Protocol,
+		Model:        raw.ModelName,
+		Serial:       raw.SerialNumber,
+		Firmware:     raw.FirmwareVersion,
+		Capacity:     raw.UserCapacity.Bytes,
+		RotationRate: raw.RotationRate,
+		ExitStatus:   raw.Smartctl.ExitStatus,
+		Temperature:  raw.Temperature.Current,
+		PowerOnHours: raw.PowerOnTime.Hours,
+		PowerCycles:  raw.PowerCycleCount,
+		Reallocated:  raw.ScsiGrownDefectList,
+		PercentUsed:  raw.ScsiPercentUsed,
+		Uncorrectable: raw.ScsiErrorLog.Read.TotalUncorrected +
+			raw.ScsiErrorLog.Write.TotalUncorrected + raw.ScsiErrorLog.Verify.TotalUncorrected,
+		ErrorLogCount: raw.AtaSmartErrorLog.Summary.Count,
+	}
+
+	if raw.SmartStatus != nil && raw.SmartStatus.Passed {
+		smart.Health = smartPassed
+	} else if raw.SmartStatus != nil {
+		smart.Health = smartFailed
+	}
+
+	if smart.Device == "" {
+		smart.Device = name
+	}
+
+	if smart.Model == "" {
+		smart.Model = raw.ScsiModelName
+	}
+
+	if smart.Firmware == "" {
+		smart.Firmware = raw.ScsiRevision
+	}
+
+	for _, msg := range raw.Smartctl.Messages {
+		if msg.Severity != "information" {
+			smart.Messages = append(smart.Messages, msg.String)
+		}
+	}
+
+	smart.ataAttributes(&raw)
+	smart.nvmeHealth(&raw)
+	smart.selfTests(&raw)
+
+	return smart, nil
+}
+
+// ataAttributes copies the ATA attribute table, and pulls the sector and error counters out of it.
+func (s *SmartData) ataAttributes(raw *smartctlJSON) {
+	for _, attr := range raw.AtaSmartAttributes.Table {
+		s.Attributes = append(s.Attributes, &SmartAttribute{
+			ID:         attr.ID,
+			Name:       attr.Name,
+			Value:      attr.Value,
+			Worst:      attr.Worst,
+			Threshold:  attr.Thresh,
+			WhenFailed: attr.WhenFailed,
+			Prefailure: attr.Flags.Prefailure,
+			Raw:        attr.Raw.Value,
+			RawString:  attr.Raw.String,
+		})
+
+		if attr.WhenFailed != "" && attr.WhenFailed != "-" {
+			s.Failed = append(s.Failed, fmt.Sprintf("%s (%s)", attr.Name, attr.WhenFailed))
+		}
+
+		switch attr.ID {
+		case ataReallocated:
+			s.Reallocated = attr.Raw.Value
+		case ataReallocEvents:
+			s.ReallocEvents = attr.Raw.Value
+		case ataPending:
+			s.Pending = attr.Raw.Value
+		case ataUncorrectable:
+			s.Uncorrectable = attr.Raw.Value
+		case ataCRCErrors:
+			s.CRCErrors = attr.Raw.Value
+		case ataReportedUncorrect:
+			s.ReportedUncor = attr.Raw.Value
+		case ataCommandTimeout:
+			s.CmdTimeouts = attr.Raw.Value
+		}
+	}
+}
+
+// nvmeHealth copies the NVMe health log. NVMe drives have no attribute table.
+func (s *SmartData) nvmeHealth(raw *smartctlJSON) {
+	nvme := raw.NvmeHealth
+	if nvme == nil {
+		return
+	}
+
+	s.MediaErrors = nvme.MediaErrors
+	s.ErrorLogCount = nvme.NumErrLogEntries
+	s.PercentUsed = nvme.PercentageUsed
+	s.Spare = nvme.AvailableSpare
+	s.SpareLimit = nvme.AvailableSpareThreshold
+	s.CritWarning = nvme.CriticalWarning
+	s.UnsafeOff = nvme.UnsafeShutdowns
+
+	if s.Temperature == 0 {
+		s.Temperature = nvme.Temperature
+	}
+
+	if s.PowerOnHours == 0 {
+		s.PowerOnHours = nvme.PowerOnHours
+	}
+
+	if s.PowerCycles == 0 {
+		s.PowerCycles = nvme.PowerCycles
+	}
+}
+
+// selfTests copies the ATA or NVMe self-test log.
+func (s *SmartData) selfTests(raw *smartctlJSON) {
+	for _, test := range raw.AtaSmartSelfTestLog.Standard.Table {
+		s.SelfTests = append(s.SelfTests, &SmartSelfTest{
+			Type:   test.Type.String,
+			Status: test.Status.String,
+			// A test that is still running has no passed value.
+			Passed:    test.Status.Passed == nil || *test.Status.Passed,
+			Remaining: test.Status.RemainingPercent,
+			Hours:     test.LifetimeHours,
+			FirstLBA:  test.LBA,
+		})
+	}
+
+	for _, test := range raw.NvmeSelfTestLog.Table {
+		s.SelfTests = append(s.SelfTests, &SmartSelfTest{
+			Type:   test.SelfTestCode.String,
+			Status: test.SelfTestResult.String,
+			// 5, 6 and 7 are failures. The rest are passed or aborted.
+			Passed:   test.SelfTestResult.Value < 5 || test.SelfTestResult.Value > 7, //nolint:gomnd
+			Hours:    test.PowerOnHours,
+			FirstLBA: test.LBA,
+		})
+	}
+}
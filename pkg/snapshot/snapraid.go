@@ -0,0 +1,186 @@
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+// snapraidConfigs are the places snapraid looks for its config file.
+var snapraidConfigs = []string{"/etc/snapraid.conf", "/usr/local/etc/snapraid.conf"} //nolint:gochecknoglobals
+
+var (
+	snapraidErrors   = regexp.MustCompile(`there are (\d+) errors`)
+	snapraidScrubbed = regexp.MustCompile(`oldest block was scrubbed (\d+) days ago`)
+	snapraidPercent  = regexp.MustCompile(`The (\d+)% of the array is not scrubbed`)
+	snapraidSyncing  = regexp.MustCompile(`sync in progress at (\d+)%`)
+)
+
+// SnapRAIDConfig enables snapraid status in snapshots.
+// snapraid diff reads every data disk and spins them up, so it has its own setting.
+type SnapRAIDConfig struct {
+	Enabled bool `toml:"enabled" xml:"enabled" json:"enabled"`
+	Diff    bool `toml:"diff" xml:"diff" json:"diff"`
+}
+
+// SnapRAIDData is a summary of `snapraid status` and `snapraid diff`.
+type SnapRAIDData struct {
+	LastSync    time.Time        `json:"lastSync"` // newest parity file modification time.
+	SyncAge     int64            `json:"syncAgeSeconds"`
+	Errors      int64            `json:"errors"` // parity and data errors found by sync and scrub.
+	Synced      bool             `json:"synced"` // false if the array is not fully synced.
+	Syncing     int              `json:"syncingPercent"`
+	ScrubOldest int              `json:"scrubOldestDays"`
+	NotScrubbed int              `json:"notScrubbedPercent"`
+	Diff        map[string]int64 `json:"diff,omitempty"` // equal, added, removed, updated, moved, copied, restored.
+	Differences bool             `json:"differences"`
+}
+
+func (s *Snapshot) getSnapRAIDData(ctx context.Context, useSudo bool, config *SnapRAIDConfig) []error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+
+	s.SnapRAID = &SnapRAIDData{Synced: true}
+	s.SnapRAID.getLastSync()
+
+	var errs []error
+
+	if err := s.SnapRAID.getStatus(ctx, useSudo); err != nil {
+		errs = append(errs, err)
+	}
+
+	if !config.Diff {
+		return errs
+	}
+
+	s.SnapRAID.Diff = make(map[string]int64)
+	if err := s.SnapRAID.getDiff(ctx, useSudo); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// getLastSync finds the parity files in the snapraid config and uses the newest modification time.
+// Only sync writes parity, so this is when the last sync finished. The config lines look like this:
+/*
+parity /mnt/parity1/snapraid.parity
+2-parity /mnt/parity2/snapraid.2-parity
+z-parity /mnt/parity3/part1.parity,/mnt/parity4/part2.parity
+*/
+func (s *SnapRAIDData) getLastSync() {
+	for _, config := range snapraidConfigs {
+		if file, err := os.Open(config); err == nil {
+			s.readParityTimes(file)
+			file.Close()
+
+			break
+		}
+	}
+
+	if !s.LastSync.IsZero() {
+		s.SyncAge = int64(time.Since(s.LastSync).Seconds())
+	}
+}
+
+func (s *SnapRAIDData) readParityTimes(config io.Reader) {
+	scanner := bufio.NewScanner(config)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasSuffix(fields[0], "parity") { //nolint:gomnd
+			continue
+		}
+
+		for _, path := range strings.Split(strings.Join(fields[1:], " "), ",") {
+			if info, err := os.Stat(strings.TrimSpace(path)); err == nil && info.ModTime().After(s.LastSync) {
+				s.LastSync = info.ModTime()
+			}
+		}
+	}
+}
+
+// getStatus parses the summary at the end of this:
+/*
+$ snapraid status
+...
+The oldest block was scrubbed 45 days ago, the median 2, the newest 0.
+WARNING! The array is NOT fully synced.
+You have a sync in progress at 46%.
+The 42% of the array is not scrubbed.
+DANGER! In the array there are 6 errors!
+*/
+func (s *SnapRAIDData) getStatus(ctx context.Context, useSudo bool) error {
+	output, err := commandOutput(ctx, useSudo, "snapraid", "status")
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if match := snapraidErrors.FindStringSubmatch(line); len(match) > 1 {
+			s.Errors, _ = strconv.ParseInt(match[1], mnd.Base10, mnd.Bits64)
+		}
+
+		if match := snapraidScrubbed.FindStringSubmatch(line); len(match) > 1 {
+			s.ScrubOldest, _ = strconv.Atoi(match[1])
+		}
+
+		if match := snapraidPercent.FindStringSubmatch(line); len(match) > 1 {
+			s.NotScrubbed, _ = strconv.Atoi(match[1])
+		}
+
+		if match := snapraidSyncing.FindStringSubmatch(line); len(match) > 1 {
+			s.Syncing, _ = strconv.Atoi(match[1])
+			s.Synced = false
+		}
+
+		if strings.Contains(line, "NOT fully synced") {
+			s.Synced = false
+		}
+	}
+
+	return nil
+}
+
+// getDiff parses the totals at the end of this. snapraid exits 2 when there are differences:
+/*
+$ snapraid diff
+...
+  241566 equal
+      12 added
+       0 removed
+       3 updated
+       0 moved
+       0 copied
+       0 restored
+There are differences!
+*/
+func (s *SnapRAIDData) getDiff(ctx context.Context, useSudo bool) error {
+	output, err := commandOutput(ctx, useSudo, "snapraid", "diff")
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 { //nolint:gomnd
+			if count, err := strconv.ParseInt(fields[0], mnd.Base10, mnd.Bits64); err == nil {
+				s.Diff[fields[1]] = count
+			}
+		}
+
+		if strings.HasPrefix(line, "There are differences") {
+			s.Differences = true
+		}
+	}
+
+	if _, ok := s.Diff["equal"]; ok {
+		return nil // we have the totals, so the exit code does not matter.
+	}
+
+	return err
+}
@@ -58,8 +58,13 @@ type Config struct {
 
 // Plugins is optional configuration for "plugins".
 type Plugins struct {
-	Nvidia *NvidiaConfig  `toml:"nvidia" xml:"nvidia" json:"nvidia"`
-	MySQL  []*MySQLConfig `toml:"mysql" xml:"mysql" json:"mysql"`
+	Nvidia      *NvidiaConfig    `toml:"nvidia" xml:"nvidia" json:"nvidia"`
+	MySQL       []*MySQLConfig   `toml:"mysql" xml:"mysql" json:"mysql"`
+	DriveAlerts *DriveAlerts     `toml:"drive_alerts" xml:"drive_alerts" json:"driveAlerts"`
+	Containers  *ContainerConfig `toml:"containers" xml:"containers" json:"containers"`
+	Btrfs       *BtrfsConfig     `toml:"btrfs" xml:"btrfs" json:"btrfs"`
+	LVM         *LVMConfig       `toml:"lvm" xml:"lvm" json:"lvm"`
+	SnapRAID    *SnapRAIDConfig  `toml:"snapraid" xml:"snapraid" json:"snapraid"`
 }
 
 // Errors this package generates.
@@ -88,9 +93,15 @@ type Snapshot struct {
 	DriveAges  map[string]int                 `json:"driveAges,omitempty"`
 	DriveTemps map[string]int                 `json:"driveTemps,omitempty"`
 	DiskHealth map[string]string              `json:"driveHealth,omitempty"`
+	Smart      map[string]*SmartData          `json:"smart,omitempty"`
 	DiskUsage  map[string]*Partition          `json:"diskUsage,omitempty"`
 	Quotas     map[string]*Partition          `json:"quotas,omitempty"`
 	ZFSPool    map[string]*Partition          `json:"zfsPools,omitempty"`
+	ZFSHealth  map[string]*ZFSPoolHealth      `json:"zfsHealth,omitempty"`
+	ZFSARC     *ZFSARCStat                    `json:"zfsArc,omitempty"`
+	Btrfs      map[string]*BtrfsData          `json:"btrfs,omitempty"`
+	LVM        *LVMData                       `json:"lvm,omitempty"`
+	SnapRAID   *SnapRAIDData                  `json:"snapraid,omitempty"`
 	IOTop      *IOTopData                     `json:"ioTop,omitempty"`
 	IOStat     *IoStatDisks                   `json:"ioStat,omitempty"`
 	IOStat2    map[string]disk.IOCountersStat `json:"ioStat2,omitempty"`
@@ -98,6 +109,7 @@ type Snapshot struct {
 	MySQL      map[string]*MySQLServerData    `json:"mysql,omitempty"`
 	Nvidia     []*NvidiaOutput                `json:"nvidia,omitempty"`
 	Sensors    []*IPMISensor                  `json:"ipmiSensors"`
+	Containers []*Container                   `json:"containers,omitempty"`
 }
 
 // RaidData contains raid information from mdstat and/or megacli.
@@ -177,6 +189,18 @@ func (c *Config) getSnapshot(ctx context.Context, snap *Snapshot) ([]error, []er
 		debug = append(debug, err...) // these can be noisy, so debug/hide them.
 	}
 
+	if err := snap.getZFSHealth(ctx, c.ZFSPools); len(err) != 0 {
+		errs = append(errs, err...)
+	}
+
+	if err := snap.getBtrfsData(ctx, c.UseSudo, c.Btrfs); len(err) != 0 {
+		errs = append(errs, err...)
+	}
+
+	if err := snap.getSnapRAIDData(ctx, c.UseSudo, c.SnapRAID); len(err) != 0 {
+		errs = append(errs, err...)
+	}
+
 	if err := snap.GetMySQL(ctx, c.Plugins.MySQL, c.MyTop); len(err) != 0 {
 		errs = append(errs, err...)
 	}
@@ -184,6 +208,7 @@ func (c *Config) getSnapshot(ctx context.Context, snap *Snapshot) ([]error, []er
 	errs = append(errs, snap.GetMemoryUsage(ctx))
 	errs = append(errs, snap.getZFSPoolData(ctx, c.ZFSPools))
 	errs = append(errs, snap.getRaidData(ctx, c.UseSudo, c.Raid))
+	errs = append(errs, snap.getLVMData(ctx, c.UseSudo, c.LVM))
 	errs = append(errs, snap.getSystemTemps(ctx))
 	errs = append(errs, snap.getIOTop(ctx, c.UseSudo, c.IOTop))
 	errs = append(errs, snap.getIoStat(ctx, c.DiskUsage && mnd.IsLinux))
@@ -191,6 +216,12 @@ func (c *Config) getSnapshot(ctx context.Context, snap *Snapshot) ([]error, []er
 	errs = append(errs, snap.GetNvidia(ctx, c.Nvidia))
 	errs = append(errs, snap.GetIPMI(ctx, c.IPMI, c.IPMISudo))
 
+	if err := snap.GetContainers(ctx, c.Plugins.Containers); errors.Is(err, ErrSocketDenied) {
+		debug = append(debug, err) // found a socket we cannot use; it was not configured, so do not complain.
+	} else {
+		errs = append(errs, err)
+	}
+
 	return errs, debug
 }
 
@@ -205,9 +236,24 @@ func readyCommand(
 	run string,
 	args ...string,
 ) (*exec.Cmd, *bufio.Scanner, *sync.WaitGroup, error) {
+	cmd, err := makeCommand(ctx, useSudo, run, args...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%s stdout error: %w", cmd.Path, err)
+	}
+
+	return cmd, bufio.NewScanner(stdout), &sync.WaitGroup{}, nil
+}
+
+// makeCommand finds a command, and wraps it in sudo if requested.
+func makeCommand(ctx context.Context, useSudo bool, run string, args ...string) (*exec.Cmd, error) {
 	cmdPath, err := exec.LookPath(run)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("%s missing! %w", run, err)
+		return nil, fmt.Errorf("%s missing! %w", run, err)
 	}
 
 	if args == nil { // avoid nil pointer deref.
@@ -218,19 +264,33 @@ func readyCommand(
 		args = append([]string{"-n", cmdPath}, args...)
 
 		if cmdPath, err = exec.LookPath("sudo"); err != nil {
-			return nil, nil, nil, fmt.Errorf("sudo missing! %w", err)
+			return nil, fmt.Errorf("sudo missing! %w", err)
 		}
 	}
 
 	cmd := exec.CommandContext(ctx, cmdPath, args...)
 	sysCallSettings(cmd)
 
-	stdout, err := cmd.StdoutPipe()
+	return cmd, nil
+}
+
+// commandOutput runs a command and returns everything it wrote to stdout.
+// The output is returned even if the command exits non-zero.
+func commandOutput(ctx context.Context, useSudo bool, run string, args ...string) ([]byte, error) {
+	cmd, err := makeCommand(ctx, useSudo, run, args...)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("%s stdout error: %w", cmdPath, err)
+		return nil, err
 	}
 
-	return cmd, bufio.NewScanner(stdout), &sync.WaitGroup{}, nil
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), fmt.Errorf("%v %w: %s", cmd.Args, err, stderr)
+	}
+
+	return stdout.Bytes(), nil
 }
 
 // runCommand executes the readied command and waits for the output loop to finish.
@@ -0,0 +1,333 @@
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+)
+
+// ZFSOnline is the only healthy pool state. Anything else means the pool lost redundancy, or worse.
+const ZFSOnline = "ONLINE"
+
+// ZFS scan states.
+const (
+	ZFSScanNone     = "none"
+	ZFSScanRunning  = "in progress"
+	ZFSScanFinished = "finished"
+	ZFSScanCanceled = "canceled"
+	ZFSScanPaused   = "paused"
+)
+
+// zpool status prints dates like this: Sun Oct  8 02:27:12 2023.
+const zpoolDate = "Mon Jan _2 15:04:05 2006"
+
+// arcstats is where Linux keeps ZFS ARC counters.
+const arcstats = "/proc/spl/kstat/zfs/arcstats"
+
+var (
+	zpoolScanDone = regexp.MustCompile(`with (\d+) errors on (.+)$`)
+	zpoolScanFrom = regexp.MustCompile(`(?:since|on) (.+)$`)
+	zpoolProgress = regexp.MustCompile(`([\d.]+)% done`)
+)
+
+// ZFSPoolHealth is the state of a pool, from `zpool status`.
+type ZFSPoolHealth struct {
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	Status     string     `json:"status,omitempty"` // zfs explains what is wrong here.
+	Action     string     `json:"action,omitempty"` // and what to do about it here.
+	Scan       *ZFSScan   `json:"scan"`
+	DataErrors int64      `json:"dataErrors"` // permanent errors in files.
+	Vdevs      []*ZFSVdev `json:"vdevs"`
+	Errors     string     `json:"errors"`
+	Checked    time.Time  `json:"checked"`
+}
+
+// ZFSScan is the last, or current, scrub or resilver.
+type ZFSScan struct {
+	Type     string    `json:"type"`  // scrub or resilver.
+	State    string    `json:"state"` // none, in progress, finished, canceled or paused.
+	Errors   int64     `json:"errors"`
+	Date     time.Time `json:"date"`     // when it finished, or when the running scan started.
+	Progress float64   `json:"progress"` // percent done while in progress.
+	Line     string    `json:"line"`
+}
+
+// ZFSVdev is one line of the pool config: the pool itself, a vdev, a disk, or a group like logs or spares.
+type ZFSVdev struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Depth    int    `json:"depth"` // 0 is the pool; vdevs are 1 and their disks 2.
+	Read     int64  `json:"read"`
+	Write    int64  `json:"write"`
+	Checksum int64  `json:"checksum"`
+	Note     string `json:"note,omitempty"` // like "too many errors" or "(resilvering)".
+}
+
+// ZFSARCStat is the hit rate of the ZFS cache.
+type ZFSARCStat struct {
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hitRatio"`
+	Size     uint64  `json:"size"`
+	MaxSize  uint64  `json:"maxSize"`
+	L2Hits   uint64  `json:"l2Hits"`
+	L2Misses uint64  `json:"l2Misses"`
+	L2Size   uint64  `json:"l2Size"`
+}
+
+// VdevErrors returns the total read, write and checksum errors on every vdev.
+func (z *ZFSPoolHealth) VdevErrors() int64 {
+	var count int64
+
+	for _, vdev := range z.Vdevs {
+		count += vdev.Read + vdev.Write + vdev.Checksum
+	}
+
+	return count
+}
+
+func (s *Snapshot) getZFSHealth(ctx context.Context, pools []string) []error {
+	if len(pools) == 0 {
+		return nil
+	}
+
+	var errs []error
+
+	s.ZFSHealth = make(map[string]*ZFSPoolHealth)
+
+	for _, pool := range pools {
+		health, err := GetZFSPoolHealth(ctx, pool)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s.ZFSHealth[pool] = health
+	}
+
+	if mnd.IsLinux {
+		if arc, err := getZFSARC(); err == nil { // no arcstats means no zfs module.
+			s.ZFSARC = arc
+		}
+	}
+
+	return errs
+}
+
+// GetZFSPoolHealth runs `zpool status` for one pool and parses it.
+func GetZFSPoolHealth(ctx context.Context, pool string) (*ZFSPoolHealth, error) {
+	output, err := commandOutput(ctx, false, "zpool", "status", "-p", pool)
+	if err != nil {
+		return nil, err
+	}
+
+	health := parseZpoolStatus(string(output))
+	if health.Name == "" {
+		return nil, fmt.Errorf("%w: zpool status %s: no pool in output", ErrNonZeroExit, pool)
+	}
+
+	return health, nil
+}
+
+// parseZpoolStatus parses this:
+/*
+  pool: tank
+ state: DEGRADED
+status: One or more devices has experienced an unrecoverable error.
+action: Replace the faulted device, or use 'zpool clear' to mark the device repaired.
+  scan: resilver in progress since Sun Oct  8 00:00:00 2023
+	1.23T scanned at 500M/s, 800G issued at 300M/s, 2.50T total
+	400G resilvered, 32.00% done, 01:30:00 to go
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	tank        DEGRADED     0     0     0
+	  raidz2-0  DEGRADED     0     0     0
+	    sda     ONLINE       0     0     0
+	    sdb     FAULTED      3     1     0  too many errors
+	logs
+	  sdc       ONLINE       0     0     0
+
+errors: No known data errors
+*/
+func parseZpoolStatus(output string) *ZFSPoolHealth {
+	var (
+		health  = &ZFSPoolHealth{Scan: &ZFSScan{State: ZFSScanNone}, Checked: time.Now()}
+		section string
+	)
+
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, found := strings.Cut(line, ": "); found && !strings.HasPrefix(line, "\t") {
+			section = strings.TrimSpace(key)
+			value = strings.TrimSpace(value)
+
+			switch section {
+			case "pool":
+				health.Name = value
+			case "state":
+				health.State = value
+			case "status":
+				health.Status = value
+			case "action":
+				health.Action = value
+			case "scan":
+				health.Scan.parse(value)
+			case "errors":
+				health.Errors = value
+				health.DataErrors, _ = strconv.ParseInt(strings.Fields(value + " 0")[0], mnd.Base10, mnd.Bits64)
+			}
+
+			continue
+		}
+
+		if strings.TrimSpace(line) == "config:" {
+			section = "config"
+			continue
+		}
+
+		switch section {
+		case "status", "action":
+			if text := strings.TrimSpace(line); text != "" {
+				if section == "status" {
+					health.Status += " " + text
+				} else {
+					health.Action += " " + text
+				}
+			}
+		case "scan":
+			if match := zpoolProgress.FindStringSubmatch(line); len(match) > 1 {
+				health.Scan.Progress, _ = strconv.ParseFloat(match[1], mnd.Bits64)
+			}
+		case "config":
+			if vdev := parseZpoolVdev(line); vdev != nil {
+				health.Vdevs = append(health.Vdevs, vdev)
+			}
+		}
+	}
+
+	return health
+}
+
+// parse reads the scan line, like one of these:
+// scrub repaired 0B in 02:03:11 with 0 errors on Sun Oct  8 02:27:12 2023
+// resilvered 400G in 05:00:00 with 0 errors on Sun Oct  8 05:00:00 2023
+// scrub in progress since Sun Oct  8 00:00:00 2023
+// scrub canceled on Sun Oct  8 01:00:00 2023
+// none requested.
+func (z *ZFSScan) parse(line string) {
+	z.Line = line
+
+	switch {
+	case strings.HasPrefix(line, "resilver"):
+		z.Type = "resilver"
+	case strings.HasPrefix(line, "scrub"):
+		z.Type = "scrub"
+	default:
+		return // none requested.
+	}
+
+	switch {
+	case strings.Contains(line, "in progress"):
+		z.State = ZFSScanRunning
+	case strings.Contains(line, "canceled"):
+		z.State = ZFSScanCanceled
+	case strings.Contains(line, "paused"):
+		z.State = ZFSScanPaused
+	default:
+		z.State = ZFSScanFinished
+	}
+
+	date := ""
+
+	if match := zpoolScanDone.FindStringSubmatch(line); len(match) > 2 { //nolint:gomnd
+		z.Errors, _ = strconv.ParseInt(match[1], mnd.Base10, mnd.Bits64)
+		date = match[2]
+	} else if match := zpoolScanFrom.FindStringSubmatch(line); len(match) > 1 {
+		date = match[1]
+	}
+
+	z.Date, _ = time.ParseInLocation(zpoolDate, strings.TrimSpace(date), time.Local)
+}
+
+// parseZpoolVdev parses one line of the config section. Returns nil for the header and blank lines.
+func parseZpoolVdev(line string) *ZFSVdev {
+	line = strings.TrimPrefix(line, "\t")
+	fields := strings.Fields(line)
+
+	if len(fields) == 0 || fields[0] == "NAME" {
+		return nil
+	}
+
+	vdev := &ZFSVdev{Name: fields[0], Depth: (len(line) - len(strings.TrimLeft(line, " "))) / 2} //nolint:gomnd
+
+	if len(fields) > 1 {
+		vdev.State = fields[1]
+	}
+
+	if len(fields) > 4 { //nolint:gomnd
+		vdev.Read, _ = strconv.ParseInt(fields[2], mnd.Base10, mnd.Bits64)
+		vdev.Write, _ = strconv.ParseInt(fields[3], mnd.Base10, mnd.Bits64)
+		vdev.Checksum, _ = strconv.ParseInt(fields[4], mnd.Base10, mnd.Bits64)
+		vdev.Note = strings.Join(fields[5:], " ")
+	}
+
+	return vdev
+}
+
+// getZFSARC reads the ARC counters from this, the first two lines are headers:
+/*
+13 1 0x01 147 39984 3456789 123456789
+name                            type data
+hits                            4    123456789
+misses                          4    1234567
+*/
+func getZFSARC() (*ZFSARCStat, error) {
+	file, err := os.Open(arcstats)
+	if err != nil {
+		return nil, fmt.Errorf("reading zfs arc stats: %w", err)
+	}
+	defer file.Close()
+
+	arc := &ZFSARCStat{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 { //nolint:gomnd
+			continue
+		}
+
+		value, _ := strconv.ParseUint(fields[2], mnd.Base10, mnd.Bits64)
+
+		switch fields[0] {
+		case "hits":
+			arc.Hits = value
+		case "misses":
+			arc.Misses = value
+		case "size":
+			arc.Size = value
+		case "c_max":
+			arc.MaxSize = value
+		case "l2_hits":
+			arc.L2Hits = value
+		case "l2_misses":
+			arc.L2Misses = value
+		case "l2_size":
+			arc.L2Size = value
+		}
+	}
+
+	if total := arc.Hits + arc.Misses; total > 0 {
+		arc.HitRatio = float64(arc.Hits) / float64(total) * 100 //nolint:gomnd
+	}
+
+	return arc, nil
+}
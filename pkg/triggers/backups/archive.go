@@ -0,0 +1,221 @@
+package backups
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"golift.io/starr"
+)
+
+// DefaultArchiveKeep is how many backups per instance are kept when no retention is configured.
+const DefaultArchiveKeep = 7
+
+// sqliteOK is what integrity_check and quick_check return for a healthy database.
+const sqliteOK = "ok"
+
+// ArchiveConfig enables keeping verified-good Starr backups on local disk.
+// Backups are saved to Dir/<app>/<instance>/ after they pass the corruption check.
+// A backup is kept if any retention rule selects it; everything else is deleted.
+type ArchiveConfig struct {
+	Dir        string `json:"dir" toml:"dir" xml:"dir" yaml:"dir"`
+	KeepLast   int    `json:"keepLast" toml:"keep_last" xml:"keep_last" yaml:"keepLast"`         // newest N backups.
+	KeepDaily  int    `json:"keepDaily" toml:"keep_daily" xml:"keep_daily" yaml:"keepDaily"`     // newest backup of the last N days.
+	KeepWeekly int    `json:"keepWeekly" toml:"keep_weekly" xml:"keep_weekly" yaml:"keepWeekly"` // newest backup of the last N weeks.
+}
+
+// Archived is one backup file in the local archive.
+type Archived struct {
+	App  starr.App `json:"app"`
+	Int  int       `json:"instance"`
+	File string    `json:"file"`
+	Size int64     `json:"bytes"`
+	Date time.Time `json:"date"`
+}
+
+// Enabled returns true if backups should be archived.
+func (a *ArchiveConfig) Enabled() bool {
+	return a != nil && a.Dir != ""
+}
+
+// instanceDir is where backups for one app instance are archived.
+func (a *ArchiveConfig) instanceDir(app starr.App, instance int) string {
+	return filepath.Join(a.Dir, strings.ToLower(string(app)), strconv.Itoa(instance))
+}
+
+// archiveBackup copies a verified backup zip into the archive and prunes old backups for the instance.
+// Backups that failed the sqlite integrity or quick check are refused.
+func (c *cmd) archiveBackup(input *genericInstance, backup *Info, localFile string, remote *starr.BackupFile) {
+	if !c.archive.Enabled() {
+		return
+	}
+
+	if backup.Integ != sqliteOK || backup.Quick != sqliteOK {
+		backup.Archive = "refused: database integrity check failed"
+		c.Errorf("[%s requested] Not archiving %s backup file (%d): %s: integrity: %s, quick: %s",
+			input.event, input.name, input.int, remote.Path, backup.Integ, backup.Quick)
+
+		return
+	}
+
+	dir := c.archive.instanceDir(input.name, input.int)
+
+	saved, err := copyBackup(localFile, dir, filepath.Base(remote.Path), remote.Time)
+	if err != nil {
+		backup.Archive = "failed: " + err.Error()
+		c.Errorf("[%s requested] Archiving %s backup file (%d): %v", input.event, input.name, input.int, err)
+
+		return
+	}
+
+	backup.Archive = saved
+	c.Printf("[%s requested] Archived verified %s backup file (%d): %s", input.event, input.name, input.int, saved)
+
+	for _, file := range c.archive.prune(listArchived(input.name, input.int, dir)) {
+		if err := os.Remove(file.File); err != nil {
+			c.Errorf("Removing old archived %s backup (%d): %v", input.name, input.int, err)
+		} else {
+			c.Printf("Removed old archived %s backup (%d): %s", input.name, input.int, file.File)
+		}
+	}
+}
+
+// copyBackup writes the backup into the archive folder with a temp file and rename,
+// so a partial file never appears in the archive. The file's mod time is set to the backup date.
+func copyBackup(localFile, dir, name string, date time.Time) (string, error) {
+	if err := os.MkdirAll(dir, mnd.Mode0750); err != nil {
+		return "", fmt.Errorf("creating archive folder: %w", err)
+	}
+
+	source, err := os.Open(localFile)
+	if err != nil {
+		return "", fmt.Errorf("opening downloaded backup: %w", err)
+	}
+	defer source.Close()
+
+	dest, err := os.CreateTemp(dir, ".tmp_"+name)
+	if err != nil {
+		return "", fmt.Errorf("creating archive file: %w", err)
+	}
+	defer os.Remove(dest.Name()) // only matters if the rename is not reached.
+
+	if _, err := io.Copy(dest, source); err != nil {
+		dest.Close()
+		return "", fmt.Errorf("writing archive file: %w", err)
+	}
+
+	if err := dest.Close(); err != nil {
+		return "", fmt.Errorf("closing archive file: %w", err)
+	}
+
+	saved := filepath.Join(dir, name)
+	if err := os.Rename(dest.Name(), saved); err != nil {
+		return "", fmt.Errorf("renaming archive file: %w", err)
+	}
+
+	if !date.IsZero() {
+		_ = os.Chtimes(saved, date, date)
+	}
+
+	return saved, nil
+}
+
+// listArchived returns the archived backups in a folder, newest first.
+func listArchived(app starr.App, instance int, dir string) []*Archived {
+	entries, _ := os.ReadDir(dir)
+	files := []*Archived{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, &Archived{
+			App:  app,
+			Int:  instance,
+			File: filepath.Join(dir, entry.Name()),
+			Size: info.Size(),
+			Date: info.ModTime().Round(time.Second),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Date.After(files[j].Date) })
+
+	return files
+}
+
+// prune returns the backups that no retention rule keeps. The input must be sorted newest first.
+func (a *ArchiveConfig) prune(files []*Archived) []*Archived {
+	keepLast := a.KeepLast
+	if keepLast <= 0 && a.KeepDaily <= 0 && a.KeepWeekly <= 0 {
+		keepLast = DefaultArchiveKeep
+	}
+
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	remove := []*Archived{}
+
+	for idx, file := range files {
+		keep := idx < keepLast
+
+		if day := file.Date.Format("2006-01-02"); len(days) < a.KeepDaily && !days[day] {
+			days[day] = true
+			keep = true
+		}
+
+		year, num := file.Date.ISOWeek()
+		if week := fmt.Sprint(year, "-", num); len(weeks) < a.KeepWeekly && !weeks[week] {
+			weeks[week] = true
+			keep = true
+		}
+
+		if !keep {
+			remove = append(remove, file)
+		}
+	}
+
+	return remove
+}
+
+// Archived returns all the backups in the local archive, grouped by app and instance, newest first.
+// Returns nil if archiving is not enabled.
+func (a *Action) Archived() []*Archived {
+	if !a.cmd.archive.Enabled() {
+		return nil
+	}
+
+	files := []*Archived{}
+
+	for _, app := range []starr.App{starr.Lidarr, starr.Prowlarr, starr.Radarr, starr.Readarr, starr.Sonarr} {
+		appDir := filepath.Join(a.cmd.archive.Dir, strings.ToLower(string(app)))
+		entries, _ := os.ReadDir(appDir)
+
+		for _, entry := range entries {
+			if instance, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+				files = append(files, listArchived(app, instance, filepath.Join(appDir, entry.Name()))...)
+			}
+		}
+	}
+
+	return files
+}
+
+// ArchiveDir returns the configured archive folder. Empty if archiving is disabled.
+func (a *Action) ArchiveDir() string {
+	if !a.cmd.archive.Enabled() {
+		return ""
+	}
+
+	return a.cmd.archive.Dir
+}
@@ -26,6 +26,8 @@ func (a *Action) Backup(input *common.ActionInput, app starr.App) error {
 		a.cmd.Exec(input, TrigRadarrBackup)
 		a.cmd.Exec(input, TrigReadarrBackup)
 		a.cmd.Exec(input, TrigSonarrBackup)
+		a.cmd.Exec(input, TrigPlexBackup)
+		a.cmd.Exec(input, TrigTautulliBackup)
 	case starr.Lidarr:
 		a.cmd.Exec(input, TrigLidarrBackup)
 	case starr.Prowlarr:
@@ -36,6 +38,10 @@ func (a *Action) Backup(input *common.ActionInput, app starr.App) error {
 		a.cmd.Exec(input, TrigReadarrBackup)
 	case starr.Sonarr:
 		a.cmd.Exec(input, TrigSonarrBackup)
+	case starr.Plex:
+		a.cmd.Exec(input, TrigPlexBackup)
+	case Tautulli:
+		a.cmd.Exec(input, TrigTautulliBackup)
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package backups
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
@@ -18,11 +19,17 @@ type Action struct {
 
 type cmd struct {
 	*common.Config
+	archive  *ArchiveConfig
+	checked  map[string]*Info        // last checked backup for each app instance.
+	restores map[string]*RestoreInfo // last restore test for each app instance.
+	checkMu  sync.Mutex
 	lidarr   map[int]string
 	prowlarr map[int]string
 	radarr   map[int]string
 	readarr  map[int]string
 	sonarr   map[int]string
+	plex     string // last corruption-checked Plex backup.
+	tautulli string // last corruption-checked Tautulli backup.
 }
 
 // Errors returned by this package.
@@ -48,34 +55,54 @@ const (
 	TrigRadarrBackup    common.TriggerName = "Sending Radarr Backup File List to Notifiarr."
 	TrigReadarrBackup   common.TriggerName = "Sending Readarr Backup File List to Notifiarr."
 	TrigSonarrBackup    common.TriggerName = "Sending Sonarr Backup File List to Notifiarr."
+	TrigPlexCorrupt     common.TriggerName = "Checking Plex for database backup corruption."
+	TrigTautulliCorrupt common.TriggerName = "Checking Tautulli for database backup corruption."
+	TrigPlexBackup      common.TriggerName = "Sending Plex Backup File List to Notifiarr."
+	TrigTautulliBackup  common.TriggerName = "Sending Tautulli Backup File List to Notifiarr."
+	TrigLidarrRestore   common.TriggerName = "Testing Lidarr database backup restore."
+	TrigProwlarrRestore common.TriggerName = "Testing Prowlarr database backup restore."
+	TrigRadarrRestore   common.TriggerName = "Testing Radarr database backup restore."
+	TrigReadarrRestore  common.TriggerName = "Testing Readarr database backup restore."
+	TrigSonarrRestore   common.TriggerName = "Testing Sonarr database backup restore."
 )
 
 // Info contains a pile of information about a Starr database (backup).
 // This is the data sent to notifiarr.com.
 type Info struct {
-	App    starr.App `json:"app"`
-	Int    int       `json:"instance"`
-	Name   string    `json:"name"`
-	File   string    `json:"file,omitempty"`
-	Ver    string    `json:"version,omitempty"`
-	Integ  string    `json:"integrity,omitempty"`
-	Quick  string    `json:"quick,omitempty"`
-	Rows   int       `json:"rows,omitempty"`
-	Size   int64     `json:"bytes,omitempty"`
-	Tables int64     `json:"tables,omitempty"`
-	Date   time.Time `json:"date,omitempty"`
+	App       starr.App        `json:"app"`
+	Int       int              `json:"instance"`
+	Name      string           `json:"name"`
+	File      string           `json:"file,omitempty"`
+	Ver       string           `json:"version,omitempty"`
+	Integ     string           `json:"integrity,omitempty"`
+	Quick     string           `json:"quick,omitempty"`
+	Rows      int              `json:"rows,omitempty"`
+	Size      int64            `json:"bytes,omitempty"`
+	Tables    int64            `json:"tables,omitempty"`
+	Date      time.Time        `json:"date,omitempty"`
+	Pages     int64            `json:"pages,omitempty"`
+	FreePages int64            `json:"freePages,omitempty"`
+	Schema    int64            `json:"schemaVersion,omitempty"` // highest migration in VersionInfo.
+	Counts    map[string]int64 `json:"tableRows,omitempty"`     // rows in key tables.
+	Changes   map[string]int64 `json:"tableChanges,omitempty"`  // row changes since the previous backup.
+	Previous  string           `json:"previous,omitempty"`      // previously checked backup file.
+	Warnings  []string         `json:"warnings,omitempty"`
+	// Archive is the local archive path, or the reason the backup was not archived.
+	Archive string `json:"archive,omitempty"`
 }
 
 // genericInstance is used to abstract all starr apps to reusable methods.
 // It's also used in the go file.
 type genericInstance struct {
-	skip  bool
-	event website.EventType
-	last  string      // app.Corrupt
-	name  starr.App   // Lidarr, Radarr, ..
-	cName string      // configured app name
-	int   int         // instance ID: 1, 2, 3...
-	app   interface { // all starr apps satisfy this interface. yay!
+	skip   bool
+	event  website.EventType
+	last   string        // app.Corrupt
+	name   starr.App     // Lidarr, Radarr, ..
+	cName  string        // configured app name
+	int    int           // instance ID: 1, 2, 3...
+	config *starr.Config // only used by restore tests.
+	local  bool          // Plex and Tautulli: the database is opened in place, read only.
+	app    interface {   // all starr apps satisfy this interface. yay!
 		GetBackupFiles() ([]*starr.BackupFile, error)
 		GetBackupFilesContext(ctx context.Context) ([]*starr.BackupFile, error)
 		starr.APIer
@@ -90,16 +117,22 @@ type Payload struct {
 	Files []*starr.BackupFile `json:"backups"`
 }
 
-// New configures the library.
-func New(config *common.Config) *Action {
-	return &Action{cmd: &cmd{
+// New configures the library. The archive config may be nil.
+func New(config *common.Config, archive *ArchiveConfig) *Action {
+	action := &Action{cmd: &cmd{
 		Config:   config,
+		archive:  archive,
+		checked:  make(map[string]*Info),
+		restores: make(map[string]*RestoreInfo),
 		lidarr:   make(map[int]string),
 		prowlarr: make(map[int]string),
 		radarr:   make(map[int]string),
 		readarr:  make(map[int]string),
 		sonarr:   make(map[int]string),
 	}}
+	action.cmd.loadChecked()
+
+	return action
 }
 
 // Create sets up all the triggers.
@@ -115,4 +148,6 @@ func (a *Action) Create() {
 	a.cmd.makeCorruptionTriggersReadarr(ci)
 	a.cmd.makeCorruptionTriggersSonarr(ci)
 	a.cmd.makeCorruptionTriggersProwlarr(ci)
+	a.cmd.makeLocalTriggers()
+	a.cmd.makeRestoreTriggers()
 }
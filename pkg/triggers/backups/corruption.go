@@ -33,6 +33,8 @@ func (a *Action) Corruption(input *common.ActionInput, app starr.App) error {
 		a.cmd.Exec(input, TrigRadarrCorrupt)
 		a.cmd.Exec(input, TrigReadarrCorrupt)
 		a.cmd.Exec(input, TrigSonarrCorrupt)
+		a.cmd.Exec(input, TrigPlexCorrupt)
+		a.cmd.Exec(input, TrigTautulliCorrupt)
 	case starr.Lidarr:
 		a.cmd.Exec(input, TrigLidarrCorrupt)
 	case starr.Prowlarr:
@@ -43,6 +45,10 @@ func (a *Action) Corruption(input *common.ActionInput, app starr.App) error {
 		a.cmd.Exec(input, TrigReadarrCorrupt)
 	case starr.Sonarr:
 		a.cmd.Exec(input, TrigSonarrCorrupt)
+	case starr.Plex:
+		a.cmd.Exec(input, TrigPlexCorrupt)
+	case Tautulli:
+		a.cmd.Exec(input, TrigTautulliCorrupt)
 	}
 
 	return nil
@@ -266,7 +272,7 @@ func (c *cmd) sendAndLogAppCorruption(ctx context.Context, input *genericInstanc
 		return input.last
 	}
 
-	backup, err := c.checkBackupFileCorruption(ctx, input, latest)
+	backup, err := c.checkBackupFileCorruption(ctx, input, fileList[0])
 	if err != nil {
 		c.Errorf("[%s requested] Checking %s Backup File Corruption (%d): %s: %v (last file: %s)",
 			input.event, input.name, input.int, latest, err, input.last)
@@ -278,13 +284,19 @@ func (c *cmd) sendAndLogAppCorruption(ctx context.Context, input *genericInstanc
 	backup.Name = input.cName
 	backup.File = latest
 	backup.Date = fileList[0].Time.Round(time.Second)
+	c.compareBackup(backup)
+
+	for _, warning := range backup.Warnings {
+		c.Errorf("[%s requested] %s Backup DB Check (%d): %s: %s", input.event, input.name, input.int, latest, warning)
+	}
 
 	c.SendData(&website.Request{
 		Route:      website.CorruptRoute,
 		Event:      input.event,
 		LogPayload: true,
-		LogMsg: fmt.Sprintf("%s Backup File Corruption Info (%d): %s: OK: ver:%s, integ:%s, quick:%s, tables:%d, size:%d",
-			input.name, input.int, latest, backup.Ver, backup.Integ, backup.Quick, backup.Tables, backup.Size),
+		LogMsg: fmt.Sprintf("%s Backup File Corruption Info (%d): %s: %s: ver:%s, integ:%s, quick:%s, tables:%d, "+
+			"size:%d, schema:%d, pages:%d, free:%d", input.name, input.int, latest, backup.status(), backup.Ver, backup.Integ,
+			backup.Quick, backup.Tables, backup.Size, backup.Schema, backup.Pages, backup.FreePages),
 		Payload: backup,
 	})
 
@@ -298,8 +310,10 @@ func (c *cmd) sendAndLogAppCorruption(ctx context.Context, input *genericInstanc
 func (c *cmd) checkBackupFileCorruption(
 	ctx context.Context,
 	input *genericInstance,
-	remotePath string,
+	remote *starr.BackupFile,
 ) (*Info, error) {
+	remotePath := remote.Path
+
 	folder, err := os.MkdirTemp("", "notifiarr_tmp_dir")
 	if err != nil {
 		const moreInfo = "click here for help with this: https://notifiarr.wiki/en/Client/Configuration#tmp-not-found"
@@ -330,7 +344,13 @@ func (c *cmd) checkBackupFileCorruption(
 		if path.Ext(filePath) == ".db" {
 			c.Debugf("[%s requested] Checking %s backup sqlite3 file (%d): %s",
 				input.event, input.name, input.int, filePath)
-			return input.checkCorruptSQLite(ctx, filePath)
+
+			backup, err := input.checkCorruptSQLite(ctx, filePath)
+			if err == nil {
+				c.archiveBackup(input, backup, fileName, remote)
+			}
+
+			return backup, err
 		}
 	}
 
@@ -387,7 +407,12 @@ func (c *genericInstance) checkCorruptSQLite(
 		return nil, fmt.Errorf("checking db file: %w", err)
 	}
 
-	conn, err := sql.Open("sqlite", filePath)
+	dsn := filePath
+	if c.local {
+		dsn = readOnlyDSN(filePath)
+	}
+
+	conn, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("opening sqlite DB: %w", err)
 	}
@@ -401,6 +426,7 @@ func (c *genericInstance) checkCorruptSQLite(
 	backup.Ver, _ = c.getSQLLiteRowString(ctx, conn, "select sqlite_version()")
 	backup.Integ, backup.Rows = c.getSQLLiteRowString(ctx, conn, "PRAGMA integrity_check")
 	backup.Quick, _ = c.getSQLLiteRowString(ctx, conn, "PRAGMA quick_check")
+	c.checkSQLiteHealth(ctx, conn, backup)
 
 	return backup, nil
 }
@@ -0,0 +1,121 @@
+package backups
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Notifiarr/notifiarr/pkg/triggers/data"
+	"golift.io/starr"
+)
+
+// A table that loses this fraction of its rows between two backups raises a warning.
+// Tables smaller than shrinkMinRows are ignored; small tables swing too much to be useful.
+const (
+	shrinkWarning = 0.25
+	shrinkMinRows = 100
+)
+
+// keyTables are the tables counted in each app's database.
+// Tables that do not exist in a backup (older or newer schema) are skipped.
+var keyTables = map[starr.App][]string{ //nolint:gochecknoglobals
+	starr.Plex:     {"library_sections", "metadata_items", "media_items", "media_parts", "accounts"},
+	Tautulli:       {"session_history", "users", "library_sections"},
+	starr.Lidarr:   {"Artists", "Albums", "Tracks", "TrackFiles", "History", "Blocklist"},
+	starr.Prowlarr: {"Indexers", "Applications", "History"},
+	starr.Radarr:   {"Movies", "MovieFiles", "History", "Blocklist"},
+	starr.Readarr:  {"Authors", "Books", "BookFiles", "History", "Blocklist"},
+	starr.Sonarr:   {"Series", "Episodes", "EpisodeFiles", "History", "Blocklist"},
+}
+
+// checkSQLiteHealth adds page, schema and table row counts to the backup info.
+func (c *genericInstance) checkSQLiteHealth(ctx context.Context, conn *sql.DB, backup *Info) {
+	backup.Pages = c.getSQLLiteRowInt64(ctx, conn, "PRAGMA page_count")
+	backup.FreePages = c.getSQLLiteRowInt64(ctx, conn, "PRAGMA freelist_count")
+	backup.Schema = c.getSQLLiteRowInt64(ctx, conn, "SELECT MAX(Version) FROM VersionInfo")
+	backup.Counts = make(map[string]int64)
+
+	for _, table := range keyTables[c.name] {
+		exists := c.getSQLLiteRowInt64(ctx, conn, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = '"+table+"'")
+		if exists > 0 {
+			// The table name comes from the list above, not from the database, so this is safe.
+			backup.Counts[table] = c.getSQLLiteRowInt64(ctx, conn, "SELECT count(*) FROM \""+table+"\"")
+		}
+	}
+}
+
+// checkedState is the state file that keeps the last checked backups between restarts.
+const checkedState = "backupCounts"
+
+// loadChecked reads the last checked backup for each instance from the state file.
+func (c *cmd) loadChecked() {
+	if err := data.LoadState(checkedState, &c.checked); err != nil {
+		c.Errorf("Loading previous backup table counts: %v", err)
+	}
+
+	if c.checked == nil { // the file had null in it.
+		c.checked = make(map[string]*Info)
+	}
+}
+
+// compareBackup compares a backup to the last backup checked for the same instance,
+// and saves it for the next comparison. Tables that shrank too much add a warning.
+func (c *cmd) compareBackup(backup *Info) {
+	key := fmt.Sprint(backup.App, backup.Int)
+
+	c.checkMu.Lock()
+	prev := c.checked[key]
+	// Only what the next comparison needs is kept.
+	c.checked[key] = &Info{File: backup.File, Date: backup.Date, Schema: backup.Schema, Counts: backup.Counts}
+	err := data.SaveState(checkedState, c.checked)
+	c.checkMu.Unlock()
+
+	if err != nil {
+		c.Errorf("Saving backup table counts: %v", err)
+	}
+
+	if prev == nil || prev.File == backup.File {
+		return
+	}
+
+	backup.Previous = prev.File
+	backup.Changes = make(map[string]int64)
+
+	tables := make([]string, 0, len(backup.Counts))
+	for table := range backup.Counts {
+		tables = append(tables, table)
+	}
+
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		was, ok := prev.Counts[table]
+		if !ok {
+			continue
+		}
+
+		now := backup.Counts[table]
+		backup.Changes[table] = now - was
+
+		if was >= shrinkMinRows && float64(was-now) >= float64(was)*shrinkWarning {
+			backup.Warnings = append(backup.Warnings, fmt.Sprintf("table %s shrank from %d to %d rows (%.0f%%) since %s",
+				table, was, now, float64(was-now)/float64(was)*100, prev.File)) //nolint:gomnd
+		}
+	}
+
+	if prev.Schema > backup.Schema {
+		backup.Warnings = append(backup.Warnings, fmt.Sprintf("schema version went backward from %d to %d since %s",
+			prev.Schema, backup.Schema, prev.File))
+	}
+}
+
+// status is OK, or the warnings, for the log line.
+func (i *Info) status() string {
+	if len(i.Warnings) == 0 {
+		return "OK"
+	}
+
+	return "WARNING: " + strings.Join(i.Warnings, "; ")
+}
@@ -0,0 +1,263 @@
+package backups
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+	"golift.io/cnfg"
+	"golift.io/starr"
+)
+
+/* Plex and Tautulli write their database backups to local disk, not behind an API.
+   These triggers list and check those backups from a configured backup_path. */
+
+// Tautulli is not a starr app, but this package identifies apps with starr.App.
+const Tautulli starr.App = "Tautulli"
+
+// Plex writes scheduled backups next to the live database with the date appended:
+// com.plexapp.plugins.library.db-2024-03-01. Only dated names match, because the live
+// database's com.plexapp.plugins.library.db-wal and -shm files are in the same folder.
+// Tautulli writes tautulli.backup-20240301120000.sched.db.
+var (
+	plexBackupRe     = regexp.MustCompile(`^com\.plexapp\.plugins\.library\.db-\d{4}-\d{2}-\d{2}$`)
+	tautulliBackupRe = regexp.MustCompile(`^tautulli\.backup-\d+(\.sched)?\.db$`)
+)
+
+// localApp is a Plex or Tautulli config reduced to what the local backup checks need.
+type localApp struct {
+	name    starr.App
+	cName   string
+	dir     string
+	enabled bool
+}
+
+func (c *cmd) plexApp() *localApp {
+	if c.Apps.Plex == nil {
+		return &localApp{name: starr.Plex}
+	}
+
+	return &localApp{
+		name:    starr.Plex,
+		cName:   c.Apps.Plex.ExtraConfig.Name,
+		dir:     c.Apps.Plex.BackupPath,
+		enabled: c.Apps.Plex.Enabled() && c.Apps.Plex.BackupPath != "",
+	}
+}
+
+func (c *cmd) tautulliApp() *localApp {
+	if c.Apps.Tautulli == nil {
+		return &localApp{name: Tautulli}
+	}
+
+	return &localApp{
+		name:    Tautulli,
+		cName:   c.Apps.Tautulli.Name,
+		dir:     c.Apps.Tautulli.BackupPath,
+		enabled: c.Apps.Tautulli.Enabled() && c.Apps.Tautulli.BackupPath != "",
+	}
+}
+
+// isBackup returns true if a file name is a database backup for this app.
+func (l *localApp) isBackup(name string) bool {
+	switch l.name {
+	case starr.Plex:
+		return plexBackupRe.MatchString(name)
+	case Tautulli:
+		return tautulliBackupRe.MatchString(name)
+	default:
+		return false
+	}
+}
+
+// instance returns the generic instance used by the shared sqlite checks.
+// Plex and Tautulli have one instance each; the database is opened in place, read only.
+func (l *localApp) instance(event website.EventType, last string) *genericInstance {
+	return &genericInstance{
+		event: event,
+		last:  last,
+		name:  l.name,
+		cName: l.cName,
+		int:   1,
+		skip:  !l.enabled,
+		local: true,
+	}
+}
+
+// list returns the backup files in the backup folder, newest first.
+func (l *localApp) list() ([]*starr.BackupFile, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup folder: %w", err)
+	}
+
+	files := []*starr.BackupFile{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !l.isBackup(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, &starr.BackupFile{
+			Name: entry.Name(),
+			Path: filepath.Join(l.dir, entry.Name()),
+			Type: "scheduled",
+			Time: info.ModTime().Round(time.Second),
+			Size: info.Size(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Time.After(files[j].Time) })
+
+	for idx, file := range files {
+		file.ID = int64(idx + 1)
+	}
+
+	return files, nil
+}
+
+// readOnlyDSN opens a sqlite database in place without writing journal or wal files next to it.
+func readOnlyDSN(filePath string) string {
+	uri := &url.URL{Scheme: "file", Path: filepath.ToSlash(filePath), RawQuery: "mode=ro&immutable=1"}
+	if !strings.HasPrefix(uri.Path, "/") {
+		uri.Path = "/" + uri.Path // Windows: file:///C:/path
+	}
+
+	return uri.String()
+}
+
+// makeLocalTriggers adds the backup list and corruption triggers for Plex and Tautulli.
+// There is no website setting for these, so they run on the default interval when a backup_path is set.
+func (c *cmd) makeLocalTriggers() {
+	for _, trig := range []struct {
+		app  *localApp
+		name common.TriggerName
+		fn   func(context.Context, *common.ActionInput)
+	}{
+		{c.plexApp(), TrigPlexBackup, c.sendPlexBackups},
+		{c.tautulliApp(), TrigTautulliBackup, c.sendTautulliBackups},
+		{c.plexApp(), TrigPlexCorrupt, c.sendPlexCorruption},
+		{c.tautulliApp(), TrigTautulliCorrupt, c.sendTautulliCorruption},
+	} {
+		action := &common.Action{Name: trig.name, Fn: trig.fn, C: make(chan *common.ActionInput, 1)}
+
+		if trig.app.enabled {
+			randomTime := time.Duration(c.Config.Rand().Intn(randomMinutes))*time.Second +
+				time.Duration(c.Config.Rand().Intn(randomMinutes))*time.Minute
+			action.D = cnfg.Duration{Duration: checkInterval + randomTime}
+		}
+
+		c.Add(action)
+	}
+}
+
+func (c *cmd) sendPlexBackups(_ context.Context, input *common.ActionInput) {
+	c.sendLocalBackups(c.plexApp(), input.Type)
+}
+
+func (c *cmd) sendTautulliBackups(_ context.Context, input *common.ActionInput) {
+	c.sendLocalBackups(c.tautulliApp(), input.Type)
+}
+
+func (c *cmd) sendPlexCorruption(ctx context.Context, input *common.ActionInput) {
+	c.plex = c.sendLocalCorruption(ctx, c.plexApp(), input.Type, c.plex)
+}
+
+func (c *cmd) sendTautulliCorruption(ctx context.Context, input *common.ActionInput) {
+	c.tautulli = c.sendLocalCorruption(ctx, c.tautulliApp(), input.Type, c.tautulli)
+}
+
+func (c *cmd) sendLocalBackups(app *localApp, event website.EventType) {
+	if !app.enabled {
+		return
+	}
+
+	fileList, err := app.list()
+	if err != nil {
+		c.Errorf("[%s requested] Getting %s Backup Files: %s: %v", event, app.name, app.dir, err)
+		return
+	} else if len(fileList) == 0 {
+		c.Printf("[%s requested] %s has no backup files: %s", event, app.name, app.dir)
+		return
+	}
+
+	c.SendData(&website.Request{
+		Route:      website.BackupRoute,
+		Event:      event,
+		LogPayload: true,
+		LogMsg:     fmt.Sprintf("%s Backup File List (1)", app.name),
+		Payload: &Payload{
+			App:   app.name,
+			Int:   1,
+			Name:  app.cName,
+			Files: fileList,
+		},
+	})
+}
+
+// sendLocalCorruption checks the newest backup and returns its path so it is not checked again.
+func (c *cmd) sendLocalCorruption(ctx context.Context, app *localApp, event website.EventType, last string) string {
+	input := app.instance(event, last)
+	if input.skip {
+		return last
+	}
+
+	fileList, err := app.list()
+	if err != nil {
+		c.Errorf("[%s requested] Getting %s Backup Files: %s: %v", event, app.name, app.dir, err)
+		return last
+	} else if len(fileList) == 0 {
+		c.Printf("[%s requested] %s has no backup files: %s", event, app.name, app.dir)
+		return last
+	}
+
+	latest := fileList[0].Path
+	if last == latest {
+		c.Printf("[%s requested] %s Backup DB Check: already checked latest file: %s", event, app.name, latest)
+		return last
+	}
+
+	c.Debugf("[%s requested] Checking %s backup sqlite3 file: %s", event, app.name, latest)
+
+	backup, err := input.checkCorruptSQLite(ctx, latest)
+	if err != nil {
+		c.Errorf("[%s requested] Checking %s Backup File Corruption: %s: %v", event, app.name, latest, err)
+		return last
+	}
+
+	backup.App = app.name
+	backup.Int = 1
+	backup.Name = app.cName
+	backup.File = latest
+	backup.Date = fileList[0].Time
+	c.compareBackup(backup)
+
+	for _, warning := range backup.Warnings {
+		c.Errorf("[%s requested] %s Backup DB Check: %s: %s", event, app.name, latest, warning)
+	}
+
+	c.SendData(&website.Request{
+		Route:      website.CorruptRoute,
+		Event:      event,
+		LogPayload: true,
+		LogMsg: fmt.Sprintf("%s Backup File Corruption Info (1): %s: %s: ver:%s, integ:%s, quick:%s, tables:%d, "+
+			"size:%d, pages:%d, free:%d", app.name, latest, backup.status(), backup.Ver, backup.Integ,
+			backup.Quick, backup.Tables, backup.Size, backup.Pages, backup.FreePages),
+		Payload: backup,
+	})
+
+	return latest
+}
@@ -0,0 +1,378 @@
+package backups
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+	"golift.io/starr"
+	"golift.io/xtractr"
+)
+
+/* Restore tests prove a backup would restore, not just that its database opens.
+   The whole zip is extracted, config.xml is compared to the running instance,
+   and the backup's database schema version is compared to the live app's migration version. */
+
+// apiVersions are the API path prefixes used to get each app's system status.
+var apiVersions = map[starr.App]string{ //nolint:gochecknoglobals
+	starr.Lidarr:   "v1",
+	starr.Prowlarr: "v1",
+	starr.Radarr:   "v3",
+	starr.Readarr:  "v1",
+	starr.Sonarr:   "v3",
+}
+
+// RestoreInfo is the result of a restore test on the newest backup of a Starr instance.
+// This is the data sent to notifiarr.com and displayed in the Web UI.
+type RestoreInfo struct {
+	App        starr.App `json:"app"`
+	Int        int       `json:"instance"`
+	Name       string    `json:"name"`
+	File       string    `json:"file,omitempty"`
+	Date       time.Time `json:"date,omitempty"`
+	Tested     time.Time `json:"tested"`
+	Restorable bool      `json:"restorable"`
+	Reasons    []string  `json:"reasons,omitempty"`  // why the backup is not restorable.
+	Warnings   []string  `json:"warnings,omitempty"` // problems that do not prevent a restore.
+	AppVer     string    `json:"appVersion,omitempty"`
+	AppSchema  int64     `json:"appSchemaVersion,omitempty"` // live app's database migration version.
+	Schema     int64     `json:"schemaVersion,omitempty"`    // backup database's schema version.
+	Files      int       `json:"files"`
+}
+
+// starrConfigXML is the part of a Starr app's config.xml that a restore test checks.
+type starrConfigXML struct {
+	XMLName   xml.Name `xml:"Config"`
+	Port      int      `xml:"Port"`
+	SslPort   int      `xml:"SslPort"`
+	EnableSsl bool     `xml:"EnableSsl"`
+	APIKey    string   `xml:"ApiKey"`
+	URLBase   string   `xml:"UrlBase"`
+}
+
+// Restore initializes a restore test for all instances of the provided app.
+func (a *Action) Restore(input *common.ActionInput, app starr.App) error {
+	switch app {
+	default:
+		return fmt.Errorf("%w: %s", common.ErrInvalidApp, app)
+	case "":
+		return fmt.Errorf("%w: <no app provided>", common.ErrInvalidApp)
+	case "All":
+		a.cmd.Exec(input, TrigLidarrRestore)
+		a.cmd.Exec(input, TrigProwlarrRestore)
+		a.cmd.Exec(input, TrigRadarrRestore)
+		a.cmd.Exec(input, TrigReadarrRestore)
+		a.cmd.Exec(input, TrigSonarrRestore)
+	case starr.Lidarr:
+		a.cmd.Exec(input, TrigLidarrRestore)
+	case starr.Prowlarr:
+		a.cmd.Exec(input, TrigProwlarrRestore)
+	case starr.Radarr:
+		a.cmd.Exec(input, TrigRadarrRestore)
+	case starr.Readarr:
+		a.cmd.Exec(input, TrigReadarrRestore)
+	case starr.Sonarr:
+		a.cmd.Exec(input, TrigSonarrRestore)
+	}
+
+	return nil
+}
+
+// RestoreResults returns the latest restore test for each instance, sorted by app and instance.
+func (a *Action) RestoreResults() []*RestoreInfo {
+	a.cmd.checkMu.Lock()
+	defer a.cmd.checkMu.Unlock()
+
+	results := make([]*RestoreInfo, 0, len(a.cmd.restores))
+	for _, result := range a.cmd.restores {
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].App == results[j].App {
+			return results[i].Int < results[j].Int
+		}
+
+		return results[i].App < results[j].App
+	})
+
+	return results
+}
+
+// makeRestoreTriggers adds the restore test triggers. They have no timer; they only run when requested.
+func (c *cmd) makeRestoreTriggers() {
+	c.Add(&common.Action{Name: TrigLidarrRestore, Fn: c.sendLidarrRestore, C: make(chan *common.ActionInput, 1)})
+	c.Add(&common.Action{Name: TrigProwlarrRestore, Fn: c.sendProwlarrRestore, C: make(chan *common.ActionInput, 1)})
+	c.Add(&common.Action{Name: TrigRadarrRestore, Fn: c.sendRadarrRestore, C: make(chan *common.ActionInput, 1)})
+	c.Add(&common.Action{Name: TrigReadarrRestore, Fn: c.sendReadarrRestore, C: make(chan *common.ActionInput, 1)})
+	c.Add(&common.Action{Name: TrigSonarrRestore, Fn: c.sendSonarrRestore, C: make(chan *common.ActionInput, 1)})
+}
+
+func (c *cmd) sendLidarrRestore(ctx context.Context, input *common.ActionInput) {
+	for idx, app := range c.Apps.Lidarr {
+		c.sendRestoreTest(ctx, &genericInstance{
+			event:  input.Type,
+			name:   starr.Lidarr,
+			int:    idx + 1,
+			app:    app.Lidarr,
+			cName:  app.Name,
+			skip:   !app.Enabled(),
+			config: app.Config,
+		})
+	}
+}
+
+func (c *cmd) sendProwlarrRestore(ctx context.Context, input *common.ActionInput) {
+	for idx, app := range c.Apps.Prowlarr {
+		c.sendRestoreTest(ctx, &genericInstance{
+			event:  input.Type,
+			name:   starr.Prowlarr,
+			int:    idx + 1,
+			app:    app.Prowlarr,
+			cName:  app.Name,
+			skip:   !app.Enabled(),
+			config: app.Config,
+		})
+	}
+}
+
+func (c *cmd) sendRadarrRestore(ctx context.Context, input *common.ActionInput) {
+	for idx, app := range c.Apps.Radarr {
+		c.sendRestoreTest(ctx, &genericInstance{
+			event:  input.Type,
+			name:   starr.Radarr,
+			int:    idx + 1,
+			app:    app.Radarr,
+			cName:  app.Name,
+			skip:   !app.Enabled(),
+			config: app.Config,
+		})
+	}
+}
+
+func (c *cmd) sendReadarrRestore(ctx context.Context, input *common.ActionInput) {
+	for idx, app := range c.Apps.Readarr {
+		c.sendRestoreTest(ctx, &genericInstance{
+			event:  input.Type,
+			name:   starr.Readarr,
+			int:    idx + 1,
+			app:    app.Readarr,
+			cName:  app.Name,
+			skip:   !app.Enabled(),
+			config: app.Config,
+		})
+	}
+}
+
+func (c *cmd) sendSonarrRestore(ctx context.Context, input *common.ActionInput) {
+	for idx, app := range c.Apps.Sonarr {
+		c.sendRestoreTest(ctx, &genericInstance{
+			event:  input.Type,
+			name:   starr.Sonarr,
+			int:    idx + 1,
+			app:    app.Sonarr,
+			cName:  app.Name,
+			skip:   !app.Enabled(),
+			config: app.Config,
+		})
+	}
+}
+
+// sendRestoreTest tests the newest backup for an instance and sends the verdict to the website.
+func (c *cmd) sendRestoreTest(ctx context.Context, input *genericInstance) {
+	if input.skip {
+		return
+	}
+
+	fileList, err := input.app.GetBackupFilesContext(ctx)
+	if err != nil {
+		c.Errorf("[%s requested] Getting %s Backup Files (%d): %v", input.event, input.name, input.int, err)
+		return
+	} else if len(fileList) == 0 {
+		c.Printf("[%s requested] %s has no backup files to restore test (%d)", input.event, input.name, input.int)
+		return
+	}
+
+	result := &RestoreInfo{
+		App:    input.name,
+		Int:    input.int,
+		Name:   input.cName,
+		File:   fileList[0].Path,
+		Date:   fileList[0].Time.Round(time.Second),
+		Tested: time.Now().Round(time.Second),
+	}
+
+	if err := c.testRestore(ctx, input, result); err != nil {
+		result.Reasons = append(result.Reasons, err.Error())
+	}
+
+	result.Restorable = len(result.Reasons) == 0
+
+	c.checkMu.Lock()
+	c.restores[fmt.Sprint(input.name, input.int)] = result
+	c.checkMu.Unlock()
+
+	verdict := "yes"
+	if !result.Restorable {
+		verdict = "no: " + strings.Join(result.Reasons, "; ")
+	}
+
+	c.SendData(&website.Request{
+		Route:      website.RestoreRoute,
+		Event:      input.event,
+		LogPayload: true,
+		LogMsg: fmt.Sprintf("%s Backup Restore Test (%d): %s: restorable: %s",
+			input.name, input.int, result.File, verdict),
+		Payload: result,
+	})
+}
+
+// testRestore downloads and extracts the whole backup, then checks each part of it.
+// Problems that prevent a restore are added to the result's reasons; the error is for failures running the test.
+func (c *cmd) testRestore(ctx context.Context, input *genericInstance, result *RestoreInfo) error {
+	folder, err := os.MkdirTemp("", "notifiarr_tmp_dir")
+	if err != nil {
+		return fmt.Errorf("creating temporary folder: %w", err)
+	}
+	defer os.RemoveAll(folder) // clean up when we're done.
+
+	fileName, err := input.saveBackupFile(ctx, result.File, folder)
+	if err != nil {
+		return err
+	}
+
+	output := filepath.Join(folder, "restore")
+
+	_, newFiles, err := xtractr.ExtractZIP(&xtractr.XFile{
+		FilePath:  fileName,
+		OutputDir: output,
+		FileMode:  mnd.Mode0600,
+		DirMode:   mnd.Mode0750,
+	})
+	if err != nil {
+		return fmt.Errorf("backup zip file does not extract: %w", err)
+	}
+
+	result.Files = len(newFiles)
+
+	var dbFile, configFile string
+
+	for _, filePath := range newFiles {
+		switch {
+		case path.Ext(filePath) == ".db" && dbFile == "":
+			dbFile = filePath
+		case strings.EqualFold(filepath.Base(filePath), "config.xml"):
+			configFile = filePath
+		}
+	}
+
+	if dbFile == "" {
+		result.Reasons = append(result.Reasons, ErrNoDBInBackup.Error())
+	} else {
+		input.restoreTestDB(ctx, dbFile, result)
+	}
+
+	if configFile == "" {
+		result.Reasons = append(result.Reasons, "no config.xml found in backup")
+	} else {
+		input.restoreTestConfig(configFile, result)
+	}
+
+	input.restoreTestVersion(ctx, result)
+
+	return nil
+}
+
+// restoreTestDB runs the integrity checks and reads the schema version from the backup database.
+func (c *genericInstance) restoreTestDB(ctx context.Context, dbFile string, result *RestoreInfo) {
+	backup, err := c.checkCorruptSQLite(ctx, dbFile)
+	if err != nil {
+		result.Reasons = append(result.Reasons, err.Error())
+		return
+	}
+
+	result.Schema = backup.Schema
+
+	if backup.Integ != sqliteOK {
+		result.Reasons = append(result.Reasons, "database integrity check failed: "+backup.Integ)
+	}
+
+	if backup.Schema < 1 {
+		result.Reasons = append(result.Reasons, "database has no schema version (VersionInfo)")
+	}
+}
+
+// restoreTestConfig makes sure config.xml parses and matches the running instance.
+// A different API key breaks every integration after a restore, so it fails the test.
+// A different port is only a warning because the configured URL may go through a proxy.
+func (c *genericInstance) restoreTestConfig(configFile string, result *RestoreInfo) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		result.Reasons = append(result.Reasons, "reading config.xml: "+err.Error())
+		return
+	}
+
+	var config starrConfigXML
+	if err := xml.Unmarshal(data, &config); err != nil {
+		result.Reasons = append(result.Reasons, "config.xml does not parse: "+err.Error())
+		return
+	}
+
+	if c.config == nil {
+		return
+	}
+
+	if config.APIKey != c.config.APIKey {
+		result.Reasons = append(result.Reasons, "config.xml API key does not match the running instance")
+	}
+
+	uri, err := url.Parse(c.config.URL)
+	if err != nil || uri.Port() == "" {
+		return
+	}
+
+	if uri.Port() != strconv.Itoa(config.Port) && (!config.EnableSsl || uri.Port() != strconv.Itoa(config.SslPort)) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("config.xml port %d does not match the configured url port %s",
+			config.Port, uri.Port()))
+	}
+}
+
+// restoreTestVersion compares the backup database's schema version to the live app's migration version.
+// Starr apps migrate old databases forward, but cannot restore a backup made by a newer schema.
+// The test fails when either version cannot be determined.
+func (c *genericInstance) restoreTestVersion(ctx context.Context, result *RestoreInfo) {
+	var status struct {
+		Version   string `json:"version"`
+		Migration int64  `json:"migrationVersion"`
+	}
+
+	err := c.app.GetInto(ctx, starr.Request{URI: path.Join(apiVersions[c.name], "system", "status")}, &status)
+	if err != nil {
+		result.Reasons = append(result.Reasons, "getting live app version: "+err.Error())
+		return
+	}
+
+	result.AppVer = status.Version
+	result.AppSchema = status.Migration
+
+	switch {
+	case result.Schema < 1:
+		// restoreTestDB already explained why the backup has no schema version.
+	case result.AppSchema < 1:
+		result.Reasons = append(result.Reasons, "live app did not report a database migration version to compare")
+	case result.Schema > result.AppSchema:
+		result.Reasons = append(result.Reasons, fmt.Sprintf("backup schema version %d is newer than the running "+
+			"version %s schema %d", result.Schema, result.AppVer, result.AppSchema))
+	}
+}
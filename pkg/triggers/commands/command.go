@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/notifier"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/hugelgupf/go-shlex"
@@ -26,6 +27,27 @@ const (
 	argSfx = "})"
 )
 
+// Notification is the command output sent to the website when notify is enabled.
+type Notification struct {
+	Name   string `json:"name"`
+	Hash   string `json:"hash"`
+	Output string `json:"output"`
+	Error  string `json:"error"`
+}
+
+// Notice satisfies the notifier.Noticer interface, so local notifiers can send command output.
+func (n *Notification) Notice() *notifier.Notice {
+	if n.Error != "" {
+		return &notifier.Notice{
+			Title:   "Command Failed: " + n.Name,
+			Message: n.Error + "\n" + n.Output,
+			Level:   notifier.LevelCritical,
+		}
+	}
+
+	return &notifier.Notice{Title: "Command Output: " + n.Name, Message: n.Output}
+}
+
 // Setup must run in the creation routine.
 func (c *Command) Setup(logger mnd.Logger, website *website.Server) {
 	if c.Name == "" {
@@ -109,11 +131,11 @@ func (c *Command) RunNow(ctx context.Context, input *common.ActionInput) (string
 		c.website.SendData(&website.Request{
 			Route: website.CommandRoute,
 			Event: input.Type,
-			Payload: map[string]string{
-				"name":   c.Name,
-				"hash":   c.Hash,
-				"output": oStr,
-				"error":  eStr,
+			Payload: &Notification{
+				Name:   c.Name,
+				Hash:   c.Hash,
+				Output: oStr,
+				Error:  eStr,
 			},
 			LogMsg:     fmt.Sprintf("Custom Command '%s' Output (elapsed: %s)", c.Name, elapsed.Round(time.Millisecond)),
 			LogPayload: c.Log,
@@ -165,19 +165,16 @@ func (c *Cmd) sendDashboardState(ctx context.Context, input *common.ActionInput)
 func (c *Cmd) getStates(ctx context.Context) *States {
 	sessions, _ := c.PlexCron.GetSessions(ctx)
 
-	return &States{
-		Deluge:   c.getDelugeStates(ctx),
-		Lidarr:   c.getLidarrStates(ctx),
-		Qbit:     c.getQbitStates(ctx),
-		NZBGet:   c.getNZBGetStates(ctx),
-		RTorrent: c.getRtorrentStates(),
-		Radarr:   c.getRadarrStates(ctx),
-		Readarr:  c.getReadarrStates(ctx),
-		Sonarr:   c.getSonarrStates(ctx),
-		SabNZB:   c.getSabNZBStates(ctx),
-		Xmission: c.getTransmissionStates(ctx),
-		Plex:     sessions,
+	states := &States{
+		Lidarr:  c.getLidarrStates(ctx),
+		Radarr:  c.getRadarrStates(ctx),
+		Readarr: c.getReadarrStates(ctx),
+		Sonarr:  c.getSonarrStates(ctx),
+		Plex:    sessions,
 	}
+	c.getDownloaderStates(ctx, states)
+
+	return states
 }
 
 type dateSorter []*Sortable
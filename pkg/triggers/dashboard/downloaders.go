@@ -0,0 +1,117 @@
+package dashboard
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps"
+)
+
+// getDownloaderStates collects the state of every download client, and adds them to the states.
+func (c *Cmd) getDownloaderStates(ctx context.Context, states *States) {
+	states.Deluge = []*State{}
+	states.NZBGet = []*State{}
+	states.Qbit = []*State{}
+	states.RTorrent = []*State{}
+	states.SabNZB = []*State{}
+	states.Xmission = []*State{}
+
+	for _, client := range c.Apps.DownloadClients() {
+		c.Debugf("Getting %s State: %d:%s", client.App, client.Instance, client.Redacted())
+
+		state := getDownloaderState(ctx, client)
+		if state.Error != "" {
+			c.Errorf("Getting %s Data from %d:%s: %s", client.App, client.Instance, client.Redacted(), state.Error)
+		}
+
+		switch client.App {
+		case apps.AppDeluge:
+			states.Deluge = append(states.Deluge, state)
+		case apps.AppNZBGet:
+			states.NZBGet = append(states.NZBGet, state)
+		case apps.AppQbit:
+			states.Qbit = append(states.Qbit, state)
+		case apps.AppRtorrent:
+			states.RTorrent = append(states.RTorrent, state)
+		case apps.AppSabNZB:
+			states.SabNZB = append(states.SabNZB, state)
+		case apps.AppXmission:
+			states.Xmission = append(states.Xmission, state)
+		}
+	}
+}
+
+//nolint:cyclop
+func getDownloaderState(ctx context.Context, client *apps.DownloadClient) *State {
+	start := time.Now()
+	state := &State{
+		Instance: client.Instance,
+		Name:     client.Name,
+		Next:     []*Sortable{},
+		Latest:   []*Sortable{},
+	}
+
+	transfers, err := client.GetTransfers(ctx)
+	if err != nil {
+		state.Error = "getting transfers: " + err.Error()
+		return state
+	}
+
+	status, err := client.GetStatus(ctx)
+	if err != nil {
+		state.Error = "getting status: " + err.Error()
+		return state
+	}
+
+	state.Elapsed.Duration = time.Since(start)
+	state.Downloads = len(transfers)
+	state.Month = status.Month
+	state.Week = status.Week
+	state.Day = status.Day
+
+	for _, xfer := range transfers {
+		state.Size += xfer.Size
+		state.Uploaded += xfer.Uploaded
+		state.Downloaded += xfer.Downloaded
+
+		if !xfer.Done && !xfer.Eta.IsZero() {
+			state.Next = append(state.Next, &Sortable{Name: xfer.Name, Date: xfer.Eta})
+		} else if !xfer.Completed.IsZero() {
+			state.Latest = append(state.Latest, &Sortable{Name: xfer.Name, Date: xfer.Completed})
+		}
+
+		if !xfer.Done {
+			state.Incomplete++
+		}
+
+		if xfer.UpRate > 0 {
+			state.Uploading++
+		}
+
+		if xfer.State == apps.TransferFailed || xfer.Message != "" {
+			state.Errors++
+		}
+
+		switch xfer.State {
+		case apps.TransferDownloading:
+			state.Downloading++
+		case apps.TransferSeeding:
+			state.Seeding++
+		case apps.TransferPaused:
+			state.Paused++
+		}
+	}
+
+	// Usenet clients report the total size they downloaded. Their history is not all of it.
+	if client.Protocol == apps.ProtocolUsenet && status.Downloaded > 0 {
+		state.Size = status.Downloaded
+	}
+
+	sort.Sort(dateSorter(state.Next))
+	sort.Sort(sort.Reverse(dateSorter(state.Latest)))
+	state.Next.Shrink(showNext)
+	state.Latest.Shrink(showLatest)
+
+	return state
+}
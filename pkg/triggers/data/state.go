@@ -0,0 +1,81 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// stateDir is where state that must survive a restart is written. Empty disables it.
+var (
+	stateDir string     //nolint:gochecknoglobals
+	stateMu  sync.Mutex //nolint:gochecknoglobals
+)
+
+// SetStateDir sets the folder that SaveState and LoadState use.
+// An empty folder keeps state in memory only, like when the app runs without a config file.
+func SetStateDir(dir string) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	stateDir = dir
+}
+
+// SaveState writes data to a json file in the state folder, replacing the previous state with the same name.
+// The data is written to a temporary file first, so a crash cannot truncate the state.
+func SaveState(name string, data interface{}) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if stateDir == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding %s state: %w", name, err)
+	}
+
+	if err := os.MkdirAll(stateDir, 0o750); err != nil { //nolint:gomnd
+		return fmt.Errorf("making state folder: %w", err)
+	}
+
+	file := filepath.Join(stateDir, name+".json")
+	tmpFile := filepath.Join(stateDir, "."+name+".json.tmp")
+
+	if err := os.WriteFile(tmpFile, body, 0o600); err != nil { //nolint:gomnd
+		return fmt.Errorf("writing %s state: %w", name, err)
+	}
+
+	if err := os.Rename(tmpFile, file); err != nil {
+		return fmt.Errorf("renaming %s state: %w", name, err)
+	}
+
+	return nil
+}
+
+// LoadState reads a json file written by SaveState into data. A missing file is not an error.
+func LoadState(name string, data interface{}) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if stateDir == "" {
+		return nil
+	}
+
+	body, err := os.ReadFile(filepath.Join(stateDir, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading %s state: %w", name, err)
+	}
+
+	if err := json.Unmarshal(body, data); err != nil {
+		return fmt.Errorf("decoding %s state: %w", name, err)
+	}
+
+	return nil
+}
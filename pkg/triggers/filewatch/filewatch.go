@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/notifier"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/nxadm/tail"
@@ -70,6 +71,15 @@ type Match struct {
 	Line    string   `json:"line"`
 }
 
+// Notice satisfies the notifier.Noticer interface, so local notifiers can send file matches.
+func (m *Match) Notice() *notifier.Notice {
+	return &notifier.Notice{
+		Title:   "Watched File Match: " + m.File,
+		Message: m.Line,
+		Level:   notifier.LevelWarning,
+	}
+}
+
 // New configures the library.
 func New(config *common.Config, files []*WatchFile, ignored []string) *Action {
 	return &Action{
@@ -142,10 +142,18 @@ func (a *Actions) runTrigger(input *common.ActionInput, trigger, content string)
 		return a.snapshot(input)
 	case "gaps":
 		return a.gaps(input)
+	case "orphans":
+		return a.orphans(input)
+	case "seeding":
+		return a.seeding(input)
+	case "throttle":
+		return a.throttle(input)
 	case "corrupt":
 		return a.corrupt(input, content)
 	case "backup":
 		return a.backup(input, content)
+	case "restore":
+		return a.restore(input, content)
 	case "reload":
 		return a.handleConfigReload()
 	case "notification":
@@ -372,11 +380,58 @@ func (a *Actions) gaps(input *common.ActionInput) (int, string) {
 	return http.StatusOK, "Radarr Collections Gaps initiated."
 }
 
+// @Description  Find downloads in download clients that no Starr app references, and Starr queue items missing from every download client.
+// @Summary      Check for orphaned and ghost downloads
+// @Tags         Triggers
+// @Produce      json
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "success"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/trigger/orphans [get]
+// @Security     ApiKeyAuth
+func (a *Actions) orphans(input *common.ActionInput) (int, string) {
+	a.Orphans.Send(input.Type)
+	return http.StatusOK, "Orphaned download check initiated."
+}
+
+// @Description  Pause or remove finished torrents that reached their seeding goal. Honors the dry_run setting.
+// @Summary      Enforce torrent seeding goals
+// @Tags         Triggers
+// @Produce      json
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "success"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "seeding goals not enabled"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/trigger/seeding [get]
+// @Security     ApiKeyAuth
+func (a *Actions) seeding(input *common.ActionInput) (int, string) {
+	if !a.Seeding.Send(input.Type) {
+		return http.StatusBadRequest, "Seeding goals are not enabled, or a rule is invalid."
+	}
+
+	return http.StatusOK, "Seeding goal enforcement initiated."
+}
+
+// @Description  Check remote Plex streaming bandwidth now, and lower or restore download client speed limits.
+// @Summary      Check Plex bandwidth for download throttling
+// @Tags         Triggers
+// @Produce      json
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "success"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "throttling not enabled"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/trigger/throttle [get]
+// @Security     ApiKeyAuth
+func (a *Actions) throttle(input *common.ActionInput) (int, string) {
+	if !a.Throttle.Send(input.Type) {
+		return http.StatusBadRequest, "Download throttling is not enabled, or its config is invalid."
+	}
+
+	return http.StatusOK, "Plex bandwidth check initiated."
+}
+
 // @Description  Start corruption check on all application backups of a specific type.
 // @Summary      Start app-specific corruption check
 // @Tags         Triggers
 // @Produce      json
-// @Param        app  path   string  true  "app type to check" Enum(lidarr, prowlarr, radarr, readarr, sonarr)
+// @Param        app  path   string  true  "app type to check" Enum(lidarr, prowlarr, radarr, readarr, sonarr, plex, tautulli)
 // @Success      200  {object} apps.Respond.apiResponse{message=string} "success"
 // @Failure      400  {object} apps.Respond.apiResponse{message=string} "missing app"
 // @Failure      404  {object} string "bad token or api key"
@@ -393,11 +448,33 @@ func (a *Actions) corrupt(input *common.ActionInput, content string) (int, strin
 	return http.StatusOK, title.String(content) + " corruption checks initiated."
 }
 
+// @Description  Start a restore test on the newest backup of every application of a specific type.
+// @Description  The backup is extracted, its config.xml and database are checked, and the result is sent to the website.
+// @Summary      Start app-specific backup restore test
+// @Tags         Triggers
+// @Produce      json
+// @Param        app  path   string  true  "app type to test" Enum(lidarr, prowlarr, radarr, readarr, sonarr)
+// @Success      200  {object} apps.Respond.apiResponse{message=string} "success"
+// @Failure      400  {object} apps.Respond.apiResponse{message=string} "missing app"
+// @Failure      404  {object} string "bad token or api key"
+// @Router       /api/trigger/restore/{app} [get]
+// @Security     ApiKeyAuth
+func (a *Actions) restore(input *common.ActionInput, content string) (int, string) {
+	title := cases.Title(language.AmericanEnglish)
+
+	err := a.Backups.Restore(input, starr.App(title.String(content)))
+	if err != nil {
+		return http.StatusBadRequest, "Restore test trigger failed: " + err.Error()
+	}
+
+	return http.StatusOK, title.String(content) + " restore tests initiated."
+}
+
 // @Description  Start backup file check on all applications of a specific type.
 // @Summary      Start app-specific backup check
 // @Tags         Triggers
 // @Produce      json
-// @Param        app  path   string  true  "app type to check" Enum(lidarr, prowlarr, radarr, readarr, sonarr)
+// @Param        app  path   string  true  "app type to check" Enum(lidarr, prowlarr, radarr, readarr, sonarr, plex, tautulli)
 // @Success      200  {object} apps.Respond.apiResponse{message=string} "success"
 // @Failure      400  {object} apps.Respond.apiResponse{message=string} "missing app"
 // @Failure      404  {object} string "bad token or api key"
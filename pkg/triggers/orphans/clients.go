@@ -0,0 +1,37 @@
+package orphans
+
+import "context"
+
+/* This file collects the torrents and NZBs from every configured download client. */
+
+// getClients collects the downloads from all enabled download clients.
+func (c *cmd) getClients(ctx context.Context) *downloads {
+	clients := newDownloads()
+
+	for _, client := range c.Apps.DownloadClients() {
+		transfers, err := client.GetTransfers(ctx)
+
+		for _, xfer := range transfers {
+			download := &Download{
+				App:      string(client.App),
+				Instance: client.Instance,
+				Name:     client.Name,
+				ID:       xfer.ID,
+				Title:    xfer.Name,
+				Category: xfer.Category,
+				Protocol: client.Protocol,
+				Size:     xfer.Size,
+			}
+
+			if xfer.DownloadID != "" {
+				download.ID = xfer.DownloadID
+			}
+
+			clients.add(download)
+		}
+
+		clients.done(client.Protocol, string(client.App), client.Instance, err)
+	}
+
+	return clients
+}
@@ -0,0 +1,219 @@
+package orphans
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+	"golift.io/cnfg"
+)
+
+/* Orphans correlates download clients with Starr queues and history.
+   Orphans are torrents and NZBs in a download client that no Starr instance's queue or history references.
+   Ghosts are Starr queue items whose download ID no longer exists in any download client. */
+
+const TrigOrphans common.TriggerName = "Checking download clients for orphaned and ghost downloads."
+
+const (
+	// This is the max number of queued items to inspect in each Starr instance.
+	queueItemsMax = 1000
+	// This is the max number of history records to inspect in each Starr instance.
+	historyRecordsMax = 5000
+)
+
+// Action contains the exported methods for this package.
+type Action struct {
+	cmd *cmd
+}
+
+type cmd struct {
+	*common.Config
+	last   *Payload
+	lastMu sync.RWMutex
+}
+
+// Download is a torrent or NZB in a download client, or a queue item in a Starr app.
+type Download struct {
+	App      string `json:"app"`
+	Instance int    `json:"instance"`
+	Name     string `json:"name"` // instance name.
+	ID       string `json:"id"`   // download ID: torrent hash or NZB ID.
+	Title    string `json:"title"`
+	Category string `json:"category"`
+	Protocol string `json:"protocol"`
+	Client   string `json:"client,omitempty"` // download client name in the Starr app, for ghosts.
+	Size     int64  `json:"size"`
+}
+
+// Payload is what we send to the website.
+type Payload struct {
+	Orphans    []*Download   `json:"orphans"`
+	Ghosts     []*Download   `json:"ghosts"`
+	OrphanSize int64         `json:"orphanSize"`
+	GhostSize  int64         `json:"ghostSize"`
+	Clients    int           `json:"clients"` // download client instances checked.
+	Starrs     int           `json:"starrs"`  // starr app instances checked.
+	Errors     []string      `json:"errors,omitempty"`
+	Date       time.Time     `json:"date"`
+	Elapsed    cnfg.Duration `json:"elapsed"`
+}
+
+// downloads is what was collected from one side: the download clients or the Starr apps.
+type downloads struct {
+	items     []*Download
+	ids       map[string]struct{} // upper-cased download IDs.
+	instances map[string]int      // protocol -> instances checked.
+	failed    map[string]bool     // protocol -> an instance failed.
+	errors    []string
+}
+
+// New configures the library.
+func New(config *common.Config) *Action {
+	return &Action{cmd: &cmd{Config: config}}
+}
+
+// Create initializes the library. This trigger has no timer; it only runs when requested.
+func (a *Action) Create() {
+	a.cmd.Add(&common.Action{
+		Name: TrigOrphans,
+		Fn:   a.cmd.sendOrphans,
+		C:    make(chan *common.ActionInput, 1),
+	})
+}
+
+// Send orphaned and ghost downloads to the website.
+func (a *Action) Send(event website.EventType) {
+	a.cmd.Exec(&common.ActionInput{Type: event}, TrigOrphans)
+}
+
+// Results returns the result of the last check, or nil if one has not run.
+func (a *Action) Results() *Payload {
+	a.cmd.lastMu.RLock()
+	defer a.cmd.lastMu.RUnlock()
+
+	return a.cmd.last
+}
+
+func newDownloads() *downloads {
+	return &downloads{
+		items:     []*Download{},
+		ids:       make(map[string]struct{}),
+		instances: make(map[string]int),
+		failed:    make(map[string]bool),
+	}
+}
+
+// add saves a download and its ID. Items without an ID are ignored.
+func (d *downloads) add(item *Download) {
+	if item.ID == "" {
+		return
+	}
+
+	d.items = append(d.items, item)
+	d.ids[strings.ToUpper(item.ID)] = struct{}{}
+}
+
+// addID saves a download ID from Starr history; history items are not reported.
+func (d *downloads) addID(id string) {
+	if id != "" {
+		d.ids[strings.ToUpper(id)] = struct{}{}
+	}
+}
+
+// done counts a checked instance, and records its error if it failed.
+func (d *downloads) done(protocol, app string, instance int, err error) {
+	d.instances[protocol]++
+
+	if err != nil {
+		d.failed[protocol] = true
+		d.errors = append(d.errors, fmt.Sprintf("%s %d: %v", app, instance, err))
+	}
+}
+
+func (d *downloads) has(id string) bool {
+	_, ok := d.ids[strings.ToUpper(id)]
+	return ok
+}
+
+func (d *downloads) count() (count int) {
+	for _, instances := range d.instances {
+		count += instances
+	}
+
+	return count
+}
+
+func (c *cmd) sendOrphans(ctx context.Context, input *common.ActionInput) {
+	start := time.Now()
+	clients := c.getClients(ctx)
+	starrs := c.getStarrs(ctx)
+
+	if clients.count() == 0 || starrs.count() == 0 {
+		c.Errorf("[%s requested] Cannot check for orphaned downloads: configured download clients (%d) "+
+			"or Starr apps (%d) are zero.", input.Type, clients.count(), starrs.count())
+		return
+	}
+
+	payload := correlate(clients, starrs)
+	payload.Elapsed.Duration = time.Since(start)
+
+	for _, err := range payload.Errors {
+		c.Errorf("[%s requested] Checking for orphaned downloads: %s", input.Type, err)
+	}
+
+	c.lastMu.Lock()
+	c.last = payload
+	c.lastMu.Unlock()
+
+	c.SendData(&website.Request{
+		Route:      website.OrphanRoute,
+		Event:      input.Type,
+		LogPayload: true,
+		LogMsg: fmt.Sprintf("Orphaned Downloads: %d orphans, %d ghosts; clients:%d, starrs:%d, elapsed:%s",
+			len(payload.Orphans), len(payload.Ghosts), payload.Clients, payload.Starrs, payload.Elapsed),
+		Payload: payload,
+	})
+}
+
+// correlate finds orphans and ghosts. Orphans are only reported when every Starr instance answered,
+// and ghosts are only reported for a protocol when every download client for that protocol answered.
+// Otherwise an instance that did not answer would make everything it knows about look missing.
+func correlate(clients, starrs *downloads) *Payload {
+	payload := &Payload{
+		Orphans: []*Download{},
+		Ghosts:  []*Download{},
+		Clients: clients.count(),
+		Starrs:  starrs.count(),
+		Errors:  append(clients.errors, starrs.errors...),
+		Date:    time.Now(),
+	}
+
+	if len(starrs.failed) == 0 {
+		for _, item := range clients.items {
+			if !starrs.has(item.ID) {
+				payload.Orphans = append(payload.Orphans, item)
+				payload.OrphanSize += item.Size
+			}
+		}
+	}
+
+	for _, item := range starrs.items {
+		protocol := strings.ToLower(item.Protocol)
+		if clients.instances[protocol] == 0 || clients.failed[protocol] || clients.has(item.ID) {
+			continue
+		}
+
+		payload.Ghosts = append(payload.Ghosts, item)
+		payload.GhostSize += item.Size
+	}
+
+	sort.Slice(payload.Orphans, func(i, j int) bool { return payload.Orphans[i].Size > payload.Orphans[j].Size })
+	sort.Slice(payload.Ghosts, func(i, j int) bool { return payload.Ghosts[i].Size > payload.Ghosts[j].Size })
+
+	return payload
+}
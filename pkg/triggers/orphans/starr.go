@@ -0,0 +1,167 @@
+package orphans
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps"
+	"golift.io/starr"
+)
+
+/* This file collects the download IDs from every Starr instance's queue and history.
+   Queue items are kept so they can be reported as ghosts. History only provides IDs. */
+
+// getStarrs collects the queues and history from all enabled Starr apps.
+func (c *cmd) getStarrs(ctx context.Context) *downloads {
+	starrs := newDownloads()
+
+	for idx, app := range c.Apps.Lidarr {
+		if app.Enabled() {
+			starrs.done(string(starr.Lidarr), string(starr.Lidarr), idx+1, getLidarr(ctx, starrs, idx+1, app))
+		}
+	}
+
+	for idx, app := range c.Apps.Radarr {
+		if app.Enabled() {
+			starrs.done(string(starr.Radarr), string(starr.Radarr), idx+1, getRadarr(ctx, starrs, idx+1, app))
+		}
+	}
+
+	for idx, app := range c.Apps.Readarr {
+		if app.Enabled() {
+			starrs.done(string(starr.Readarr), string(starr.Readarr), idx+1, getReadarr(ctx, starrs, idx+1, app))
+		}
+	}
+
+	for idx, app := range c.Apps.Sonarr {
+		if app.Enabled() {
+			starrs.done(string(starr.Sonarr), string(starr.Sonarr), idx+1, getSonarr(ctx, starrs, idx+1, app))
+		}
+	}
+
+	return starrs
+}
+
+func getLidarr(ctx context.Context, starrs *downloads, instance int, app *apps.LidarrConfig) error {
+	queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting queue: %w", err)
+	}
+
+	hist, err := app.GetHistoryContext(ctx, historyRecordsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting history: %w", err)
+	}
+
+	for _, record := range queue.Records {
+		starrs.add(&Download{
+			App:      string(starr.Lidarr),
+			Instance: instance,
+			Name:     app.Name,
+			ID:       record.DownloadID,
+			Title:    record.Title,
+			Protocol: record.Protocol,
+			Client:   record.DownloadClient,
+			Size:     int64(record.Size),
+		})
+	}
+
+	for _, record := range hist.Records {
+		starrs.addID(record.DownloadID)
+	}
+
+	return nil
+}
+
+func getRadarr(ctx context.Context, starrs *downloads, instance int, app *apps.RadarrConfig) error {
+	queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting queue: %w", err)
+	}
+
+	hist, err := app.GetHistoryContext(ctx, historyRecordsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting history: %w", err)
+	}
+
+	for _, record := range queue.Records {
+		starrs.add(&Download{
+			App:      string(starr.Radarr),
+			Instance: instance,
+			Name:     app.Name,
+			ID:       record.DownloadID,
+			Title:    record.Title,
+			Protocol: record.Protocol,
+			Client:   record.DownloadClient,
+			Size:     int64(record.Size),
+		})
+	}
+
+	for _, record := range hist.Records {
+		starrs.addID(record.DownloadID)
+	}
+
+	return nil
+}
+
+func getReadarr(ctx context.Context, starrs *downloads, instance int, app *apps.ReadarrConfig) error {
+	queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting queue: %w", err)
+	}
+
+	hist, err := app.GetHistoryContext(ctx, historyRecordsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting history: %w", err)
+	}
+
+	for _, record := range queue.Records {
+		starrs.add(&Download{
+			App:      string(starr.Readarr),
+			Instance: instance,
+			Name:     app.Name,
+			ID:       record.DownloadID,
+			Title:    record.Title,
+			Protocol: record.Protocol,
+			Client:   record.DownloadClient,
+			Size:     int64(record.Size),
+		})
+	}
+
+	for _, record := range hist.Records {
+		starrs.addID(record.DownloadID)
+	}
+
+	return nil
+}
+
+func getSonarr(ctx context.Context, starrs *downloads, instance int, app *apps.SonarrConfig) error {
+	queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting queue: %w", err)
+	}
+
+	hist, err := app.GetHistoryContext(ctx, historyRecordsMax, queueItemsMax)
+	if err != nil {
+		return fmt.Errorf("getting history: %w", err)
+	}
+
+	for _, record := range queue.Records {
+		starrs.add(&Download{
+			App:      string(starr.Sonarr),
+			Instance: instance,
+			Name:     app.Name,
+			ID:       record.DownloadID,
+			Title:    record.Title,
+			Protocol: record.Protocol,
+			Client:   record.DownloadClient,
+			Size:     int64(record.Size),
+		})
+	}
+
+	for _, record := range hist.Records {
+		starrs.addID(record.DownloadID)
+	}
+
+	return nil
+}
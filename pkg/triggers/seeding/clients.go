@@ -0,0 +1,96 @@
+package seeding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps"
+)
+
+/* This file collects torrents from Qbit, Deluge and Transmission into one shape.
+   rTorrent does not report trackers, so tracker requirements could not protect its torrents; it is skipped.
+   Qbit only reports the working tracker, so finished torrents without one have their trackers looked up. */
+
+// torrent is a torrent from any supported client, with the functions to pause or remove it.
+type torrent struct {
+	client   string
+	instance int
+	name     string // instance name.
+	hash     string
+	title    string
+	category []string // Qbit category, Deluge or Transmission label.
+	trackers []string // tracker host names.
+	ratio    float64
+	seeded   time.Duration
+	size     int64
+	done     bool // finished downloading.
+	paused   bool
+	pause    func(ctx context.Context) error
+	remove   func(ctx context.Context, deleteData bool) error
+}
+
+// getTorrents collects the torrents from all enabled torrent clients, except rTorrent.
+func (c *cmd) getTorrents(ctx context.Context, payload *Payload) []*torrent {
+	torrents := []*torrent{}
+
+	for _, client := range c.Apps.DownloadClients() {
+		if client.Protocol != apps.ProtocolTorrent || client.App == apps.AppRtorrent {
+			continue
+		}
+
+		transfers, err := client.GetTransfers(ctx)
+		if err != nil {
+			payload.Errors = append(payload.Errors, fmt.Sprintf("%s %d: %v", client.App, client.Instance, err))
+		}
+
+		for _, xfer := range transfers {
+			torrent := newTorrent(client, xfer)
+			c.getTrackers(ctx, client, torrent, payload)
+			torrents = append(torrents, torrent)
+		}
+	}
+
+	return torrents
+}
+
+// getTrackers looks up the trackers of a finished Qbit torrent that has no working tracker.
+// This only happens when tracker requirements are configured, because it's one request per torrent.
+func (c *cmd) getTrackers(ctx context.Context, client *apps.DownloadClient, torrent *torrent, payload *Payload) {
+	qbit, ok := client.Downloader.(*apps.QbitConfig)
+	if !ok || !torrent.done || len(torrent.trackers) > 0 || len(c.seeding.Trackers) == 0 {
+		return
+	}
+
+	trackers, err := qbit.GetTrackers(ctx, torrent.hash)
+	if err != nil {
+		payload.Errors = append(payload.Errors,
+			fmt.Sprintf("%s %d: getting trackers for %s: %v", client.App, client.Instance, torrent.title, err))
+		return
+	}
+
+	torrent.trackers = trackers
+}
+
+func newTorrent(client *apps.DownloadClient, xfer *apps.Transfer) *torrent {
+	hash := xfer.ID
+
+	return &torrent{
+		client:   string(client.App),
+		instance: client.Instance,
+		name:     client.Name,
+		hash:     hash,
+		title:    xfer.Name,
+		category: []string{xfer.Category},
+		trackers: xfer.Trackers,
+		ratio:    xfer.Ratio,
+		seeded:   xfer.Seeding.Duration,
+		size:     xfer.Size,
+		done:     xfer.Done,
+		paused:   xfer.State == apps.TransferPaused,
+		pause:    func(ctx context.Context) error { return client.PauseTransfers(ctx, hash) },
+		remove: func(ctx context.Context, deleteData bool) error {
+			return client.RemoveTransfers(ctx, deleteData, hash)
+		},
+	}
+}
@@ -0,0 +1,388 @@
+package seeding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+	"golift.io/cnfg"
+)
+
+/* Seeding enforces seeding goals on finished torrents in Qbit, Deluge and Transmission.
+   A torrent that reached the goal of the first rule matching its category or tracker is paused or removed.
+   Torrents on a tracker with a hit-and-run requirement are protected until they also meet it.
+   When any tracker requirement is configured, torrents with no known tracker are protected too.
+   Torrents still in a Starr app's queue are always protected. */
+
+const TrigSeeding common.TriggerName = "Enforcing torrent seeding goals."
+
+// DefaultInterval is how often seeding goals are enforced when no interval is configured.
+const DefaultInterval = 30 * time.Minute
+
+// Actions a rule may take on a torrent that reached its goal.
+const (
+	ActionPause  = "pause"
+	ActionRemove = "remove" // remove the torrent, keep the data.
+	ActionDelete = "delete" // remove the torrent and its data.
+)
+
+// Errors returned by this package.
+var (
+	ErrInvalidRule = fmt.Errorf("invalid seeding rule")
+)
+
+// Config is the [seeding] section of the config file.
+//
+//nolint:lll
+type Config struct {
+	Interval cnfg.Duration `json:"interval" toml:"interval" xml:"interval" yaml:"interval"`
+	DryRun   bool          `json:"dryRun" toml:"dry_run" xml:"dry_run" yaml:"dryRun"`
+	Rules    []*Rule       `json:"rules" toml:"rule" xml:"rule" yaml:"rules"`
+	Trackers []*Tracker    `json:"trackers" toml:"tracker" xml:"tracker" yaml:"trackers"`
+}
+
+// Rule is a seeding goal for torrents in a category or on a tracker. Rules are checked in order; the first match wins.
+// A torrent reaches the goal when it meets every minimum set on the rule.
+//
+//nolint:lll
+type Rule struct {
+	Name        string        `json:"name" toml:"name" xml:"name" yaml:"name"`
+	Category    []string      `json:"category" toml:"category" xml:"category" yaml:"category"` // Qbit category, Deluge or Transmission label.
+	Tracker     []string      `json:"tracker" toml:"tracker" xml:"tracker" yaml:"tracker"`     // text in a tracker host name.
+	MinRatio    float64       `json:"minRatio" toml:"min_ratio" xml:"min_ratio" yaml:"minRatio"`
+	MinSeedTime cnfg.Duration `json:"minSeedTime" toml:"min_seed_time" xml:"min_seed_time" yaml:"minSeedTime"`
+	Action      string        `json:"action" toml:"action" xml:"action" yaml:"action"` // pause, remove or delete.
+}
+
+// Tracker is a private tracker's hit-and-run requirement.
+// Torrents on the tracker are not paused or removed until they meet it.
+//
+//nolint:lll
+type Tracker struct {
+	Host        string        `json:"host" toml:"host" xml:"host" yaml:"host"` // text in a tracker host name.
+	MinRatio    float64       `json:"minRatio" toml:"min_ratio" xml:"min_ratio" yaml:"minRatio"`
+	MinSeedTime cnfg.Duration `json:"minSeedTime" toml:"min_seed_time" xml:"min_seed_time" yaml:"minSeedTime"`
+}
+
+// Action contains the exported methods for this package.
+type Action struct {
+	cmd *cmd
+}
+
+type cmd struct {
+	*common.Config
+	seeding *Config
+	last    *Payload
+	lastMu  sync.RWMutex
+}
+
+// Result is one action taken on a torrent, or one that would have been taken in dry run mode.
+type Result struct {
+	Client   string        `json:"client"`
+	Instance int           `json:"instance"`
+	Name     string        `json:"name"`
+	Hash     string        `json:"hash"`
+	Title    string        `json:"title"`
+	Category string        `json:"category"`
+	Tracker  string        `json:"tracker"`
+	Ratio    float64       `json:"ratio"`
+	Seeded   cnfg.Duration `json:"seedTime"`
+	Size     int64         `json:"size"`
+	Rule     string        `json:"rule"`
+	Action   string        `json:"action"`
+	DryRun   bool          `json:"dryRun"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Payload is what we send to the website.
+type Payload struct {
+	Actions   []*Result `json:"actions"`
+	Checked   int       `json:"checked"`   // finished torrents checked.
+	Protected int       `json:"protected"` // reached a goal, but queued, on an unknown tracker or below a tracker requirement.
+	DryRun    bool      `json:"dryRun"`
+	Errors    []string  `json:"errors,omitempty"`
+	Date      time.Time `json:"date"`
+}
+
+// New configures the library.
+func New(config *common.Config, seeding *Config) *Action {
+	return &Action{cmd: &cmd{Config: config, seeding: seeding}}
+}
+
+// Create initializes the library.
+func (a *Action) Create() {
+	a.cmd.create()
+}
+
+func (c *cmd) create() {
+	if c.seeding == nil || len(c.seeding.Rules) == 0 {
+		return
+	}
+
+	if err := c.seeding.Validate(); err != nil {
+		c.Errorf("Seeding goals disabled: %v", err)
+		return
+	}
+
+	interval := c.seeding.Interval.Duration
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	c.Printf("==> Seeding Goals Timer Enabled, interval:%s, rules:%d, trackers:%d, dry run:%v",
+		interval, len(c.seeding.Rules), len(c.seeding.Trackers), c.seeding.DryRun)
+
+	c.Add(&common.Action{
+		Name: TrigSeeding,
+		Fn:   c.enforce,
+		C:    make(chan *common.ActionInput, 1),
+		D:    cnfg.Duration{Duration: interval},
+	})
+}
+
+// Send enforces seeding goals now. Returns false if seeding goals are not enabled.
+func (a *Action) Send(event website.EventType) bool {
+	return a.cmd.Exec(&common.ActionInput{Type: event}, TrigSeeding)
+}
+
+// Results returns the result of the last run, or nil if one has not run.
+func (a *Action) Results() *Payload {
+	a.cmd.lastMu.RLock()
+	defer a.cmd.lastMu.RUnlock()
+
+	return a.cmd.last
+}
+
+// Validate makes sure every rule has a valid action and at least one goal.
+// A rule without a goal would pause or remove every torrent it matches.
+func (c *Config) Validate() error {
+	for idx, rule := range c.Rules {
+		switch {
+		case rule == nil:
+			return fmt.Errorf("%w: rule %d is empty", ErrInvalidRule, idx+1)
+		case rule.Action != ActionPause && rule.Action != ActionRemove && rule.Action != ActionDelete:
+			return fmt.Errorf("%w: rule %d: action must be %s, %s or %s",
+				ErrInvalidRule, idx+1, ActionPause, ActionRemove, ActionDelete)
+		case rule.MinRatio < 0 || rule.MinSeedTime.Duration < 0:
+			return fmt.Errorf("%w: rule %d: min_ratio and min_seed_time may not be negative", ErrInvalidRule, idx+1)
+		case rule.MinRatio == 0 && rule.MinSeedTime.Duration == 0:
+			return fmt.Errorf("%w: rule %d needs a min_ratio or min_seed_time", ErrInvalidRule, idx+1)
+		}
+	}
+
+	for idx, tracker := range c.Trackers {
+		if tracker == nil || tracker.Host == "" {
+			return fmt.Errorf("%w: tracker %d needs a host", ErrInvalidRule, idx+1)
+		}
+	}
+
+	return nil
+}
+
+// Matches returns true if the rule's category and tracker match the torrent.
+func (r *Rule) Matches(categories, trackers []string) bool {
+	if len(r.Category) > 0 && !containsAny(r.Category, categories) {
+		return false
+	}
+
+	return len(r.Tracker) == 0 || matchTracker(r.Tracker, trackers) != ""
+}
+
+// Met returns true if a torrent with this ratio and seed time reached the rule's goal.
+func (r *Rule) Met(ratio float64, seeded time.Duration) bool {
+	return ratio >= r.MinRatio && seeded >= r.MinSeedTime.Duration
+}
+
+// Met returns true if a torrent with this ratio and seed time meets the tracker's requirement.
+func (t *Tracker) Met(ratio float64, seeded time.Duration) bool {
+	return ratio >= t.MinRatio && seeded >= t.MinSeedTime.Duration
+}
+
+// Label returns the rule name, or its number if it has no name.
+func (r *Rule) Label(idx int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	return fmt.Sprint("#", idx+1)
+}
+
+func containsAny(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(w, h) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchTracker returns the first tracker host that contains one of the wanted texts.
+func matchTracker(want, hosts []string) string {
+	for _, host := range hosts {
+		for _, text := range want {
+			if text != "" && strings.Contains(strings.ToLower(host), strings.ToLower(text)) {
+				return host
+			}
+		}
+	}
+
+	return ""
+}
+
+// enforce checks every finished torrent against the rules and acts on those that reached their goal.
+func (c *cmd) enforce(ctx context.Context, input *common.ActionInput) {
+	payload := &Payload{Actions: []*Result{}, DryRun: c.seeding.DryRun, Date: time.Now()}
+	torrents := c.getTorrents(ctx, payload)
+	queued := c.getQueued(ctx, payload)
+
+	for _, torrent := range torrents {
+		if !torrent.done {
+			continue
+		}
+
+		payload.Checked++
+
+		result := c.check(ctx, input, torrent, queued, payload)
+		if result != nil {
+			payload.Actions = append(payload.Actions, result)
+		}
+	}
+
+	for _, err := range payload.Errors {
+		c.Errorf("[%s requested] Seeding Goals: %s", input.Type, err)
+	}
+
+	c.lastMu.Lock()
+	c.last = payload
+	c.lastMu.Unlock()
+
+	if len(payload.Actions) == 0 && input.Type == website.EventCron {
+		return
+	}
+
+	c.SendData(&website.Request{
+		Route:      website.SeedingRoute,
+		Event:      input.Type,
+		LogPayload: true,
+		LogMsg: fmt.Sprintf("Seeding Goals: checked %d torrents, %d actions, %d protected, dry run: %v",
+			payload.Checked, len(payload.Actions), payload.Protected, payload.DryRun),
+		Payload: payload,
+	})
+}
+
+// check finds the torrent's rule and applies it. Returns nil if nothing was (or would have been) done.
+func (c *cmd) check(
+	ctx context.Context,
+	input *common.ActionInput,
+	torrent *torrent,
+	queued *queuedIDs,
+	payload *Payload,
+) *Result {
+	for idx, rule := range c.seeding.Rules {
+		if !rule.Matches(torrent.category, torrent.trackers) {
+			continue
+		}
+
+		if !rule.Met(torrent.ratio, torrent.seeded) || (rule.Action == ActionPause && torrent.paused) {
+			return nil
+		}
+
+		if queued.has(torrent.hash) {
+			payload.Protected++
+			c.Debugf("[%s requested] Seeding Goals: %s %d: %s: rule %s reached, protected while in a Starr queue",
+				input.Type, torrent.client, torrent.instance, torrent.title, rule.Label(idx))
+
+			return nil
+		}
+
+		if len(c.seeding.Trackers) > 0 && len(torrent.trackers) == 0 {
+			payload.Protected++
+			c.Debugf("[%s requested] Seeding Goals: %s %d: %s: rule %s reached, protected because its tracker is unknown",
+				input.Type, torrent.client, torrent.instance, torrent.title, rule.Label(idx))
+
+			return nil
+		}
+
+		if tracker := c.requirement(torrent); tracker != nil && !tracker.Met(torrent.ratio, torrent.seeded) {
+			payload.Protected++
+			c.Debugf("[%s requested] Seeding Goals: %s %d: %s: rule %s reached, protected until tracker %s "+
+				"requirement is met (ratio: %.2f, seeded: %s)", input.Type, torrent.client, torrent.instance,
+				torrent.title, rule.Label(idx), tracker.Host, torrent.ratio, torrent.seeded.Round(time.Minute))
+
+			return nil
+		}
+
+		return c.apply(ctx, input, torrent, rule, idx)
+	}
+
+	return nil
+}
+
+// requirement returns the hit-and-run requirement for the torrent's tracker, if it has one.
+func (c *cmd) requirement(torrent *torrent) *Tracker {
+	for _, tracker := range c.seeding.Trackers {
+		if matchTracker([]string{tracker.Host}, torrent.trackers) != "" {
+			return tracker
+		}
+	}
+
+	return nil
+}
+
+// apply pauses or removes the torrent, and logs what happened.
+func (c *cmd) apply(ctx context.Context, input *common.ActionInput, torrent *torrent, rule *Rule, idx int) *Result {
+	result := &Result{
+		Client:   torrent.client,
+		Instance: torrent.instance,
+		Name:     torrent.name,
+		Hash:     torrent.hash,
+		Title:    torrent.title,
+		Category: strings.Join(torrent.category, ", "),
+		Ratio:    torrent.ratio,
+		Seeded:   cnfg.Duration{Duration: torrent.seeded.Round(time.Second)},
+		Size:     torrent.size,
+		Rule:     rule.Label(idx),
+		Action:   rule.Action,
+		DryRun:   c.seeding.DryRun,
+	}
+
+	if len(torrent.trackers) > 0 {
+		result.Tracker = torrent.trackers[0]
+	}
+
+	msg := fmt.Sprintf("[%s requested] Seeding Goals: %s %d: rule %s: %s: %s (ratio: %.2f, seeded: %s, hash: %s)",
+		input.Type, torrent.client, torrent.instance, result.Rule, rule.Action, torrent.title,
+		torrent.ratio, result.Seeded, torrent.hash)
+
+	if c.seeding.DryRun {
+		c.Printf("%s; dry run, nothing was changed", msg)
+		return result
+	}
+
+	var err error
+
+	switch rule.Action {
+	case ActionPause:
+		err = torrent.pause(ctx)
+	case ActionRemove:
+		err = torrent.remove(ctx, false)
+	case ActionDelete:
+		err = torrent.remove(ctx, true)
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		c.Errorf("%s; failed: %v", msg, err)
+	} else {
+		c.Printf("%s; done", msg)
+	}
+
+	return result
+}
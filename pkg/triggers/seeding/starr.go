@@ -0,0 +1,97 @@
+package seeding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+/* This file finds the torrents still in a Starr app's queue. Those are never paused or removed. */
+
+// queueItemsMax is the most queue items fetched from each Starr instance.
+const queueItemsMax = 1000
+
+// queuedIDs is the set of download IDs in a Starr app's queue.
+// If any Starr instance could not be read, every torrent is treated as queued.
+type queuedIDs struct {
+	ids    map[string]struct{}
+	failed bool
+}
+
+func (q *queuedIDs) has(hash string) bool {
+	if q.failed {
+		return true
+	}
+
+	_, ok := q.ids[strings.ToUpper(hash)]
+
+	return ok
+}
+
+func (q *queuedIDs) add(downloadID string) {
+	if downloadID != "" {
+		q.ids[strings.ToUpper(downloadID)] = struct{}{}
+	}
+}
+
+// getQueued collects the download IDs in every enabled Starr app's queue.
+// A torrent in a queue may not be imported yet, so removing it could lose the download.
+func (c *cmd) getQueued(ctx context.Context, payload *Payload) *queuedIDs {
+	queued := &queuedIDs{ids: make(map[string]struct{})}
+
+	fail := func(app string, instance int, err error) {
+		queued.failed = true
+		payload.Errors = append(payload.Errors, fmt.Sprintf("%s %d: getting queue, all torrents are protected: %v",
+			app, instance, err))
+	}
+
+	for idx, app := range c.Apps.Lidarr {
+		if !app.Enabled() {
+			continue
+		} else if queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax); err != nil {
+			fail("Lidarr", idx+1, err)
+		} else {
+			for _, record := range queue.Records {
+				queued.add(record.DownloadID)
+			}
+		}
+	}
+
+	for idx, app := range c.Apps.Radarr {
+		if !app.Enabled() {
+			continue
+		} else if queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax); err != nil {
+			fail("Radarr", idx+1, err)
+		} else {
+			for _, record := range queue.Records {
+				queued.add(record.DownloadID)
+			}
+		}
+	}
+
+	for idx, app := range c.Apps.Readarr {
+		if !app.Enabled() {
+			continue
+		} else if queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax); err != nil {
+			fail("Readarr", idx+1, err)
+		} else {
+			for _, record := range queue.Records {
+				queued.add(record.DownloadID)
+			}
+		}
+	}
+
+	for idx, app := range c.Apps.Sonarr {
+		if !app.Enabled() {
+			continue
+		} else if queue, err := app.GetQueueContext(ctx, queueItemsMax, queueItemsMax); err != nil {
+			fail("Sonarr", idx+1, err)
+		} else {
+			for _, record := range queue.Records {
+				queued.add(record.DownloadID)
+			}
+		}
+	}
+
+	return queued
+}
@@ -0,0 +1,234 @@
+package snapcron
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/cooldown"
+	"github.com/Notifiarr/notifiarr/pkg/snapshot"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/data"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+)
+
+/* Drive alerts compare the SMART data in each snapshot to the previous snapshots,
+   and warn when a drive is getting worse. Warnings go to the website and the Web UI. */
+
+// Keys in the data cache. The cache survives a reload, so drive history does too.
+// The baseline is also saved to disk with the same name, so a restart does not forget it.
+const (
+	DriveAlertsKey   = "driveAlerts"
+	driveBaselineKey = "driveBaseline"
+	// How many recent warnings to keep for the Web UI.
+	maxDriveAlerts = 50
+)
+
+// Drive alert types.
+const (
+	AlertReallocated = "reallocated"
+	AlertPending     = "pending"
+	AlertMediaErrors = "mediaErrors"
+	AlertTemperature = "temperature"
+	AlertWear        = "wear"
+	AlertSpare       = "spare"
+)
+
+// driveCooler keeps the same warning from repeating every snapshot.
+// It lives as long as the app, so a reload does not reset it.
+var driveCooler = cooldown.NewTimer(false, time.Hour) //nolint:gochecknoglobals
+
+// DriveAlert is a warning about a drive that is getting worse.
+type DriveAlert struct {
+	Device   string    `json:"device"`
+	Model    string    `json:"model"`
+	Serial   string    `json:"serial"`
+	Type     string    `json:"type"`
+	Message  string    `json:"message"`
+	Previous int64     `json:"previous"`
+	Current  int64     `json:"current"`
+	Limit    int64     `json:"limit"`
+	Date     time.Time `json:"date"`
+}
+
+// DriveAlerts is the payload sent to the website.
+type DriveAlerts struct {
+	Alerts []*DriveAlert `json:"alerts"`
+}
+
+// driveCounters are the SMART counters kept between snapshots.
+type driveCounters struct {
+	Reallocated int64 `json:"reallocated"`
+	Pending     int64 `json:"pending"`
+	MediaErrors int64 `json:"mediaErrors"`
+}
+
+// driveCheck collects the warnings for one drive.
+type driveCheck struct {
+	smart  *snapshot.SmartData
+	name   string
+	cool   time.Duration
+	alerts []*DriveAlert
+}
+
+// checkDrives compares SMART data to the last snapshot and sends any warnings.
+func (c *cmd) checkDrives(input *common.ActionInput, snap *snapshot.Snapshot) {
+	if c.Snapshot.Plugins == nil || c.Snapshot.DriveAlerts == nil || c.Snapshot.DriveAlerts.Disabled ||
+		snap == nil || len(snap.Smart) == 0 {
+		return
+	}
+
+	baseline := c.getDriveBaseline()
+	alerts := []*DriveAlert{}
+	names := make([]string, 0, len(snap.Smart))
+
+	for name := range snap.Smart {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		check := &driveCheck{smart: snap.Smart[name], name: name, cool: c.Snapshot.DriveAlerts.CoolFor()}
+		check.thresholds(c.Snapshot.DriveAlerts)
+
+		// Key by serial number, because device names can change between boots.
+		key := name
+		if check.smart.Serial != "" {
+			key = check.smart.Serial
+		}
+
+		if counters := baseline[key]; counters != nil {
+			check.grew(AlertReallocated, "reallocated sectors", &counters.Reallocated, check.smart.Reallocated)
+			check.grew(AlertPending, "pending sectors", &counters.Pending, check.smart.Pending)
+			check.grew(AlertMediaErrors, "media errors", &counters.MediaErrors, check.smart.MediaErrors)
+		} else { // first time we see this drive; nothing to compare yet.
+			baseline[key] = &driveCounters{
+				Reallocated: check.smart.Reallocated,
+				Pending:     check.smart.Pending,
+				MediaErrors: check.smart.MediaErrors,
+			}
+		}
+
+		alerts = append(alerts, check.alerts...)
+	}
+
+	data.Save(driveBaselineKey, baseline)
+
+	if err := data.SaveState(driveBaselineKey, baseline); err != nil {
+		c.Errorf("[%s requested] Drive Warnings: saving drive baseline: %v", input.Type, err)
+	}
+
+	if len(alerts) == 0 {
+		return
+	}
+
+	for _, alert := range alerts {
+		c.Printf("[%s requested] Drive Warning: %s: %s", input.Type, alert.Device, alert.Message)
+	}
+
+	saveDriveAlerts(alerts)
+	c.SendData(&website.Request{
+		Route:      website.DrivesRoute,
+		Event:      input.Type,
+		LogPayload: true,
+		LogMsg:     fmt.Sprintf("Drive Warnings: %d", len(alerts)),
+		Payload:    &DriveAlerts{Alerts: alerts},
+	})
+}
+
+// thresholds warns about a drive that is too hot or worn out, no matter what it was before.
+func (d *driveCheck) thresholds(config *snapshot.DriveAlerts) {
+	if limit := config.TempLimit(d.smart); limit > 0 && d.smart.Temperature > limit {
+		d.warn(AlertTemperature, 0, int64(d.smart.Temperature), int64(limit),
+			"temperature is %d°C, above the %d°C limit", d.smart.Temperature, limit)
+	}
+
+	if config.MaxWear > 0 && d.smart.PercentUsed >= config.MaxWear {
+		d.warn(AlertWear, 0, int64(d.smart.PercentUsed), int64(config.MaxWear),
+			"%d%% of rated endurance is used, the limit is %d%%", d.smart.PercentUsed, config.MaxWear)
+	}
+
+	if d.smart.SpareLimit > 0 && d.smart.Spare <= d.smart.SpareLimit {
+		d.warn(AlertSpare, 0, int64(d.smart.Spare), int64(d.smart.SpareLimit),
+			"available spare is %d%%, at or below the drive's %d%% threshold", d.smart.Spare, d.smart.SpareLimit)
+	}
+}
+
+// grew warns when a counter went up, and moves the baseline to the current value.
+// While a warning is cooling down the baseline stays put, so the next warning covers all of the growth.
+func (d *driveCheck) grew(kind, what string, baseline *int64, current int64) {
+	if current <= *baseline {
+		*baseline = current
+		return
+	}
+
+	if d.warn(kind, *baseline, current, 0, "%s grew from %d to %d", what, *baseline, current) {
+		*baseline = current
+	}
+}
+
+// warn adds a warning unless the same warning for this drive is cooling down. Returns true if it was added.
+func (d *driveCheck) warn(kind string, previous, current, limit int64, format string, args ...interface{}) bool {
+	if driveCooler.Active(d.name+":"+kind, d.cool) {
+		return false
+	}
+
+	d.alerts = append(d.alerts, &DriveAlert{
+		Device:   d.name,
+		Model:    d.smart.Model,
+		Serial:   d.smart.Serial,
+		Type:     kind,
+		Message:  fmt.Sprintf(format, args...),
+		Previous: previous,
+		Current:  current,
+		Limit:    limit,
+		Date:     time.Now(),
+	})
+
+	return true
+}
+
+// getDriveBaseline returns the counters from the last snapshot.
+// The data cache is empty after a restart, so the baseline is read from disk then.
+func (c *cmd) getDriveBaseline() map[string]*driveCounters {
+	if item := data.Get(driveBaselineKey); item != nil {
+		if baseline, ok := item.Data.(map[string]*driveCounters); ok {
+			return baseline
+		}
+	}
+
+	baseline := make(map[string]*driveCounters)
+	if err := data.LoadState(driveBaselineKey, &baseline); err != nil {
+		c.Errorf("Drive Warnings: reading drive baseline: %v", err)
+	}
+
+	if baseline == nil { // the file said null.
+		baseline = make(map[string]*driveCounters)
+	}
+
+	return baseline
+}
+
+// saveDriveAlerts keeps the most recent warnings, newest first, for the Web UI.
+func saveDriveAlerts(alerts []*DriveAlert) {
+	recent := make([]*DriveAlert, 0, maxDriveAlerts)
+
+	for idx := len(alerts) - 1; idx >= 0 && len(recent) < maxDriveAlerts; idx-- {
+		recent = append(recent, alerts[idx])
+	}
+
+	if item := data.Get(DriveAlertsKey); item != nil {
+		if saved, ok := item.Data.([]*DriveAlert); ok {
+			for _, alert := range saved {
+				if len(recent) >= maxDriveAlerts {
+					break
+				}
+
+				recent = append(recent, alert)
+			}
+		}
+	}
+
+	data.Save(DriveAlertsKey, recent)
+}
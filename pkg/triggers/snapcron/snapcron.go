@@ -72,6 +72,15 @@ func (c *cmd) printLog() {
 		"mysql":    c.Snapshot.Plugins != nil && len(c.Snapshot.MySQL) > 0,
 		"zfs":      len(c.Snapshot.ZFSPools) > 0,
 		"sudo":     c.Snapshot.UseSudo && c.Snapshot.DriveData,
+		"drive alerts": c.Snapshot.DriveData && c.Snapshot.Plugins != nil &&
+			c.Snapshot.DriveAlerts != nil && !c.Snapshot.DriveAlerts.Disabled,
+		"containers": c.Snapshot.Plugins != nil && c.Snapshot.Containers != nil &&
+			!c.Snapshot.Containers.Disabled,
+		"btrfs":    c.Snapshot.Plugins != nil && c.Snapshot.Btrfs != nil && c.Snapshot.Btrfs.Enabled,
+		"lvm":      c.Snapshot.Plugins != nil && c.Snapshot.LVM != nil && c.Snapshot.LVM.Enabled,
+		"snapraid": c.Snapshot.Plugins != nil && c.Snapshot.SnapRAID != nil && c.Snapshot.SnapRAID.Enabled,
+		"snapraid diff": c.Snapshot.Plugins != nil && c.Snapshot.SnapRAID != nil &&
+			c.Snapshot.SnapRAID.Enabled && c.Snapshot.SnapRAID.Diff,
 	} {
 		if !val {
 			continue
@@ -109,6 +118,7 @@ func (c *cmd) sendSnapshot(ctx context.Context, input *common.ActionInput) {
 	}
 
 	data.Save("snapshot", snapshot)
+	c.checkDrives(input, snapshot)
 	c.SendData(&website.Request{
 		Route:      website.SnapRoute,
 		Event:      input.Type,
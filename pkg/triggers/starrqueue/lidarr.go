@@ -10,6 +10,7 @@ import (
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/Notifiarr/notifiarr/pkg/website/clientinfo"
 	"golift.io/cnfg"
+	"golift.io/starr"
 )
 
 const TrigLidarrQueue common.TriggerName = "Storing Lidarr instance %d queue."
@@ -43,6 +44,14 @@ func (app *lidarrApp) storeQueue(ctx context.Context, input *common.ActionInput)
 	app.cmd.Debugf("[%s requested] Stored Lidarr Queue (%d items), instance %d %s",
 		input.Type, len(queue.Records), app.idx+1, app.app.Name)
 	data.SaveWithID("lidarr", app.idx, queue)
+	app.cmd.remediate(ctx, input, &remediation{
+		app:      starr.Lidarr,
+		instance: app.idx + 1,
+		rules:    app.app.QueueRules,
+		deleter:  app.app,
+		delOK:    app.app.DelOK,
+		items:    lidarrQueueItems(queue.Records),
+	})
 }
 
 func (c *cmd) setupLidarr() bool {
@@ -50,22 +59,26 @@ func (c *cmd) setupLidarr() bool {
 
 	for idx, app := range c.Apps.Lidarr {
 		ci := clientinfo.Get()
-		if !app.Enabled() || ci == nil {
+		if !app.Enabled() {
 			continue
 		}
 
 		var dur time.Duration
 
 		instance := idx + 1
-		if ci.Actions.Apps.Lidarr.Finished(instance) {
+		if ci != nil && ci.Actions.Apps.Lidarr.Finished(instance) {
 			dur = finishedDuration
-		} else if ci.Actions.Apps.Lidarr.Stuck(instance) {
+		} else if ci != nil && ci.Actions.Apps.Lidarr.Stuck(instance) {
 			dur = stuckDuration
 		}
 
-		if dur != 0 {
-			enabled = true
+		enabled = enabled || dur != 0
+
+		if dur == 0 && len(app.QueueRules) > 0 {
+			dur = stuckDuration // queue rules need the queue, even if the website does not.
+		}
 
+		if dur != 0 {
 			c.Add(&common.Action{
 				Hide: true,
 				Name: TrigLidarrQueue.WithInstance(instance),
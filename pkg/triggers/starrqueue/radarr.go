@@ -10,6 +10,7 @@ import (
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/Notifiarr/notifiarr/pkg/website/clientinfo"
 	"golift.io/cnfg"
+	"golift.io/starr"
 )
 
 const TrigRadarrQueue common.TriggerName = "Storing Radarr instance %d queue."
@@ -45,6 +46,14 @@ func (app *radarrApp) storeQueue(ctx context.Context, input *common.ActionInput)
 	app.cmd.Debugf("[%s requested] Stored Radarr Queue (%d items), instance %d %s",
 		input.Type, len(queue.Records), app.idx+1, app.app.Name)
 	data.SaveWithID("radarr", app.idx, queue)
+	app.cmd.remediate(ctx, input, &remediation{
+		app:      starr.Radarr,
+		instance: app.idx + 1,
+		rules:    app.app.QueueRules,
+		deleter:  app.app,
+		delOK:    app.app.DelOK,
+		items:    radarrQueueItems(queue.Records),
+	})
 }
 
 func (c *cmd) setupRadarr() bool {
@@ -52,22 +61,26 @@ func (c *cmd) setupRadarr() bool {
 
 	for idx, app := range c.Apps.Radarr {
 		ci := clientinfo.Get()
-		if !app.Enabled() || ci == nil {
+		if !app.Enabled() {
 			continue
 		}
 
 		var dur time.Duration
 
 		instance := idx + 1
-		if ci.Actions.Apps.Radarr.Finished(instance) {
+		if ci != nil && ci.Actions.Apps.Radarr.Finished(instance) {
 			dur = finishedDuration
-		} else if ci.Actions.Apps.Radarr.Stuck(instance) {
+		} else if ci != nil && ci.Actions.Apps.Radarr.Stuck(instance) {
 			dur = stuckDuration
 		}
 
-		if dur != 0 {
-			enabled = true
+		enabled = enabled || dur != 0
+
+		if dur == 0 && len(app.QueueRules) > 0 {
+			dur = stuckDuration // queue rules need the queue, even if the website does not.
+		}
 
+		if dur != 0 {
 			c.Add(&common.Action{
 				Hide: true,
 				Name: TrigRadarrQueue.WithInstance(instance),
@@ -10,6 +10,7 @@ import (
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/Notifiarr/notifiarr/pkg/website/clientinfo"
 	"golift.io/cnfg"
+	"golift.io/starr"
 )
 
 const TrigReadarrQueue common.TriggerName = "Storing Readarr instance %d queue."
@@ -43,6 +44,14 @@ func (app *readarrApp) storeQueue(ctx context.Context, input *common.ActionInput
 	app.cmd.Debugf("[%s requested] Stored Readarr Queue (%d items), instance %d %s",
 		input.Type, len(queue.Records), app.idx+1, app.app.Name)
 	data.SaveWithID("readarr", app.idx, queue)
+	app.cmd.remediate(ctx, input, &remediation{
+		app:      starr.Readarr,
+		instance: app.idx + 1,
+		rules:    app.app.QueueRules,
+		deleter:  app.app,
+		delOK:    app.app.DelOK,
+		items:    readarrQueueItems(queue.Records),
+	})
 }
 
 func (c *cmd) setupReadarr() bool {
@@ -50,7 +59,7 @@ func (c *cmd) setupReadarr() bool {
 
 	for idx, app := range c.Apps.Readarr {
 		ci := clientinfo.Get()
-		if !app.Enabled() || ci == nil {
+		if !app.Enabled() {
 			continue
 		}
 
@@ -59,12 +68,14 @@ func (c *cmd) setupReadarr() bool {
 		instance := idx + 1
 
 		switch {
-		case ci.Actions.Apps.Readarr.Finished(instance):
+		case ci != nil && ci.Actions.Apps.Readarr.Finished(instance):
 			enable = true
 			dur = finishedDuration
-		case ci.Actions.Apps.Readarr.Stuck(instance):
+		case ci != nil && ci.Actions.Apps.Readarr.Stuck(instance):
 			enable = true
 			dur = stuckDuration
+		case len(app.QueueRules) > 0:
+			dur = stuckDuration // queue rules need the queue, even if the website does not.
 		default:
 			continue
 		}
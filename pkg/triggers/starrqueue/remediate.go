@@ -0,0 +1,209 @@
+package starrqueue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
+	"golift.io/starr"
+	"golift.io/starr/lidarr"
+	"golift.io/starr/radarr"
+	"golift.io/starr/readarr"
+	"golift.io/starr/sonarr"
+)
+
+/* This file applies locally configured queue rules to stuck queue items.
+   Items are matched every time a queue is stored, and acted on once they have matched for the rule's age.
+   Every action (and every dry run) is written to the log with a "Queue Remediation" prefix; that is the audit log. */
+
+// queueDeleter is satisfied by every starr app with a queue.
+type queueDeleter interface {
+	DeleteQueueContext(ctx context.Context, queueID int64, opts *starr.QueueDeleteOpts) error
+}
+
+// remediation is one app instance's queue with its rules.
+type remediation struct {
+	app      starr.App
+	instance int
+	rules    []*apps.QueueRule
+	deleter  queueDeleter
+	delOK    func() bool
+	items    []*apps.QueueItem
+}
+
+// remediate applies the rules to the queue items. Items only match a rule after matching it for the rule's age.
+func (c *cmd) remediate(ctx context.Context, input *common.ActionInput, rem *remediation) {
+	if len(rem.rules) == 0 {
+		return
+	}
+
+	now := time.Now()
+	prefix := fmt.Sprint(rem.app, rem.instance, "/")
+	current := make(map[string]struct{})
+	// repeatStomper is used to collapse duplicate download IDs.
+	repeatStomper := make(map[string]struct{})
+
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+
+	for _, item := range rem.items {
+		if _, exists := repeatStomper[item.DownloadID]; exists && item.DownloadID != "" {
+			continue
+		}
+
+		for ruleIdx, rule := range rem.rules {
+			if !rule.Matches(item) {
+				continue
+			}
+
+			key := fmt.Sprint(prefix, ruleIdx, "/", item.DownloadID, "/", item.ID)
+			current[key] = struct{}{}
+
+			if _, ok := c.seen[key]; !ok {
+				c.seen[key] = now
+			}
+
+			if now.Sub(c.seen[key]) < rule.Age.Duration {
+				break // Matched, but not for long enough. First matching rule wins.
+			}
+
+			if c.applyRule(ctx, input, rem, ruleIdx, item) {
+				repeatStomper[item.DownloadID] = struct{}{}
+				delete(c.seen, key)
+			}
+
+			break
+		}
+	}
+
+	// Forget items that left the queue or stopped matching, so their age starts over if they come back.
+	for key := range c.seen {
+		if _, ok := current[key]; !ok && strings.HasPrefix(key, prefix) {
+			delete(c.seen, key)
+		}
+	}
+}
+
+// applyRule runs one rule's actions on a queue item and writes the audit line. Returns true if the item was removed.
+func (c *cmd) applyRule(
+	ctx context.Context,
+	input *common.ActionInput,
+	rem *remediation,
+	ruleIdx int,
+	item *apps.QueueItem,
+) bool {
+	rule := rem.rules[ruleIdx]
+	name := rule.Name
+
+	if name == "" {
+		name = fmt.Sprint("#", ruleIdx+1)
+	}
+
+	audit := fmt.Sprintf("[%s requested] Queue Remediation: %s (%d) rule %s: %s: %s (queue ID %d, download ID %s)",
+		input.Type, rem.app, rem.instance, name, rule.Actions(), item.Title, item.ID, item.DownloadID)
+
+	switch {
+	case rule.DryRun:
+		c.Printf("%s; dry run, nothing was changed", audit)
+		return false
+	case !rem.delOK():
+		c.Errorf("%s; skipped, deletes limit reached (or deletes is 0)", audit)
+		return false
+	}
+
+	if err := rem.deleter.DeleteQueueContext(ctx, item.ID, rule.DeleteOpts()); err != nil {
+		c.Errorf("%s; failed: %v", audit, err)
+		return false
+	}
+
+	c.Printf("%s; done", audit)
+
+	return true
+}
+
+// queueMessages collects the error message and all status messages from a queue record.
+func queueMessages(errorMessage string, statusMessages []*starr.StatusMessage) []string {
+	messages := []string{}
+
+	if errorMessage != "" {
+		messages = append(messages, errorMessage)
+	}
+
+	for _, msg := range statusMessages {
+		if msg == nil {
+			continue
+		}
+
+		messages = append(messages, msg.Title)
+		messages = append(messages, msg.Messages...)
+	}
+
+	return messages
+}
+
+func lidarrQueueItems(records []*lidarr.QueueRecord) []*apps.QueueItem {
+	items := make([]*apps.QueueItem, len(records))
+	for idx, record := range records {
+		items[idx] = &apps.QueueItem{
+			ID:         record.ID,
+			Title:      record.Title,
+			DownloadID: record.DownloadID,
+			Protocol:   record.Protocol,
+			States:     []string{record.Status, record.TrackedDownloadStatus},
+			Messages:   queueMessages(record.ErrorMessage, record.StatusMessages),
+		}
+	}
+
+	return items
+}
+
+func radarrQueueItems(records []*radarr.QueueRecord) []*apps.QueueItem {
+	items := make([]*apps.QueueItem, len(records))
+	for idx, record := range records {
+		items[idx] = &apps.QueueItem{
+			ID:         record.ID,
+			Title:      record.Title,
+			DownloadID: record.DownloadID,
+			Protocol:   record.Protocol,
+			States:     []string{record.Status, record.TrackedDownloadStatus, record.TrackedDownloadState},
+			Messages:   queueMessages(record.ErrorMessage, record.StatusMessages),
+		}
+	}
+
+	return items
+}
+
+func readarrQueueItems(records []*readarr.QueueRecord) []*apps.QueueItem {
+	items := make([]*apps.QueueItem, len(records))
+	for idx, record := range records {
+		items[idx] = &apps.QueueItem{
+			ID:         record.ID,
+			Title:      record.Title,
+			DownloadID: record.DownloadID,
+			Protocol:   record.Protocol,
+			States:     []string{record.Status, record.TrackedDownloadStatus, record.TrackedDownloadState},
+			Messages:   queueMessages(record.ErrorMessage, record.StatusMessages),
+		}
+	}
+
+	return items
+}
+
+func sonarrQueueItems(records []*sonarr.QueueRecord) []*apps.QueueItem {
+	items := make([]*apps.QueueItem, len(records))
+	for idx, record := range records {
+		items[idx] = &apps.QueueItem{
+			ID:         record.ID,
+			Title:      record.Title,
+			DownloadID: record.DownloadID,
+			Protocol:   record.Protocol,
+			States:     []string{record.Status, record.TrackedDownloadStatus, record.TrackedDownloadState},
+			Messages:   queueMessages(record.ErrorMessage, record.StatusMessages),
+		}
+	}
+
+	return items
+}
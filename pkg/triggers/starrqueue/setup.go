@@ -1,6 +1,7 @@
 package starrqueue
 
 import (
+	"sync"
 	"time"
 
 	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
@@ -19,6 +20,9 @@ type cmd struct {
 	*common.Config
 	// We set empty to true after we send 1 "empty downloads" payload.
 	empty bool
+	// seen is when a queue item first matched a queue rule.
+	seen   map[string]time.Time
+	seenMu sync.Mutex
 }
 
 const (
@@ -54,7 +58,7 @@ type QueuesPaylod struct {
 
 // New configures the library.
 func New(config *common.Config) *Action {
-	return &Action{cmd: &cmd{Config: config}}
+	return &Action{cmd: &cmd{Config: config, seen: make(map[string]time.Time)}}
 }
 
 // Run initializes the library.
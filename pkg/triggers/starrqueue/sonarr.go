@@ -10,6 +10,7 @@ import (
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/Notifiarr/notifiarr/pkg/website/clientinfo"
 	"golift.io/cnfg"
+	"golift.io/starr"
 )
 
 const TrigSonarrQueue common.TriggerName = "Storing Sonarr instance %d queue."
@@ -45,6 +46,14 @@ func (app *sonarrApp) storeQueue(ctx context.Context, input *common.ActionInput)
 	app.cmd.Debugf("[%s requested] Stored Sonarr Queue (%d items), instance %d %s",
 		input.Type, len(queue.Records), app.idx+1, app.app.Name)
 	data.SaveWithID("sonarr", app.idx, queue)
+	app.cmd.remediate(ctx, input, &remediation{
+		app:      starr.Sonarr,
+		instance: app.idx + 1,
+		rules:    app.app.QueueRules,
+		deleter:  app.app,
+		delOK:    app.app.DelOK,
+		items:    sonarrQueueItems(queue.Records),
+	})
 }
 
 func (c *cmd) setupSonarr() bool {
@@ -52,19 +61,21 @@ func (c *cmd) setupSonarr() bool {
 
 	for idx, app := range c.Apps.Sonarr {
 		ci := clientinfo.Get()
-		if !app.Enabled() || ci == nil {
+		if !app.Enabled() {
 			continue
 		}
 
 		var dur time.Duration
 
 		instance := idx + 1
-		if ci.Actions.Apps.Sonarr.Finished(instance) {
+		if ci != nil && ci.Actions.Apps.Sonarr.Finished(instance) {
 			enable = true
 			dur = finishedDuration
-		} else if ci.Actions.Apps.Sonarr.Stuck(instance) {
+		} else if ci != nil && ci.Actions.Apps.Sonarr.Stuck(instance) {
 			enable = true
 			dur = stuckDuration
+		} else if len(app.QueueRules) > 0 {
+			dur = stuckDuration // queue rules need the queue, even if the website does not.
 		}
 
 		if dur != 0 {
@@ -0,0 +1,210 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps"
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/data"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+)
+
+// saved is a download client, and the limits it had before they were lowered.
+// The limits are written to disk, so a restart after a crash does not save the lowered limits as the originals.
+type saved struct {
+	client *apps.DownloadClient
+	Down   int64 `json:"down"`
+	Up     int64 `json:"up"`
+}
+
+// target is a download client, and the config entry with its throttle limits.
+type target struct {
+	client *apps.DownloadClient
+	limits *Client
+}
+
+// targets returns the download clients in the config. A client matched by more than one entry uses the first one.
+func (c *cmd) targets() map[string]*target {
+	targets := make(map[string]*target)
+
+	for _, limits := range c.throttle.Clients {
+		app := appName(limits.App)
+
+		for _, client := range c.Apps.DownloadClients() {
+			if client.App != app || (limits.Instance != 0 && limits.Instance != client.Instance) {
+				continue
+			}
+
+			if _, ok := targets[client.String()]; !ok {
+				targets[client.String()] = &target{client: client, limits: limits}
+			}
+		}
+	}
+
+	return targets
+}
+
+// lower saves the current limits on every configured client, and lowers them.
+// A limit that is already lower than the throttle limit is left alone.
+// Clients that were already lowered are skipped, so calling this again retries the clients that failed.
+func (c *cmd) lower(ctx context.Context, event website.EventType, mbps float64, streams int) {
+	c.mu.Lock()
+	c.below = time.Time{} // still busy; start the restore delay over.
+	retry := c.saved != nil
+	pending := make(map[string]*target)
+
+	for name, target := range c.targets() {
+		if _, ok := c.saved[name]; !ok {
+			pending[name] = target
+		}
+	}
+
+	c.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if retry {
+		c.Printf("[%s requested] Download Throttle: remote Plex streaming at %.1fMbps (%d streams), "+
+			"lowering limits on %d download clients that were not lowered", event, mbps, streams, len(pending))
+	} else {
+		c.Printf("[%s requested] Download Throttle: remote Plex streaming at %.1fMbps (%d streams) reached %.1fMbps, "+
+			"lowering download client limits", event, mbps, streams, c.throttle.StartMbps)
+	}
+
+	saves := make(map[string]*saved)
+
+	for name, target := range pending {
+		status, err := target.client.GetStatus(ctx)
+		if err != nil {
+			c.Errorf("[%s requested] Download Throttle: %s: getting limits: %v", event, name, err)
+			continue
+		}
+
+		down := lowest(status.DownLimit, target.limits.Download*kibibyte)
+		up := lowest(status.UpLimit, target.limits.Upload*kibibyte)
+
+		if err := target.client.SetSpeedLimits(ctx, down, up); err != nil {
+			c.Errorf("[%s requested] Download Throttle: %s: lowering limits: %v", event, name, err)
+			continue
+		}
+
+		saves[name] = &saved{client: target.client, Down: status.DownLimit, Up: status.UpLimit}
+		c.Printf("[%s requested] Download Throttle: %s: download limit %s -> %s, upload limit %s -> %s",
+			event, name, rate(status.DownLimit), rate(down), rate(status.UpLimit), rate(up))
+	}
+
+	if len(saves) == 0 {
+		return // nothing was lowered; try again next time.
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.saved == nil {
+		c.saved = make(map[string]*saved)
+	}
+
+	for name, save := range saves {
+		c.saved[name] = save
+	}
+
+	c.saveState()
+}
+
+// restore puts back the limits each client had before they were lowered.
+func (c *cmd) restore(ctx context.Context, event website.EventType, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.saved == nil {
+		return
+	}
+
+	c.Printf("[%s requested] Download Throttle: %s, restoring download client limits", event, reason)
+
+	for name, save := range c.saved {
+		if err := save.client.SetSpeedLimits(ctx, save.Down, save.Up); err != nil {
+			c.Errorf("[%s requested] Download Throttle: %s: restoring limits: %v", event, name, err)
+			continue // try again next time.
+		}
+
+		c.Printf("[%s requested] Download Throttle: %s: restored download limit %s, upload limit %s",
+			event, name, rate(save.Down), rate(save.Up))
+		delete(c.saved, name)
+	}
+
+	if len(c.saved) == 0 {
+		c.saved = nil
+	}
+
+	c.saveState()
+}
+
+// saveState writes the saved limits to disk. Call it with the lock held.
+func (c *cmd) saveState() {
+	if err := data.SaveState(savedState, c.saved); err != nil {
+		c.Errorf("Download Throttle: saving original download client limits: %v", err)
+	}
+}
+
+// loadState reads the limits saved before a restart, so they are restored instead of lost.
+// Saved limits for a client that is no longer configured cannot be restored, and are dropped.
+func (c *cmd) loadState() {
+	saves := make(map[string]*saved)
+	if err := data.LoadState(savedState, &saves); err != nil {
+		c.Errorf("Download Throttle: reading original download client limits: %v", err)
+		return
+	}
+
+	clients := make(map[string]*apps.DownloadClient)
+	for _, client := range c.Apps.DownloadClients() {
+		clients[client.String()] = client
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, save := range saves {
+		if save == nil {
+			continue
+		} else if save.client = clients[name]; save.client == nil {
+			c.Errorf("Download Throttle: %s: client not found, cannot restore download limit %s, upload limit %s",
+				name, rate(save.Down), rate(save.Up))
+			continue
+		}
+
+		if c.saved == nil {
+			c.saved = make(map[string]*saved)
+		}
+
+		c.saved[name] = save
+		c.Printf("Download Throttle: %s: limits were lowered before a restart; will restore download limit %s, "+
+			"upload limit %s", name, rate(save.Down), rate(save.Up))
+	}
+
+	if len(c.saved) != len(saves) {
+		c.saveState() // forget the limits that cannot be restored.
+	}
+}
+
+// lowest returns the lower of the current limit and the throttle limit. Zero is unlimited for both.
+func lowest(current, throttle int64) int64 {
+	if throttle <= 0 || (current > 0 && current < throttle) {
+		return current
+	}
+
+	return throttle
+}
+
+// rate formats a speed limit for logs.
+func rate(limit int64) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+
+	return fmt.Sprintf("%s/s", mnd.FormatBytes(limit))
+}
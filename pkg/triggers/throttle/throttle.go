@@ -0,0 +1,241 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/apps"
+	"github.com/Notifiarr/notifiarr/pkg/apps/apppkg/plex"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/common"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/plexcron"
+	"github.com/Notifiarr/notifiarr/pkg/website"
+	"golift.io/cnfg"
+	"golift.io/starr"
+)
+
+/* Throttle lowers download client speed limits while remote Plex streams use a lot of bandwidth.
+   Limits are lowered when remote streaming reaches start_mbps, and the previous limits are restored
+   after it stays at or below stop_mbps for restore_delay. The gap between the two is the hysteresis. */
+
+const TrigThrottle common.TriggerName = "Checking Plex bandwidth for download throttling."
+
+// Defaults used when the config leaves them empty.
+const (
+	DefaultInterval     = time.Minute
+	DefaultRestoreDelay = 5 * time.Minute
+	// Plex reports session bandwidth in kilobits per second.
+	kbpsPerMbps = 1000
+	// Limits in the config are KiB/s.
+	kibibyte = 1024
+	// How long restoring limits may take when stopping.
+	stopTimeout = 30 * time.Second
+	// The name of the state file the original limits are saved in.
+	savedState = "throttleLimits"
+)
+
+// Errors returned by this package.
+var (
+	ErrInvalidConfig = fmt.Errorf("invalid throttle config")
+)
+
+// Config is the [throttle] section of the config file.
+//
+//nolint:lll
+type Config struct {
+	Interval     cnfg.Duration `json:"interval" toml:"interval" xml:"interval" yaml:"interval"`
+	StartMbps    float64       `json:"startMbps" toml:"start_mbps" xml:"start_mbps" yaml:"startMbps"`
+	StopMbps     float64       `json:"stopMbps" toml:"stop_mbps" xml:"stop_mbps" yaml:"stopMbps"`
+	RestoreDelay cnfg.Duration `json:"restoreDelay" toml:"restore_delay" xml:"restore_delay" yaml:"restoreDelay"`
+	Clients      []*Client     `json:"clients" toml:"client" xml:"client" yaml:"clients"`
+}
+
+// Client is a download client to throttle, and the limits to set on it. Limits are KiB/s.
+// A zero limit is not changed. Usenet clients do not upload, and ignore the upload limit.
+//
+//nolint:lll
+type Client struct {
+	App      string `json:"app" toml:"app" xml:"app" yaml:"app"`                     // qbit, deluge, transmission, rtorrent, sabnzbd or nzbget.
+	Instance int    `json:"instance" toml:"instance" xml:"instance" yaml:"instance"` // 0 is every instance of the app.
+	Download int64  `json:"download" toml:"download" xml:"download" yaml:"download"`
+	Upload   int64  `json:"upload" toml:"upload" xml:"upload" yaml:"upload"`
+}
+
+// Action contains the exported methods for this package.
+type Action struct {
+	cmd *cmd
+}
+
+type cmd struct {
+	*common.Config
+	throttle *Config
+	plex     *plexcron.Action
+	below    time.Time         // when remote bandwidth first dropped to stop_mbps, while throttled.
+	saved    map[string]*saved // limits to restore, by client; nil when not throttled.
+	mu       sync.Mutex
+}
+
+// New configures the library.
+func New(config *common.Config, throttle *Config, plex *plexcron.Action) *Action {
+	return &Action{cmd: &cmd{Config: config, throttle: throttle, plex: plex}}
+}
+
+// Run adds the throttle timer. Stop restores any lowered limits.
+func (a *Action) Run() {
+	a.cmd.run()
+}
+
+// Stop restores the speed limits if they are lowered, so a reload or shutdown does not leave them that way.
+func (a *Action) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+
+	a.cmd.restore(ctx, website.EventSignal, "stopping")
+}
+
+func (c *cmd) run() {
+	if c.throttle == nil || len(c.throttle.Clients) == 0 || c.throttle.StartMbps <= 0 {
+		return
+	}
+
+	if !c.Apps.Plex.Enabled() {
+		c.Errorf("Download throttling disabled: Plex is not configured")
+		return
+	}
+
+	if err := c.throttle.Validate(); err != nil {
+		c.Errorf("Download throttling disabled: %v", err)
+		return
+	}
+
+	interval := c.throttle.Interval.Duration
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	c.loadState()
+
+	c.Printf("==> Download Throttle Timer Enabled, interval:%s, start:%.1fMbps, stop:%.1fMbps, restore delay:%s, clients:%d",
+		interval, c.throttle.StartMbps, c.throttle.StopMbps, c.throttle.restoreDelay(), len(c.throttle.Clients))
+
+	c.Add(&common.Action{
+		Name: TrigThrottle,
+		Fn:   c.check,
+		C:    make(chan *common.ActionInput, 1),
+		D:    cnfg.Duration{Duration: interval},
+	})
+}
+
+// Send checks Plex bandwidth now. Returns false if throttling is not enabled.
+func (a *Action) Send(event website.EventType) bool {
+	return a.cmd.Exec(&common.ActionInput{Type: event}, TrigThrottle)
+}
+
+// Validate makes sure the thresholds make sense and every client has a valid app and a limit.
+func (c *Config) Validate() error {
+	if c.StartMbps <= 0 {
+		return nil // disabled.
+	}
+
+	if c.StopMbps < 0 || c.StopMbps >= c.StartMbps {
+		return fmt.Errorf("%w: stop_mbps must be at least 0 and less than start_mbps", ErrInvalidConfig)
+	}
+
+	for idx, client := range c.Clients {
+		switch {
+		case client == nil:
+			return fmt.Errorf("%w: client %d is empty", ErrInvalidConfig, idx+1)
+		case appName(client.App) == "":
+			return fmt.Errorf("%w: client %d: app must be qbit, deluge, transmission, rtorrent, sabnzbd or nzbget",
+				ErrInvalidConfig, idx+1)
+		case client.Instance < 0 || client.Download < 0 || client.Upload < 0:
+			return fmt.Errorf("%w: client %d: instance, download and upload may not be negative", ErrInvalidConfig, idx+1)
+		case client.Download == 0 && client.Upload == 0:
+			return fmt.Errorf("%w: client %d needs a download or upload limit", ErrInvalidConfig, idx+1)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) restoreDelay() time.Duration {
+	if c.RestoreDelay.Duration <= 0 {
+		return DefaultRestoreDelay
+	}
+
+	return c.RestoreDelay.Duration
+}
+
+// appName turns a configured app name into a download client app name.
+func appName(name string) starr.App {
+	for _, app := range []starr.App{apps.AppDeluge, apps.AppNZBGet, apps.AppQbit, apps.AppRtorrent, apps.AppSabNZB, apps.AppXmission} {
+		if strings.EqualFold(name, string(app)) || strings.EqualFold(name, app.Lower()) {
+			return app
+		}
+	}
+
+	return ""
+}
+
+// remoteBandwidth returns the bandwidth in Mbps used by remote streams that are not paused, and how many there are.
+func remoteBandwidth(sessions *plex.Sessions) (float64, int) {
+	var (
+		kbps    int64
+		streams int
+	)
+
+	for _, session := range sessions.Sessions {
+		if session.Player.Local || strings.EqualFold(session.Session.Location, "lan") ||
+			strings.EqualFold(session.Player.State, "paused") {
+			continue
+		}
+
+		kbps += session.Session.Bandwidth
+		streams++
+	}
+
+	return float64(kbps) / kbpsPerMbps, streams
+}
+
+// check compares remote streaming bandwidth to the thresholds, and lowers or restores limits.
+func (c *cmd) check(ctx context.Context, input *common.ActionInput) {
+	sessions, err := c.plex.GetSessions(ctx)
+	if err != nil {
+		// Without sessions we cannot tell; leave the limits as they are.
+		c.Errorf("[%s requested] Download Throttle: getting Plex sessions: %v", input.Type, err)
+		return
+	}
+
+	mbps, streams := remoteBandwidth(sessions)
+
+	c.mu.Lock()
+	throttled := c.saved != nil
+	c.mu.Unlock()
+
+	switch {
+	case mbps >= c.throttle.StartMbps:
+		c.lower(ctx, input.Type, mbps, streams) // also retries clients that were not lowered yet.
+	case throttled && mbps > c.throttle.StopMbps:
+		c.mu.Lock()
+		c.below = time.Time{} // still busy; start the restore delay over.
+		c.mu.Unlock()
+	case throttled:
+		c.mu.Lock()
+		if c.below.IsZero() {
+			c.below = time.Now()
+		}
+
+		waited := time.Since(c.below)
+		c.mu.Unlock()
+
+		if waited >= c.throttle.restoreDelay() {
+			c.restore(ctx, input.Type, fmt.Sprintf("remote Plex streaming at %.1fMbps stayed at or below %.1fMbps for %s",
+				mbps, c.throttle.StopMbps, c.throttle.restoreDelay()))
+		} else {
+			c.Debugf("[%s requested] Download Throttle: remote streaming at %.1fMbps (%d streams), restoring limits in %s",
+				input.Type, mbps, streams, (c.throttle.restoreDelay() - waited).Round(time.Second))
+		}
+	}
+}
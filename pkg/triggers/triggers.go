@@ -21,9 +21,12 @@ import (
 	"github.com/Notifiarr/notifiarr/pkg/triggers/filewatch"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/gaps"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/mdblist"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/orphans"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/plexcron"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/seeding"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/snapcron"
 	"github.com/Notifiarr/notifiarr/pkg/triggers/starrqueue"
+	"github.com/Notifiarr/notifiarr/pkg/triggers/throttle"
 	"github.com/Notifiarr/notifiarr/pkg/website"
 	"github.com/Notifiarr/notifiarr/pkg/website/clientinfo"
 )
@@ -36,6 +39,9 @@ type Config struct {
 	WatchFiles []*filewatch.WatchFile
 	LogFiles   []string
 	Commands   []*commands.Command
+	Archive    *backups.ArchiveConfig // keeps verified Starr backups locally; may be nil.
+	Seeding    *seeding.Config        // torrent seeding goals; may be nil.
+	Throttle   *throttle.Config       // plex bandwidth download throttling; may be nil.
 	CIC        *clientinfo.Config
 	common.Services
 	*logs.Logger
@@ -59,6 +65,9 @@ type Actions struct {
 	EmptyTrash *emptytrash.Action
 	MDbList    *mdblist.Action
 	FileUpload *fileupload.Action
+	Orphans    *orphans.Action
+	Seeding    *seeding.Action
+	Throttle   *throttle.Action
 }
 
 // New turns a populated Config into a pile of Actions.
@@ -75,7 +84,7 @@ func New(config *Config) *Actions {
 
 	return &Actions{
 		PlexCron:   plex,
-		Backups:    backups.New(common),
+		Backups:    backups.New(common, config.Archive),
 		CFSync:     cfsync.New(common),
 		CronTimer:  crontimer.New(common),
 		Dashboard:  dashboard.New(common, plex),
@@ -87,6 +96,9 @@ func New(config *Config) *Actions {
 		EmptyTrash: emptytrash.New(common),
 		MDbList:    mdblist.New(common),
 		FileUpload: fileupload.New(common),
+		Orphans:    orphans.New(common),
+		Seeding:    seeding.New(common, config.Seeding),
+		Throttle:   throttle.New(common, config.Throttle, plex),
 		Timers:     common,
 	}
 }
@@ -0,0 +1,352 @@
+package website
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"golift.io/cnfg"
+)
+
+// Outbound queue defaults.
+const (
+	DefaultQueueSize = 1000
+	// DefaultQueueTTL is how long undelivered notifications are kept when their route is not in defaultQueueTTLs.
+	DefaultQueueTTL = 24 * time.Hour
+	// QueueRetryMin is how long to wait before the first replay of queued requests.
+	// The wait doubles after every failed replay, up to QueueRetryMax.
+	QueueRetryMin = 30 * time.Second
+	QueueRetryMax = 30 * time.Minute
+	// queueReplyWait is how long the replay routine waits for the sender to process one request.
+	queueReplyWait = 5 * time.Minute
+)
+
+// ErrUnreachable is returned when a request could not be delivered to notifiarr.com.
+// Only these failures are queued; the website rejecting a request is not retried.
+var ErrUnreachable = fmt.Errorf("notifiarr.com is unreachable")
+
+// defaultQueueTTLs are how long undelivered requests are kept, by route name.
+// Notification routes not listed here are kept for DefaultQueueTTL. Other routes are never queued.
+// A zero TTL is for notifications that are stale by the time the website is back.
+var defaultQueueTTLs = map[string]time.Duration{ //nolint:gochecknoglobals
+	LogLineRoute.Name():  24 * time.Hour,
+	CommandRoute.Name():  24 * time.Hour,
+	BackupRoute.Name():   24 * time.Hour,
+	CorruptRoute.Name():  24 * time.Hour,
+	PkgRoute.Name():      24 * time.Hour,
+	OrphanRoute.Name():   24 * time.Hour,
+	SeedingRoute.Name():  24 * time.Hour,
+	RestoreRoute.Name():  24 * time.Hour,
+	DrivesRoute.Name():   24 * time.Hour,
+	SvcRoute.Name():      time.Hour,
+	StuckRoute.Name():    time.Hour,
+	PlexRoute.Name():     time.Hour,
+	DashRoute.Name():     0,
+	SnapRoute.Name():     0,
+	DownloadRoute.Name(): 0,
+	TestRoute.Name():     0,
+}
+
+// QueueConfig controls the on-disk queue for requests that could not be delivered to notifiarr.com.
+// The queue is disabled when Dir is empty.
+type QueueConfig struct {
+	Dir  string                   `json:"dir" toml:"dir" xml:"dir" yaml:"dir"`
+	Size int                      `json:"size" toml:"size" xml:"size" yaml:"size"`
+	TTL  map[string]cnfg.Duration `json:"ttl" toml:"ttl" xml:"ttl" yaml:"ttl"` // route name -> ttl; 0 disables.
+}
+
+// queuedRequest is a request saved to disk.
+type queuedRequest struct {
+	Route   Route           `json:"route"`
+	Event   EventType       `json:"event"`
+	Params  []string        `json:"params,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+	LogMsg  string          `json:"logMsg"`
+	Queued  time.Time       `json:"queued"`
+}
+
+// queue spools undelivered requests to disk and replays them when the website is reachable.
+type queue struct {
+	*QueueConfig
+	wake    chan struct{} // signals the replay routine that the website is reachable.
+	replied chan bool     // the sender replies here after processing a replayed request.
+	stop    chan struct{}
+	mu      sync.Mutex // protects the files in Dir.
+}
+
+func newQueue(config *QueueConfig) *queue {
+	if config == nil || config.Dir == "" {
+		return nil
+	}
+
+	if config.Size <= 0 {
+		config.Size = DefaultQueueSize
+	}
+
+	return &queue{
+		QueueConfig: config,
+		wake:        make(chan struct{}, 1),
+		replied:     make(chan bool, 1),
+	}
+}
+
+// ttl returns how long requests for a route are kept in the queue.
+func (q *queue) ttl(route Route) time.Duration {
+	if ttl, ok := q.TTL[route.Name()]; ok {
+		return ttl.Duration
+	}
+
+	if ttl, ok := defaultQueueTTLs[route.Name()]; ok {
+		return ttl
+	}
+
+	if strings.HasPrefix(string(route), string(notifiRoute)+"/") {
+		return DefaultQueueTTL
+	}
+
+	return 0
+}
+
+// files returns the queued request files, oldest first.
+func (q *queue) files() []string {
+	entries, _ := os.ReadDir(q.Dir)
+	files := []string{}
+
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && !strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".json") {
+			files = append(files, filepath.Join(q.Dir, name))
+		}
+	}
+
+	sort.Strings(files)
+
+	return files
+}
+
+// save writes a request to disk. The oldest requests are removed when the queue is full.
+func (q *queue) save(req *Request) (int, error) {
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		return 0, fmt.Errorf("encoding payload: %w", err)
+	}
+
+	data, err := json.Marshal(&queuedRequest{
+		Route:   req.Route,
+		Event:   req.Event,
+		Params:  req.Params,
+		Payload: payload,
+		LogMsg:  req.LogMsg,
+		Queued:  time.Now(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("encoding request: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.MkdirAll(q.Dir, mnd.Mode0750); err != nil {
+		return 0, fmt.Errorf("creating queue dir: %w", err)
+	}
+
+	name := fmt.Sprintf("%019d-%s.json", time.Now().UnixNano(), req.Route.Name())
+	tmpFile := filepath.Join(q.Dir, "."+name)
+
+	if err := os.WriteFile(tmpFile, data, mnd.Mode0600); err != nil {
+		return 0, fmt.Errorf("writing queue file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, filepath.Join(q.Dir, name)); err != nil {
+		return 0, fmt.Errorf("renaming queue file: %w", err)
+	}
+
+	files := q.files()
+	for len(files) > q.Size {
+		mnd.Website.Add("Queue Dropped", 1)
+		os.Remove(files[0])
+		files = files[1:]
+	}
+
+	return len(files), nil
+}
+
+// load reads a queued request from disk. Expired or unreadable requests are removed and return nil.
+func (q *queue) load(file string) *Request {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	var queued queuedRequest
+	if err := json.Unmarshal(data, &queued); err != nil || time.Since(queued.Queued) > q.ttl(queued.Route) {
+		mnd.Website.Add("Queue Expired", 1)
+		os.Remove(file)
+
+		return nil
+	}
+
+	if queued.LogMsg != "" {
+		queued.LogMsg = fmt.Sprintf("%s (queued %s ago)", queued.LogMsg, time.Since(queued.Queued).Round(time.Second))
+	}
+
+	return &Request{
+		Route:   queued.Route,
+		Event:   queued.Event,
+		Params:  queued.Params,
+		Payload: queued.Payload,
+		LogMsg:  queued.LogMsg,
+		queued:  file,
+	}
+}
+
+func (q *queue) remove(file string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	os.Remove(file)
+}
+
+// signal wakes the replay routine without blocking.
+func signal(channel chan struct{}) {
+	select {
+	case channel <- struct{}{}:
+	default:
+	}
+}
+
+// startQueue runs the replay routine, if the queue is enabled.
+func (s *Server) startQueue(ctx context.Context) {
+	if s.queue == nil {
+		return
+	}
+
+	s.queue.stop = make(chan struct{})
+
+	go s.replayQueue(ctx, s.queue.stop)
+}
+
+func (s *Server) stopQueue() {
+	if s.queue != nil && s.queue.stop != nil {
+		close(s.queue.stop)
+		s.queue.stop = nil
+	}
+}
+
+// replayQueue sends queued requests back through the send-data channel, oldest first.
+// It waits longer after every failed attempt, and starts again right away when a live request succeeds.
+func (s *Server) replayQueue(ctx context.Context, stop chan struct{}) {
+	defer s.Config.CapturePanic()
+
+	wait := QueueRetryMin
+	timer := time.NewTimer(wait)
+
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-s.queue.wake:
+		case <-timer.C:
+		}
+
+		if s.replayFiles(ctx, stop) {
+			wait = QueueRetryMin
+		} else if wait *= 2; wait > QueueRetryMax {
+			wait = QueueRetryMax
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+// replayFiles sends every queued request. Returns false if the website is still unreachable.
+func (s *Server) replayFiles(ctx context.Context, stop chan struct{}) bool {
+	for _, file := range s.queue.files() {
+		req := s.queue.load(file)
+		if req == nil {
+			continue
+		}
+
+		// Discard a late reply for a previous file that timed out, so it isn't read as this file's reply.
+		select {
+		case <-s.queue.replied:
+		default:
+		}
+
+		s.SendData(req)
+
+		select {
+		case <-stop:
+			return true
+		case <-ctx.Done():
+			return true
+		case <-time.After(queueReplyWait):
+			return false
+		case ok := <-s.queue.replied:
+			if !ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// queueResult is called by the sender after every request. It spools requests that could not be delivered,
+// and removes replayed requests from the queue once the website accepts (or rejects) them.
+func (s *Server) queueResult(data *Request, err error) {
+	if s.queue == nil {
+		return
+	}
+
+	unreachable := errors.Is(err, ErrUnreachable)
+
+	switch {
+	case data.queued != "":
+		if !unreachable {
+			s.queue.remove(data.queued)
+		}
+
+		select {
+		case s.queue.replied <- !unreachable:
+		default:
+		}
+	case !unreachable:
+		if err == nil && len(s.queue.files()) > 0 {
+			signal(s.queue.wake)
+		}
+	case data.respChan != nil, data.UploadFile != nil, s.queue.ttl(data.Route) <= 0:
+		return // cannot be queued.
+	default:
+		size, err := s.queue.save(data)
+		if err != nil {
+			s.Config.Errorf("[%s requested] Queueing undelivered request: %s: %v", data.Event, data.LogMsg, err)
+			return
+		}
+
+		mnd.Website.Add("Queued", 1)
+		s.Config.Printf("[%s requested] Queued undelivered request for retry (%d/%d): %s",
+			data.Event, size, s.queue.Size, data.Route.Name())
+	}
+}
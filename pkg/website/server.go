@@ -10,6 +10,7 @@ import (
 
 	"github.com/Notifiarr/notifiarr/pkg/apps"
 	"github.com/Notifiarr/notifiarr/pkg/mnd"
+	"github.com/Notifiarr/notifiarr/pkg/notifier"
 	"github.com/shirou/gopsutil/v3/host"
 	"golift.io/cnfg"
 )
@@ -40,7 +41,9 @@ type Config struct {
 	Timeout    cnfg.Duration
 	HostID     string
 	BindAddr   string
-	mnd.Logger // log file writer
+	Notifiers  *notifier.Notifiers // local notification sinks; may be nil.
+	Queue      *QueueConfig        // on-disk queue for undelivered requests; may be nil.
+	mnd.Logger                     // log file writer
 }
 
 // Server is what you get for providing a Config to New().
@@ -52,6 +55,7 @@ type Server struct {
 	hostInfo     *host.InfoStat
 	sendData     chan *Request
 	stopSendData chan struct{}
+	queue        *queue // nil if the outbound queue is disabled.
 }
 
 func New(c *Config) *Server {
@@ -74,16 +78,20 @@ func New(c *Config) *Server {
 		hostInfo:     nil, // must start nil
 		sendData:     make(chan *Request, mnd.Kilobyte),
 		stopSendData: make(chan struct{}),
+		queue:        newQueue(c.Queue),
 	}
 }
 
 // Start runs the website go routine.
 func (s *Server) Start(ctx context.Context) {
 	go s.watchSendDataChan(ctx)
+	s.startQueue(ctx)
 }
 
 // Stop stops the website go routine.
 func (s *Server) Stop() {
+	s.stopQueue()
+
 	s.sdMutex.Lock()
 	defer s.sdMutex.Unlock()
 
@@ -151,7 +159,7 @@ func (s *Server) sendPayload(ctx context.Context, uri string, payload interface{
 
 	code, body, err := s.sendJSON(ctx, s.Config.BaseURL+uri, post, log)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrUnreachable, err)
 	}
 
 	resp, err := unmarshalResponse(s.Config.BaseURL+uri, code, body)
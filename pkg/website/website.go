@@ -306,7 +306,20 @@ func (s *Server) watchSendDataChan(ctx context.Context) {
 	}()
 
 	for data := range s.sendData {
-		switch resp, elapsed, err := s.sendRequest(ctx, data); {
+		// Local notifiers may replace the website for some routes. Requests waiting on a response still go to the website.
+		if data.respChan == nil && s.Config.Notifiers.Instead(data.Route.Name()) {
+			s.Config.Notifiers.Send(data.Route.Name(), string(data.Event), data.Payload, data.LogMsg, false)
+			continue
+		}
+
+		resp, elapsed, err := s.sendRequest(ctx, data)
+		if data.queued == "" { // replayed requests already went to local notifiers.
+			s.Config.Notifiers.Send(data.Route.Name(), string(data.Event), data.Payload, data.LogMsg, err != nil)
+		}
+
+		s.queueResult(data, err)
+
+		switch {
 		case data.LogMsg == "", errors.Is(err, ErrInvalidAPIKey):
 			continue
 		case errors.Is(err, ErrNon200):
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path"
 	"strings"
 	"time"
 
@@ -51,6 +52,7 @@ type Request struct {
 	LogPayload bool        // debug log the sent payload.
 	ErrorsOnly bool        // only log errors.
 	respChan   chan *chResponse
+	queued     string // file path, if this request was replayed from the outbound queue.
 }
 
 // UploadFile is the file upload identifier in a request.
@@ -129,6 +131,10 @@ const (
 	SvcRoute      Route = notifiRoute + "/services"
 	CorruptRoute  Route = notifiRoute + "/corruption"
 	BackupRoute   Route = notifiRoute + "/backup"
+	RestoreRoute  Route = notifiRoute + "/restore"
+	OrphanRoute   Route = notifiRoute + "/orphans"
+	SeedingRoute  Route = notifiRoute + "/seeding"
+	DrivesRoute   Route = notifiRoute + "/drives"
 	TestRoute     Route = notifiRoute + "/test"
 	PkgRoute      Route = notifiRoute + "/packageManager"
 	LogLineRoute  Route = notifiRoute + "/logWatcher"
@@ -138,6 +144,12 @@ const (
 	UploadRoute Route = systemRoute + "/upload"
 )
 
+// Name returns the last part of the route path, ie. "services" or "logWatcher".
+// Local notifiers are configured with these names.
+func (r Route) Name() string {
+	return path.Base(strings.SplitN(string(r), "?", 2)[0]) //nolint:gomnd
+}
+
 // Path adds parameters to a route path and turns it into a string.
 func (r Route) Path(event EventType, params ...string) string {
 	sep := "?"